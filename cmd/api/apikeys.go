@@ -0,0 +1,114 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyPrefix = "pmx_"
+
+type createAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,min=2"`
+}
+
+type createAPIKeyResponse struct {
+	database.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey creates an API key for the current user
+// @Summary Create an API key ** Auth Required **
+// @Description Creates a key that authenticates as the current user through the X-API-Key header, or the apiKey query parameter on WebSocket upgrades. The key is only returned by this call.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param apiKey body createAPIKeyRequest true "Key name"
+// @Success 201 {object} createAPIKeyResponse
+// @Failure 400 {object} gin.H "Invalid request body"
+// @Failure 500 {object} gin.H "Failed to create the key"
+// @Router /api/v1/auth/keys [post]
+func (app *application) createAPIKey(c *gin.Context) {
+	var request createAPIKeyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the key."})
+		return
+	}
+	key := apiKeyPrefix + hex.EncodeToString(secret)
+
+	user := app.GetUserFromContext(c)
+	apiKey := database.APIKey{
+		UserId: user.Id,
+		Name:   request.Name,
+		Prefix: key[:len(apiKeyPrefix)+6],
+	}
+
+	if err := app.models.APIKeys.Insert(&apiKey, key); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the key."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, createAPIKeyResponse{APIKey: apiKey, Key: key})
+}
+
+// GetAPIKeys lists the current user's API keys
+// @Summary List API keys ** Auth Required **
+// @Description Lists the current user's keys. Only the first characters of each key are shown.
+// @Tags auth
+// @Produce json
+// @Success 200 {array} database.APIKey
+// @Failure 500 {object} gin.H "Failed to retrieve keys"
+// @Router /api/v1/auth/keys [get]
+func (app *application) getAPIKeys(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	keys, err := app.models.APIKeys.GetByUser(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve keys."})
+		return
+	}
+
+	c.JSON(http.StatusOK, keys)
+}
+
+// DeleteAPIKey revokes one of the current user's API keys
+// @Summary Revoke an API key ** Auth Required **
+// @Description Revokes a key. Open WebSocket connections that authenticated with it stay open until they disconnect.
+// @Tags auth
+// @Param id path int true "Key ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} gin.H "Invalid key ID"
+// @Failure 404 {object} gin.H "Key not found"
+// @Failure 500 {object} gin.H "Failed to revoke the key"
+// @Router /api/v1/auth/keys/{id} [delete]
+func (app *application) deleteAPIKey(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid key Id."})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	deleted, err := app.models.APIKeys.Delete(id, user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke the key."})
+		return
+	}
+	if !deleted {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Key not found."})
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
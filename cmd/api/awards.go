@@ -0,0 +1,351 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+type holeshotRequest struct {
+	Class   string `json:"class" binding:"required"`
+	RiderId int    `json:"riderId" binding:"required"`
+}
+
+type lapsLedRequest struct {
+	Class   string         `json:"class" binding:"required"`
+	LapsLed []lapsLedInput `json:"lapsLed" binding:"required,dive"`
+}
+
+type lapsLedInput struct {
+	RiderId int `json:"riderId" binding:"required"`
+	Laps    int `json:"laps" binding:"required,min=1"`
+}
+
+type eventAwards struct {
+	Holeshots []*database.Holeshot `json:"holeshots"`
+	LapsLed   []*database.LapsLed  `json:"lapsLed"`
+}
+
+type awardStats struct {
+	Series        string                 `json:"series"`
+	Season        int                    `json:"season"`
+	Class         string                 `json:"class,omitempty"`
+	Riders        []*database.AwardTotal `json:"riders"`
+	Manufacturers []*database.AwardTotal `json:"manufacturers"`
+}
+
+// GetEventAwards returns the holeshots and laps led of an event
+// @Summary Holeshots and laps led for an event
+// @Description Returns every moto's holeshot winner and laps led. Source is "manual" for records entered by hand and "laps" for ones derived from lap times.
+// @Tags stats
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {object} eventAwards
+// @Failure 400 {object} gin.H "Invalid event ID"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve awards"
+// @Router /api/v1/events/{id}/awards [get]
+func (app *application) getEventAwards(c *gin.Context) {
+	eventId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event Id."})
+		return
+	}
+
+	event, err := app.models.Events.Get(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event."})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return
+	}
+
+	holeshots, err := app.models.Awards.GetHoleshotsByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve awards."})
+		return
+	}
+
+	lapsLed, err := app.models.Awards.GetLapsLedByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve awards."})
+		return
+	}
+
+	c.JSON(http.StatusOK, eventAwards{Holeshots: holeshots, LapsLed: lapsLed})
+}
+
+// SetHoleshot records the holeshot winner of a moto
+// @Summary Set the holeshot winner ** Auth Required **
+// @Description Records who won the holeshot in a moto. Entries made here are never overwritten by lap data.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param moto path int true "Moto number"
+// @Param holeshot body holeshotRequest true "Holeshot winner"
+// @Success 200 {object} database.Holeshot
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event not in progress or provisional"
+// @Failure 422 {object} gin.H "Rider not entered in the event"
+// @Failure 500 {object} gin.H "Failed to save the holeshot"
+// @Router /api/v1/events/{id}/motos/{moto}/holeshot [put]
+func (app *application) setHoleshot(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "record holeshots for")
+	if !ok {
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	moto, err := strconv.Atoi(c.Param("moto"))
+	if err != nil || moto < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+		return
+	}
+
+	var request holeshotRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, request.RiderId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendee."})
+		return
+	}
+	if attendee == nil || !attendee.Active() {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Rider %d is not entered in this event.", request.RiderId)})
+		return
+	}
+
+	holeshot := database.Holeshot{EventId: event.Id, Class: request.Class, Moto: moto, RiderId: request.RiderId, Source: database.AwardManual}
+	if err := app.models.Awards.SaveHoleshot(&holeshot); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save the holeshot."})
+		return
+	}
+
+	c.JSON(http.StatusOK, holeshot)
+}
+
+// SetLapsLed records the laps each rider led in a moto
+// @Summary Set laps led ** Auth Required **
+// @Description Replaces the laps-led records of a moto. Entries made here are never overwritten by lap data.
+// @Tags stats
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param moto path int true "Moto number"
+// @Param lapsLed body lapsLedRequest true "Laps led per rider"
+// @Success 200 {array} database.LapsLed
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event not in progress or provisional"
+// @Failure 422 {object} gin.H "Rider not entered in the event"
+// @Failure 500 {object} gin.H "Failed to save laps led"
+// @Router /api/v1/events/{id}/motos/{moto}/laps-led [put]
+func (app *application) setLapsLed(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "record laps led for")
+	if !ok {
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	moto, err := strconv.Atoi(c.Param("moto"))
+	if err != nil || moto < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+		return
+	}
+
+	var request lapsLedRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	records := []*database.LapsLed{}
+	for _, input := range request.LapsLed {
+		attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, input.RiderId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendee."})
+			return
+		}
+		if attendee == nil || !attendee.Active() {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Rider %d is not entered in this event.", input.RiderId)})
+			return
+		}
+		records = append(records, &database.LapsLed{RiderId: input.RiderId, Laps: input.Laps, Source: database.AwardManual})
+	}
+
+	err = app.models.Transaction(func(tx database.Models) error {
+		return tx.Awards.ReplaceLapsLed(event.Id, request.Class, moto, records)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save laps led."})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// GetHoleshotStats returns season holeshot counts per rider and manufacturer
+// @Summary Season holeshot totals
+// @Description Counts holeshots per rider and per manufacturer for a season. A manufacturer is credited with the bike the rider raced that moto on.
+// @Tags stats
+// @Produce json
+// @Param series query string false "Series ID or slug, defaults to Pro Motocross"
+// @Param season query int false "Season, defaults to the latest"
+// @Param class query string false "Only this class"
+// @Success 200 {object} awardStats
+// @Failure 400 {object} gin.H "Invalid season"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to retrieve stats"
+// @Router /api/v1/stats/holeshots [get]
+func (app *application) getHoleshotStats(c *gin.Context) {
+	app.writeAwardStats(c, app.models.Awards.HoleshotTotals, app.models.Awards.HoleshotTotalsByManufacturer)
+}
+
+// GetLapsLedStats returns season laps-led totals per rider and manufacturer
+// @Summary Season laps-led totals
+// @Description Adds up laps led per rider and per manufacturer for a season. A manufacturer is credited with the bike the rider raced that moto on.
+// @Tags stats
+// @Produce json
+// @Param series query string false "Series ID or slug, defaults to Pro Motocross"
+// @Param season query int false "Season, defaults to the latest"
+// @Param class query string false "Only this class"
+// @Success 200 {object} awardStats
+// @Failure 400 {object} gin.H "Invalid season"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to retrieve stats"
+// @Router /api/v1/stats/laps-led [get]
+func (app *application) getLapsLedStats(c *gin.Context) {
+	app.writeAwardStats(c, app.models.Awards.LapsLedTotals, app.models.Awards.LapsLedTotalsByManufacturer)
+}
+
+func (app *application) writeAwardStats(c *gin.Context, byRider, byManufacturer func(seriesId, season int, class string) ([]*database.AwardTotal, error)) {
+	series, ok := app.seriesParam(c)
+	if !ok {
+		return
+	}
+
+	season, ok := app.seasonParam(c, series.Id)
+	if !ok {
+		return
+	}
+
+	stats := awardStats{Series: series.Slug, Season: season, Class: c.Query("class")}
+
+	var err error
+	if stats.Riders, err = byRider(series.Id, season, stats.Class); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stats."})
+		return
+	}
+	if stats.Manufacturers, err = byManufacturer(series.Id, season, stats.Class); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve stats."})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// seasonParam reads the season query parameter, defaulting to the latest
+// season of the series with events. It responds with the error itself when
+// it fails.
+func (app *application) seasonParam(c *gin.Context, seriesId int) (int, bool) {
+	if value := c.Query("season"); value != "" {
+		season, err := strconv.Atoi(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season."})
+			return 0, false
+		}
+		return season, true
+	}
+
+	season, err := app.models.Events.LatestSeason(seriesId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events."})
+		return 0, false
+	}
+	return season, true
+}
+
+// deriveAwards works out a moto's holeshot and laps led from its lap times.
+// The holeshot goes to whoever led the first lap, since that is as close
+// as lap timing gets to the first turn. Records entered by hand are kept.
+func (app *application) deriveAwards(eventId int, class string, moto int) error {
+	laps, err := app.models.Laps.GetByMoto(eventId, class, moto)
+	if err != nil || len(laps) == 0 {
+		return err
+	}
+
+	analysis := analyseLaps(eventId, class, moto, laps)
+
+	var holeshot *database.Holeshot
+	var lapsLed []*database.LapsLed
+	for _, series := range analysis.Positions {
+		led := 0
+		for _, point := range series.Points {
+			if point.Value != 1 {
+				continue
+			}
+			led++
+			if point.Lap == 1 {
+				holeshot = &database.Holeshot{EventId: eventId, Class: class, Moto: moto, RiderId: series.RiderId, Source: database.AwardLaps}
+			}
+		}
+		if led > 0 {
+			lapsLed = append(lapsLed, &database.LapsLed{RiderId: series.RiderId, Laps: led, Source: database.AwardLaps})
+		}
+	}
+
+	return app.models.Transaction(func(tx database.Models) error {
+		if holeshot != nil {
+			existing, err := tx.Awards.GetHoleshot(eventId, class, moto)
+			if err != nil {
+				return err
+			}
+			if existing == nil || existing.Source != database.AwardManual {
+				if err := tx.Awards.SaveHoleshot(holeshot); err != nil {
+					return err
+				}
+			}
+		}
+
+		manual, err := tx.Awards.HasManualLapsLed(eventId, class, moto)
+		if err != nil || manual {
+			return err
+		}
+		return tx.Awards.ReplaceLapsLed(eventId, class, moto, lapsLed)
+	})
+}
+
+// deriveMotoAwards derives the awards of every class in a moto after its
+// laps changed. Failures are logged; the laps themselves are already saved.
+func (app *application) deriveMotoAwards(eventId, moto int) {
+	classes, err := app.models.Laps.GetClassesByMoto(eventId, moto)
+	if err != nil {
+		log.Printf("awards: loading classes of event %d moto %d: %v", eventId, moto, err)
+		return
+	}
+
+	for _, class := range classes {
+		if err := app.deriveAwards(eventId, class, moto); err != nil {
+			log.Printf("awards: deriving event %d %s moto %d: %v", eventId, class, moto, err)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// GetCalendar returns the race schedule as an iCalendar feed
+// @Summary Race schedule as iCalendar
+// @Description Returns the schedule as an RFC 5545 calendar. Each event keeps the same UID across updates and its SEQUENCE goes up whenever the date changes.
+// @Tags calendar
+// @Produce text/calendar
+// @Param series query string false "Only events in this series, by ID or slug"
+// @Param season query int false "Only events in this season"
+// @Param class query string false "Only events with entries in this class, e.g. 450"
+// @Success 200 "iCalendar feed"
+// @Failure 400 {object} gin.H "Invalid season"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to build the calendar"
+// @Router /api/v1/calendar.ics [get]
+func (app *application) getCalendar(c *gin.Context) {
+	season := 0
+	if value := c.Query("season"); value != "" {
+		var err error
+		if season, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season."})
+			return
+		}
+	}
+
+	name := "Pro Motocross"
+	seriesId := 0
+	if c.Query("series") != "" {
+		series, ok := app.seriesParam(c)
+		if !ok {
+			return
+		}
+		name, seriesId = series.Name, series.Id
+	}
+
+	class := c.Query("class")
+
+	var events []*database.Event
+	var err error
+	if class != "" {
+		events, err = app.models.Events.GetByClass(seriesId, class)
+	} else {
+		events, err = app.models.Events.GetAll(seriesId)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the calendar."})
+		return
+	}
+
+	if season != 0 {
+		name = fmt.Sprintf("%d %s", season, name)
+	}
+	if class != "" {
+		name += " " + class + " Class"
+	}
+
+	var filtered []database.Event
+	for _, event := range events {
+		if season == 0 || event.Season == season {
+			filtered = append(filtered, *event)
+		}
+	}
+
+	writeCalendar(c, name, filtered)
+}
+
+// GetRiderCalendar returns the events a rider is entered in as an iCalendar feed
+// @Summary A rider's schedule as iCalendar
+// @Description Returns the events a rider is entered in as an RFC 5545 calendar
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "Rider ID"
+// @Success 200 "iCalendar feed"
+// @Success 301 "Rider was merged into another; Location gives their new address"
+// @Failure 400 {object} gin.H "Invalid rider ID"
+// @Failure 404 {object} gin.H "Rider not found"
+// @Failure 500 {object} gin.H "Failed to build the calendar"
+// @Router /api/v1/riders/{id}/calendar.ics [get]
+func (app *application) getRiderCalendar(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+		return
+	}
+
+	rider, err := app.models.Riders.Get(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+		return
+	}
+	if rider == nil {
+		if app.redirectMergedRider(c, id) {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rider not found."})
+		return
+	}
+
+	events, err := app.models.Events.GetByAttendee(rider.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to build the calendar."})
+		return
+	}
+
+	writeCalendar(c, fmt.Sprintf("#%d %s %s", rider.Number, rider.FirstName, rider.LastName), events)
+}
+
+func writeCalendar(c *gin.Context, name string, events []database.Event) {
+	var b strings.Builder
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+
+	writeCalendarLine(&b, "BEGIN:VCALENDAR")
+	writeCalendarLine(&b, "VERSION:2.0")
+	writeCalendarLine(&b, "PRODID:-//Pro Motocross API//Schedule//EN")
+	writeCalendarLine(&b, "CALSCALE:GREGORIAN")
+	writeCalendarLine(&b, "METHOD:PUBLISH")
+	writeCalendarLine(&b, "X-WR-CALNAME:"+escapeCalendarText(name))
+
+	for _, event := range events {
+		start, err := parseEventDate(event.Date)
+		if err != nil {
+			continue
+		}
+
+		writeCalendarLine(&b, "BEGIN:VEVENT")
+		writeCalendarLine(&b, fmt.Sprintf("UID:event-%d@pro-motocross-api", event.Id))
+		writeCalendarLine(&b, "DTSTAMP:"+stamp)
+		writeCalendarLine(&b, "DTSTART;VALUE=DATE:"+start.Format("20060102"))
+		writeCalendarLine(&b, "DTEND;VALUE=DATE:"+start.AddDate(0, 0, 1).Format("20060102"))
+		writeCalendarLine(&b, fmt.Sprintf("SEQUENCE:%d", event.Sequence))
+		if event.Status == database.EventCancelled {
+			writeCalendarLine(&b, "STATUS:CANCELLED")
+		}
+		writeCalendarLine(&b, "SUMMARY:"+escapeCalendarText(event.Name))
+		writeCalendarLine(&b, "LOCATION:"+escapeCalendarText(event.Location))
+		writeCalendarLine(&b, "DESCRIPTION:"+escapeCalendarText(event.Description))
+		writeCalendarLine(&b, "END:VEVENT")
+	}
+
+	writeCalendarLine(&b, "END:VCALENDAR")
+
+	c.Header("Content-Disposition", "inline; filename=calendar.ics")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(b.String()))
+}
+
+// parseEventDate accepts the plain dates events are created with as well as
+// the timestamps the SQLite driver returns for DATETIME columns.
+func parseEventDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid event date %q", value)
+}
+
+var calendarEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func escapeCalendarText(value string) string {
+	return calendarEscaper.Replace(value)
+}
+
+// writeCalendarLine ends the line with CRLF and folds it at 75 octets as RFC
+// 5545 requires, without splitting a UTF-8 sequence.
+func writeCalendarLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// headToHead counts the motos both riders started and who finished ahead.
+// AverageGap is the second rider's position minus the first's, so a
+// positive gap means the first rider usually finishes ahead.
+type headToHead struct {
+	Motos      int     `json:"motos"`
+	Ahead      [2]int  `json:"ahead"`
+	AverageGap float64 `json:"averageGap"`
+}
+
+// roundDelta is the points each rider scored at an event and the running
+// difference over the series' season, first rider minus second.
+type roundDelta struct {
+	EventId    int    `json:"eventId"`
+	EventName  string `json:"eventName"`
+	SeriesId   int    `json:"seriesId"`
+	Season     int    `json:"season"`
+	Round      int    `json:"round"`
+	Points     [2]int `json:"points"`
+	Delta      int    `json:"delta"`
+	Cumulative int    `json:"cumulative"`
+}
+
+// trackRecord is how each rider has done at a track they have both raced.
+type trackRecord struct {
+	Track             string     `json:"track"`
+	Motos             [2]int     `json:"motos"`
+	BestFinish        [2]int     `json:"bestFinish"`
+	AverageMotoFinish [2]float64 `json:"averageMotoFinish"`
+}
+
+type riderComparison struct {
+	Riders     [2]*database.Rider `json:"riders"`
+	HeadToHead headToHead         `json:"headToHead"`
+	Rounds     []roundDelta       `json:"rounds"`
+	Tracks     []trackRecord      `json:"tracks"`
+}
+
+// CompareRiders compares two riders head to head
+// @Summary Head-to-head rider comparison
+// @Description Compares two riders from stored results: who finished ahead in the motos both started and by how many places on average, the points each scored per round with the running difference over each series' season, and their record at tracks both have raced. Cancelled events don't count. Pairs in the response are in the order of ids. Riders merged into another are compared as the rider they were merged into.
+// @Tags riders
+// @Produce json
+// @Param ids query string true "Two rider IDs, e.g. 1,2"
+// @Success 200 {object} riderComparison
+// @Failure 400 {object} gin.H "Invalid rider IDs"
+// @Failure 404 {object} gin.H "Rider not found"
+// @Failure 500 {object} gin.H "Failed to compare riders"
+// @Router /api/v1/riders/compare [get]
+func (app *application) compareRiders(c *gin.Context) {
+	parts := strings.Split(c.Query("ids"), ",")
+	if len(parts) != 2 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give exactly two rider ids, e.g. ids=1,2."})
+		return
+	}
+
+	var comparison riderComparison
+	var results [2][]*database.RaceResult
+
+	for i, part := range parts {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+			return
+		}
+
+		// Riders merged into another are compared as the rider they became.
+		toId, err := app.models.Riders.Redirect(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+			return
+		}
+		if toId != 0 {
+			id = toId
+		}
+
+		rider, err := app.models.Riders.Get(id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+			return
+		}
+		if rider == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Rider %d not found.", id)})
+			return
+		}
+
+		if results[i], err = app.models.Results.GetCountingByRider(rider.Id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compare riders."})
+			return
+		}
+		comparison.Riders[i] = rider
+	}
+
+	if comparison.Riders[0].Id == comparison.Riders[1].Id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pick two different riders."})
+		return
+	}
+
+	comparison.HeadToHead = compareMotos(results)
+	comparison.Rounds = compareRounds(results)
+	comparison.Tracks = compareTracks(results)
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+func motoKey(result *database.RaceResult) string {
+	return fmt.Sprintf("%d/%s/%d", result.EventId, result.Class, result.Moto)
+}
+
+func compareMotos(results [2][]*database.RaceResult) headToHead {
+	var record headToHead
+
+	second := map[string]*database.RaceResult{}
+	for _, result := range results[1] {
+		second[motoKey(result)] = result
+	}
+
+	gap := 0
+	for _, a := range results[0] {
+		b, ok := second[motoKey(a)]
+		if !ok || a.Status == database.ResultDNS || b.Status == database.ResultDNS {
+			continue
+		}
+
+		record.Motos++
+		gap += b.Position - a.Position
+		if a.Position < b.Position {
+			record.Ahead[0]++
+		} else if b.Position < a.Position {
+			record.Ahead[1]++
+		}
+	}
+
+	if record.Motos > 0 {
+		record.AverageGap = math.Round(float64(gap)/float64(record.Motos)*100) / 100
+	}
+
+	return record
+}
+
+func compareRounds(results [2][]*database.RaceResult) []roundDelta {
+	byEvent := map[int]*roundDelta{}
+	rounds := []*roundDelta{}
+
+	for i, riderResults := range results {
+		for _, result := range riderResults {
+			round, ok := byEvent[result.EventId]
+			if !ok {
+				round = &roundDelta{EventId: result.EventId, EventName: result.EventName, SeriesId: result.SeriesId, Season: result.Season, Round: result.Round}
+				byEvent[result.EventId] = round
+				rounds = append(rounds, round)
+			}
+			round.Points[i] += result.Points
+		}
+	}
+
+	// Each series' season keeps its own running difference.
+	sort.SliceStable(rounds, func(i, j int) bool {
+		if rounds[i].SeriesId != rounds[j].SeriesId {
+			return rounds[i].SeriesId < rounds[j].SeriesId
+		}
+		if rounds[i].Season != rounds[j].Season {
+			return rounds[i].Season < rounds[j].Season
+		}
+		return rounds[i].Round < rounds[j].Round
+	})
+
+	deltas := []roundDelta{}
+	cumulative := 0
+	for i, round := range rounds {
+		if i == 0 || round.SeriesId != rounds[i-1].SeriesId || round.Season != rounds[i-1].Season {
+			cumulative = 0
+		}
+		round.Delta = round.Points[0] - round.Points[1]
+		cumulative += round.Delta
+		round.Cumulative = cumulative
+		deltas = append(deltas, *round)
+	}
+
+	return deltas
+}
+
+func compareTracks(results [2][]*database.RaceResult) []trackRecord {
+	type tally struct {
+		motos, finished, positionSum, best int
+	}
+
+	tallies := [2]map[string]*tally{{}, {}}
+	for i, riderResults := range results {
+		for _, result := range riderResults {
+			t, ok := tallies[i][result.Track]
+			if !ok {
+				t = &tally{}
+				tallies[i][result.Track] = t
+			}
+			t.motos++
+			if result.Status == database.ResultFinished {
+				t.finished++
+				t.positionSum += result.Position
+				if t.best == 0 || result.Position < t.best {
+					t.best = result.Position
+				}
+			}
+		}
+	}
+
+	tracks := []trackRecord{}
+	for track, first := range tallies[0] {
+		second, ok := tallies[1][track]
+		if !ok {
+			continue
+		}
+
+		record := trackRecord{Track: track}
+		for i, t := range [2]*tally{first, second} {
+			record.Motos[i] = t.motos
+			record.BestFinish[i] = t.best
+			if t.finished > 0 {
+				record.AverageMotoFinish[i] = math.Round(float64(t.positionSum)/float64(t.finished)*100) / 100
+			}
+		}
+		tracks = append(tracks, record)
+	}
+
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].Track < tracks[j].Track })
+
+	return tracks
+}
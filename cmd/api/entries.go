@@ -0,0 +1,404 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+type classEntries struct {
+	Class      string            `json:"class"`
+	Capacity   int               `json:"capacity"`
+	Entered    int               `json:"entered"`
+	Waitlisted int               `json:"waitlisted"`
+	Entries    []*database.Entry `json:"entries"`
+}
+
+type capacityRequest struct {
+	Class    string `json:"class" binding:"required"`
+	Capacity int    `json:"capacity" binding:"required,min=1"`
+}
+
+type entryRequests struct {
+	Incoming []*database.EntryRequest `json:"incoming"`
+	Outgoing []*database.EntryRequest `json:"outgoing"`
+}
+
+type rejectRequest struct {
+	Reason string `json:"reason"`
+}
+
+// GetEntries returns the entry list of an event
+// @Summary Get event entry lists
+// @Description Returns each class's entry list: the riders holding a spot in the order they got one, then the waitlist in turn, then withdrawals. Entered counts entered and confirmed riders against the class capacity.
+// @Tags attendees
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param class query string false "Only this class"
+// @Success 200 {array} classEntries
+// @Failure 400 {object} gin.H "Invalid event ID"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve entries"
+// @Router /api/v1/events/{id}/entries [get]
+func (app *application) getEntries(c *gin.Context) {
+	event, ok := app.eventParam(c)
+	if !ok {
+		return
+	}
+
+	class := c.Query("class")
+	lists, err := entryLists(app.models, event.Id, class)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve entries."})
+		return
+	}
+
+	if class != "" && len(lists) == 0 {
+		lists = append(lists, &classEntries{Class: class, Capacity: database.DefaultClassCapacity, Entries: []*database.Entry{}})
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+// SetClassCapacity sets how many riders a class takes
+// @Summary Set a class's entry capacity ** Auth Required **
+// @Description Sets how many riders can hold a spot in a class at an event, 40 unless set. Raising the capacity enters waitlisted riders in turn. Lowering it below the riders already entered keeps their spots, and waitlists new entries until enough withdraw.
+// @Tags attendees
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param capacity body capacityRequest true "Class capacity"
+// @Success 200 {object} classEntries
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to set the capacity"
+// @Router /api/v1/events/{id}/capacity [put]
+func (app *application) setClassCapacity(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "set capacities for")
+	if !ok {
+		return
+	}
+
+	var request capacityRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := app.models.Transaction(func(tx database.Models) error {
+		if err := tx.Attendees.SetCapacity(event.Id, request.Class, request.Capacity); err != nil {
+			return err
+		}
+
+		_, err := promoteWaitlisted(tx, event.Id, request.Class)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set the capacity."})
+		return
+	}
+
+	lists, err := entryLists(app.models, event.Id, request.Class)
+	if err != nil || len(lists) == 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve entries."})
+		return
+	}
+
+	c.JSON(http.StatusOK, lists[0])
+}
+
+// ConfirmEntry confirms a rider's entry
+// @Summary Confirm an entry ** Auth Required **
+// @Description Confirms an entered rider's spot, for instance once their entry fee is paid. Only entered riders can be confirmed.
+// @Tags attendees
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param riderId path int true "Rider ID"
+// @Success 200 {object} database.Attendee
+// @Failure 400 {object} gin.H "Invalid event or rider ID"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found or rider not entered"
+// @Failure 409 {object} gin.H "Entry is not entered"
+// @Failure 500 {object} gin.H "Failed to confirm the entry"
+// @Router /api/v1/events/{id}/attendees/{riderId}/confirm [post]
+func (app *application) confirmEntry(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "confirm entries for")
+	if !ok {
+		return
+	}
+
+	riderId, err := strconv.Atoi(c.Param("riderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+		return
+	}
+
+	attendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, riderId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendee."})
+		return
+	}
+	if attendee == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rider is not entered in this event."})
+		return
+	}
+
+	if attendee.Status != database.EntryEntered {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Only entered riders can be confirmed, this entry is %s.", attendee.Status)})
+		return
+	}
+
+	if err := app.models.Attendees.SetStatus(attendee, database.EntryConfirmed); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm the entry."})
+		return
+	}
+
+	c.JSON(http.StatusOK, attendee)
+}
+
+// GetPendingEntries lists the entry requests waiting on a decision
+// @Summary Get pending entry requests ** Auth Required **
+// @Description Returns the pending entry requests for events you own, waiting on you to approve or reject them, and those you made for your riders, waiting on other event owners.
+// @Tags attendees
+// @Produce json
+// @Success 200 {object} entryRequests
+// @Failure 500 {object} gin.H "Failed to retrieve entry requests"
+// @Router /api/v1/entries/pending [get]
+func (app *application) getPendingEntries(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	incoming, outgoing, err := app.models.Attendees.GetPendingRequests(user.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve entry requests."})
+		return
+	}
+
+	c.JSON(http.StatusOK, entryRequests{Incoming: incoming, Outgoing: outgoing})
+}
+
+// ApproveEntry approves an entry request
+// @Summary Approve an entry request ** Auth Required **
+// @Description Approves a rider owner's pending entry request while the event is open for entries. The rider is entered, or waitlisted if their class is at capacity.
+// @Tags attendees
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param entryId path int true "Entry ID"
+// @Success 200 {object} database.Attendee
+// @Failure 400 {object} gin.H "Invalid event or entry ID"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event or entry not found"
+// @Failure 409 {object} gin.H "Entry is not pending or event not open for entries"
+// @Failure 500 {object} gin.H "Failed to approve the entry"
+// @Router /api/v1/events/{id}/entries/{entryId}/approve [post]
+func (app *application) approveEntry(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "approve entries for")
+	if !ok {
+		return
+	}
+
+	if event.Status != database.EventOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Entries are only accepted while the event is open."})
+		return
+	}
+
+	attendee, ok := app.pendingEntry(c, event)
+	if !ok {
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	err := app.models.Transaction(func(tx database.Models) error {
+		status, err := entryStatus(tx, event.Id, attendee.Class)
+		if err != nil {
+			return err
+		}
+
+		return tx.Attendees.Decide(attendee, status, user.Id, "")
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to approve the entry."})
+		return
+	}
+
+	c.JSON(http.StatusOK, attendee)
+}
+
+// RejectEntry rejects an entry request
+// @Summary Reject an entry request ** Auth Required **
+// @Description Rejects a rider owner's pending entry request, with an optional reason. The rider's owner can request entry again.
+// @Tags attendees
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param entryId path int true "Entry ID"
+// @Param reason body rejectRequest false "Why the entry was rejected"
+// @Success 200 {object} database.Attendee
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event or entry not found"
+// @Failure 409 {object} gin.H "Entry is not pending"
+// @Failure 500 {object} gin.H "Failed to reject the entry"
+// @Router /api/v1/events/{id}/entries/{entryId}/reject [post]
+func (app *application) rejectEntry(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "reject entries for")
+	if !ok {
+		return
+	}
+
+	var request rejectRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	attendee, ok := app.pendingEntry(c, event)
+	if !ok {
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	if err := app.models.Attendees.Decide(attendee, database.EntryRejected, user.Id, request.Reason); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reject the entry."})
+		return
+	}
+
+	c.JSON(http.StatusOK, attendee)
+}
+
+// pendingEntry loads the event's entry named by the entryId path parameter,
+// responding with an error unless it is waiting on a decision.
+func (app *application) pendingEntry(c *gin.Context, event *database.Event) (*database.Attendee, bool) {
+	entryId, err := strconv.Atoi(c.Param("entryId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid entry Id."})
+		return nil, false
+	}
+
+	attendee, err := app.models.Attendees.Get(entryId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the entry."})
+		return nil, false
+	}
+	if attendee == nil || attendee.EventId != event.Id {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Entry not found."})
+		return nil, false
+	}
+
+	if attendee.Status != database.EntryPending {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Only pending entries can be decided, this entry is %s.", attendee.Status)})
+		return nil, false
+	}
+
+	return attendee, true
+}
+
+// entryStatus is the status a new entry in an event class gets: entered
+// while the class has a spot free, waitlisted once it is full.
+func entryStatus(tx database.Models, eventId int, class string) (string, error) {
+	entered, err := tx.Attendees.CountActive(eventId, class)
+	if err != nil {
+		return "", err
+	}
+
+	capacity, err := tx.Attendees.GetCapacity(eventId, class)
+	if err != nil {
+		return "", err
+	}
+
+	if entered >= capacity {
+		return database.EntryWaitlisted, nil
+	}
+	return database.EntryEntered, nil
+}
+
+// promoteWaitlisted enters waitlisted riders in an event class, in turn,
+// while the class has spots free.
+func promoteWaitlisted(tx database.Models, eventId int, class string) ([]*database.Attendee, error) {
+	capacity, err := tx.Attendees.GetCapacity(eventId, class)
+	if err != nil {
+		return nil, err
+	}
+
+	entered, err := tx.Attendees.CountActive(eventId, class)
+	if err != nil {
+		return nil, err
+	}
+
+	promoted := []*database.Attendee{}
+	for ; entered < capacity; entered++ {
+		next, err := tx.Attendees.NextWaitlisted(eventId, class)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil {
+			break
+		}
+
+		if err := tx.Attendees.SetStatus(next, database.EntryEntered); err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, next)
+	}
+
+	return promoted, nil
+}
+
+// entryLists groups an event's entries by class, optionally for one class.
+// Classes with a capacity set are listed even without entries.
+func entryLists(models database.Models, eventId int, class string) ([]*classEntries, error) {
+	entries, err := models.Attendees.GetEntries(eventId, class)
+	if err != nil {
+		return nil, err
+	}
+
+	capacities, err := models.Attendees.GetCapacities(eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	byClass := map[string]*classEntries{}
+	list := func(class string) *classEntries {
+		if byClass[class] == nil {
+			capacity, ok := capacities[class]
+			if !ok {
+				capacity = database.DefaultClassCapacity
+			}
+			byClass[class] = &classEntries{Class: class, Capacity: capacity, Entries: []*database.Entry{}}
+		}
+		return byClass[class]
+	}
+
+	for capacityClass := range capacities {
+		if class == "" || capacityClass == class {
+			list(capacityClass)
+		}
+	}
+
+	for _, entry := range entries {
+		classList := list(entry.Class)
+		switch entry.Status {
+		case database.EntryEntered, database.EntryConfirmed:
+			classList.Entered++
+		case database.EntryWaitlisted:
+			classList.Waitlisted++
+		}
+		classList.Entries = append(classList.Entries, entry)
+	}
+
+	lists := []*classEntries{}
+	for _, classList := range byClass {
+		lists = append(lists, classList)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Class < lists[j].Class })
+
+	return lists, nil
+}
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -10,13 +12,13 @@ import (
 
 // CreateEvent creates a new event
 // @Summary Create a new event ** Auth Required **
-// @Description Create a new event with the provided details
+// @Description Create a new event with the provided details. Events go in Pro Motocross unless a seriesId is given.
 // @Tags events
 // @Accept json
 // @Produce json
 // @Param event body database.Event true "Event data"
 // @Success 201 {object} database.Event
-// @Failure 400 {object} gin.H "Invalid request body"
+// @Failure 400 {object} gin.H "Invalid request body or series"
 // @Failure 500 {object} gin.H "Failed to create the event"
 // @Router /api/v1/events [post]
 func (app *application) createEvent(c *gin.Context) {
@@ -27,6 +29,10 @@ func (app *application) createEvent(c *gin.Context) {
 		return
 	}
 
+	if !app.knownSeries(c, &event) {
+		return
+	}
+
 	user := app.GetUserFromContext(c)
 	event.OwnerId = user.Id
 
@@ -70,14 +76,21 @@ func (app *application) getEvent(c *gin.Context) {
 
 // GetAllEvents returns all events
 // @Summary Get all events
-// @Description Get a list of all events
+// @Description Get a list of all events, optionally in one series
 // @Tags events
 // @Produce json
+// @Param series query string false "Only events in this series, by ID or slug"
 // @Success 200 {array} database.Event
+// @Failure 404 {object} gin.H "Series not found"
 // @Failure 500 {object} gin.H "Server failed to get all events"
 // @Router /api/v1/events [get]
 func (app *application) getAllEvents(c *gin.Context) {
-	events, err := app.models.Events.GetAll()
+	seriesId, ok := app.seriesFilter(c)
+	if !ok {
+		return
+	}
+
+	events, err := app.models.Events.GetAll(seriesId)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sever failed to get all events."})
@@ -88,14 +101,14 @@ func (app *application) getAllEvents(c *gin.Context) {
 
 // UpdateEvent updates an existing event
 // @Summary Update an existing event ** Auth Required **
-// @Description Update an existing event with the provided details
+// @Description Update an existing event with the provided details. The event stays in its series unless a seriesId is given.
 // @Tags events
 // @Accept json
 // @Produce json
 // @Param id path int true "Event ID"
 // @Param event body database.Event true "Updated event data"
 // @Success 200 {object} database.Event
-// @Failure 400 {object} gin.H "Invalid event ID or request body"
+// @Failure 400 {object} gin.H "Invalid event ID, request body or series"
 // @Failure 403 {object} gin.H "Unauthorized to update the event"
 // @Failure 404 {object} gin.H "Event not found"
 // @Failure 500 {object} gin.H "Failed to update event"
@@ -132,11 +145,21 @@ func (app *application) updateEvent(c *gin.Context) {
 		return
 	}
 
+	if updatedEvent.SeriesId == 0 {
+		updatedEvent.SeriesId = existingEvent.SeriesId
+	}
+	if !app.knownSeries(c, updatedEvent) {
+		return
+	}
+
 	if err := app.models.Events.Update(updatedEvent); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update event!"})
 		return
 	}
 
+	updatedEvent.Status = existingEvent.Status
+	updatedEvent.OriginalDate = existingEvent.OriginalDate
+
 	c.JSON(http.StatusOK, updatedEvent)
 }
 
@@ -183,17 +206,111 @@ func (app *application) deleteEvent(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
+type eventStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=scheduled open in_progress provisional official postponed cancelled"`
+}
+
+type rescheduleRequest struct {
+	Date string `json:"date" binding:"required,datetime=2006-01-02"`
+}
+
+// UpdateEventStatus moves an event through its lifecycle
+// @Summary Change an event's status ** Auth Required **
+// @Description Moves an event to a new status. Events go scheduled, open (for entries), in_progress, provisional and official, and open events can close again; scheduled, open and in_progress events can be postponed or cancelled, postponed ones rescheduled, reopened or cancelled, and provisional ones reopened while results are corrected. Official and cancelled events are final.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param status body eventStatusRequest true "New status"
+// @Success 200 {object} database.Event
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event can't move to that status"
+// @Failure 500 {object} gin.H "Failed to update the event status"
+// @Router /api/v1/events/{id}/status [put]
+func (app *application) updateEventStatus(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "change the status of")
+	if !ok {
+		return
+	}
+
+	var request eventStatusRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !database.EventCanMove(event.Status, request.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("An event that is %s can't be moved to %s.", event.Status, request.Status)})
+		return
+	}
+
+	event.Status = request.Status
+	if err := app.models.Events.UpdateStatus(event); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the event status."})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
+// RescheduleEvent moves an event to a new date
+// @Summary Reschedule an event ** Auth Required **
+// @Description Moves a scheduled, open or postponed event to a new date, keeping the date it was first planned for in originalDate. Postponed events go back to scheduled.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param reschedule body rescheduleRequest true "New date"
+// @Success 200 {object} database.Event
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event can't be rescheduled"
+// @Failure 500 {object} gin.H "Failed to reschedule the event"
+// @Router /api/v1/events/{id}/reschedule [post]
+func (app *application) rescheduleEvent(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "reschedule")
+	if !ok {
+		return
+	}
+
+	var request rescheduleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	switch event.Status {
+	case database.EventScheduled, database.EventOpen:
+	case database.EventPostponed:
+		event.Status = database.EventScheduled
+	default:
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("An event that is %s can't be rescheduled.", event.Status)})
+		return
+	}
+
+	if err := app.models.Events.Reschedule(event, request.Date); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reschedule the event."})
+		return
+	}
+
+	c.JSON(http.StatusOK, event)
+}
+
 // AddAttendeeToEvent adds a rider to an event
 // @Summary Add a rider to an event
-// @Description Add a rider as an attendee to an event
+// @Description Enters a rider in an event class, the rider's own class unless another is given. Entries are only taken while the event is open. Once the class is at capacity further riders are waitlisted, and are entered in turn as spots free up. A rider who withdrew or was rejected can enter again, at the back of the line. The owner of a rider can request entry to events they don't own; the request is pending until the event owner approves or rejects it.
 // @Tags attendees
 // @Param id path int true "Event ID"
 // @Param riderId path int true "Rider ID"
+// @Param class query string false "Class to enter, defaults to the rider's class"
 // @Success 201 {object} database.Attendee
-// @Failure 400 {object} gin.H "Invalid event or rider ID"
-// @Failure 401 {object} gin.H "Unauthorized to add attendee"
+// @Failure 400 {object} gin.H "Invalid event or rider ID, or a class the series doesn't run"
+// @Failure 401 {object} gin.H "Owns neither the event nor the rider"
 // @Failure 404 {object} gin.H "Event or rider not found"
-// @Failure 409 {object} gin.H "Rider already signed up for this event"
+// @Failure 409 {object} gin.H "Rider already signed up or event not open for entries"
 // @Failure 500 {object} gin.H "Failed to add rider to event"
 // @Router /api/v1/events/{id}/attendees/{riderId} [post]
 func (app *application) addAttendeeToEvent(c *gin.Context) {
@@ -206,6 +323,7 @@ func (app *application) addAttendeeToEvent(c *gin.Context) {
 	riderId, err := strconv.Atoi(c.Param("riderId"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+		return
 	}
 
 	event, err := app.models.Events.Get(eventId)
@@ -224,34 +342,77 @@ func (app *application) addAttendeeToEvent(c *gin.Context) {
 		return
 	}
 
+	if riderToAdd == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rider not found."})
+		return
+	}
+
 	user := app.GetUserFromContext(c)
+	eventOwner := user.Id == event.OwnerId
 
-	if user.Id != event.OwnerId {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized to add to a rider to an event you don't own."})
+	if !eventOwner && user.Id != riderToAdd.OwnerId {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized to add a rider you don't own to an event you don't own."})
 		return
 	}
 
-	if riderToAdd == nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Rider not found."})
+	if event.Status != database.EventOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Entries are only accepted while the event is open."})
+		return
 	}
 
-	existingAttendee, err := app.models.Attendees.GetByEventAndAttendee(event.Id, riderToAdd.Id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendee."})
+	class := c.DefaultQuery("class", riderToAdd.Class)
+	if class == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The rider has no class, give the class to enter them in."})
 		return
 	}
 
-	if existingAttendee != nil {
-		c.JSON(http.StatusConflict, gin.H{"error": "Rider already signed up for this event!"})
+	series, ok := app.eventSeries(c, event)
+	if !ok {
+		return
+	}
+	if !series.HasClass(class) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s doesn't run a %s class.", series.Name, class)})
 		return
 	}
 
+	errAlreadyEntered := errors.New("already entered")
 	attendee := database.Attendee{
 		EventId: event.Id,
 		RiderId: riderToAdd.Id,
+		Class:   class,
+	}
+
+	err = app.models.Transaction(func(tx database.Models) error {
+		existingAttendee, err := tx.Attendees.GetByEventAndAttendee(event.Id, riderToAdd.Id)
+		if err != nil {
+			return err
+		}
+		if existingAttendee != nil {
+			if !existingAttendee.Closed() {
+				return errAlreadyEntered
+			}
+			if err := tx.Attendees.Delete(riderToAdd.Id, event.Id); err != nil {
+				return err
+			}
+		}
+
+		if eventOwner {
+			attendee.Status, err = entryStatus(tx, event.Id, class)
+			if err != nil {
+				return err
+			}
+		} else {
+			attendee.Status = database.EntryPending
+			attendee.RequestedBy = &user.Id
+		}
+
+		_, err = tx.Attendees.Insert(&attendee)
+		return err
+	})
+	if errors.Is(err, errAlreadyEntered) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Rider already signed up for this event!"})
+		return
 	}
-
-	_, err = app.models.Attendees.Insert(&attendee)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add rider to event."})
 		return
@@ -262,7 +423,7 @@ func (app *application) addAttendeeToEvent(c *gin.Context) {
 
 // GetAttendeesForEvent gets all attendees for an event
 // @Summary Get attendees for an event
-// @Description Get a list of riders attending an event
+// @Description Get a list of riders attending an event, with class set to the class each is entered in
 // @Tags attendees
 // @Param id path int true "Event ID"
 // @Success 200 {array} database.Rider
@@ -287,13 +448,14 @@ func (app *application) getAttendeesForEvent(c *gin.Context) {
 
 // DeleteAttendeeFromEvent removes a rider from an event
 // @Summary Remove a rider from an event
-// @Description Remove a rider as an attendee from an event
+// @Description Withdraws a rider's entry or entry request from an event. The entry is kept, marked withdrawn. If the rider held a spot, the next waitlisted rider in their class is entered in it. Both the event owner and the rider's owner can withdraw an entry.
 // @Tags attendees
 // @Param id path int true "Event ID"
 // @Param riderId path int true "Rider ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} gin.H "Invalid event or rider ID"
-// @Failure 401 {object} gin.H "Unauthorized as you don't own the event"
+// @Failure 401 {object} gin.H "Owns neither the event nor the rider"
+// @Failure 404 {object} gin.H "Event not found or rider not entered"
 // @Failure 500 {object} gin.H "Failed to delete attendee"
 // @Router /api/v1/events/{id}/attendees/{riderId} [delete]
 func (app *application) deleteAttendeeFromEvent(c *gin.Context) {
@@ -314,15 +476,51 @@ func (app *application) deleteAttendeeFromEvent(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the requested event."})
 		return
 	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return
+	}
 
 	user := app.GetUserFromContext(c)
 
 	if user.Id != event.OwnerId {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized as you don't own the event."})
+		rider, err := app.models.Riders.Get(riderId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+			return
+		}
+		if rider == nil || rider.OwnerId != user.Id {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized as you own neither the event nor the rider."})
+			return
+		}
+	}
+
+	errNotEntered := errors.New("not entered")
+
+	err = app.models.Transaction(func(tx database.Models) error {
+		attendee, err := tx.Attendees.GetByEventAndAttendee(id, riderId)
+		if err != nil {
+			return err
+		}
+		if attendee == nil || attendee.Closed() {
+			return errNotEntered
+		}
+
+		heldSpot := attendee.Active()
+		if err := tx.Attendees.SetStatus(attendee, database.EntryWithdrawn); err != nil {
+			return err
+		}
+		if !heldSpot {
+			return nil
+		}
+
+		_, err = promoteWaitlisted(tx, id, attendee.Class)
+		return err
+	})
+	if errors.Is(err, errNotEntered) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rider is not entered in this event."})
 		return
 	}
-
-	err = app.models.Attendees.Delete(riderId, id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete attendee."})
 		return
@@ -334,7 +532,7 @@ func (app *application) deleteAttendeeFromEvent(c *gin.Context) {
 
 // GetEventsByAttendee gets all events for an attendee
 // @Summary Get events for an attendee
-// @Description Get a list of events that a rider is attending
+// @Description Get a list of events that a rider is attending. A rider merged into another gets the events of the rider they were merged into.
 // @Tags attendees
 // @Param id path int true "Attendee ID"
 // @Success 200 {array} database.Event
@@ -348,6 +546,16 @@ func (app *application) getEventsByAttendee(c *gin.Context) {
 		return
 	}
 
+	// A rider merged into another took their entries with them.
+	toId, err := app.models.Riders.Redirect(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+		return
+	}
+	if toId != 0 {
+		id = toId
+	}
+
 	events, err := app.models.Events.GetByAttendee(id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// exportFlushEvery is how many rows are written between flushes to the client.
+const exportFlushEvery = 200
+
+// ExportDataset streams a dataset as CSV or NDJSON
+// @Summary Export riders, events, attendees or results
+// @Description Streams a dataset straight from the database with a stable column order. CSV starts with a header row, NDJSON objects keep the same key order, and the X-Export-Schema header lists every column with its type (int64 or string).
+// @Tags export
+// @Produce text/csv,application/x-ndjson
+// @Param dataset path string true "riders, events, attendees or results"
+// @Param series query string false "Only rows for this series, by ID or slug"
+// @Param season query int false "Only rows for this season"
+// @Param format query string false "csv (default) or ndjson"
+// @Success 200 "The exported rows"
+// @Failure 400 {object} gin.H "Invalid season or format"
+// @Failure 404 {object} gin.H "Unknown dataset or series"
+// @Failure 500 {object} gin.H "Failed to export"
+// @Router /api/v1/export/{dataset} [get]
+func (app *application) exportDataset(c *gin.Context) {
+	dataset := c.Param("dataset")
+	columns, ok := database.ExportColumns(dataset)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown export, use riders, events, attendees or results."})
+		return
+	}
+
+	seriesId, ok := app.seriesFilter(c)
+	if !ok {
+		return
+	}
+
+	season := 0
+	if value := c.Query("season"); value != "" {
+		var err error
+		if season, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season."})
+			return
+		}
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "ndjson" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format must be csv or ndjson."})
+		return
+	}
+
+	filename := dataset
+	if seriesId != 0 {
+		filename = fmt.Sprintf("%s-%s", filename, c.Query("series"))
+	}
+	if season != 0 {
+		filename = fmt.Sprintf("%s-%d", filename, season)
+	}
+
+	schema := make([]string, len(columns))
+	for i, column := range columns {
+		schema[i] = column.Name + ":" + column.Type
+	}
+
+	c.Header("X-Export-Schema", strings.Join(schema, ","))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", filename, format))
+
+	var write func(row []any) error
+	var flush func() error
+
+	if format == "csv" {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+
+		writer := csv.NewWriter(c.Writer)
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.Name
+		}
+		writer.Write(header)
+
+		record := make([]string, len(columns))
+		write = func(row []any) error {
+			for i, value := range row {
+				record[i] = ""
+				if value != nil {
+					record[i] = fmt.Sprint(value)
+				}
+			}
+			return writer.Write(record)
+		}
+		flush = func() error {
+			writer.Flush()
+			return writer.Error()
+		}
+	} else {
+		c.Header("Content-Type", "application/x-ndjson")
+
+		// Objects are assembled by hand because encoding/json sorts map keys.
+		var line []byte
+		write = func(row []any) error {
+			line = append(line[:0], '{')
+			for i, value := range row {
+				if i > 0 {
+					line = append(line, ',')
+				}
+				key, _ := json.Marshal(columns[i].Name)
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return err
+				}
+				line = append(append(append(line, key...), ':'), encoded...)
+			}
+			line = append(line, '}', '\n')
+			_, err := c.Writer.Write(line)
+			return err
+		}
+		flush = func() error { return nil }
+	}
+
+	c.Status(http.StatusOK)
+
+	written := 0
+	err := app.models.Exports.Stream(c.Request.Context(), dataset, seriesId, season, func(row []any) error {
+		if err := write(row); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+
+	if err == nil {
+		err = flush()
+	}
+
+	// Headers are already on the wire, so a failure can only cut the stream short.
+	if err != nil {
+		log.Printf("export %s: %v", dataset, err)
+		c.Abort()
+	}
+}
+
+// GetExportSchema returns the columns of an export
+// @Summary Get the schema of an export
+// @Description Returns the ordered columns and their types for an export dataset
+// @Tags export
+// @Produce json
+// @Param dataset path string true "riders, events, attendees or results"
+// @Success 200 {array} database.ExportColumn
+// @Failure 404 {object} gin.H "Unknown dataset"
+// @Router /api/v1/export/{dataset}/schema [get]
+func (app *application) getExportSchema(c *gin.Context) {
+	columns, ok := database.ExportColumns(c.Param("dataset"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown export, use riders, events, attendees or results."})
+		return
+	}
+
+	c.JSON(http.StatusOK, columns)
+}
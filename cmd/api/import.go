@@ -0,0 +1,551 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const maxImportSize = 10 << 20
+
+// errRollback aborts an import transaction without it being a server error.
+var errRollback = errors.New("import rolled back")
+
+// importRow is a single record from an uploaded file. CSV rows carry their
+// fields keyed by lower-cased header, NDJSON rows carry the raw object. A
+// record that couldn't be read carries the error instead and is rejected.
+type importRow struct {
+	Line    int
+	columns []string
+	fields  map[string]string
+	raw     []byte
+	err     error
+}
+
+type importRowReport struct {
+	Line   int    `json:"line"`
+	Action string `json:"action"`
+	Id     int    `json:"id,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type importReport struct {
+	DryRun    bool              `json:"dryRun"`
+	Committed bool              `json:"committed"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Rejected  int               `json:"rejected"`
+	Rows      []importRowReport `json:"rows"`
+}
+
+type resultImportRow struct {
+	Number    int    `json:"number" binding:"required"`
+	FirstName string `json:"firstName" binding:"required"`
+	LastName  string `json:"lastName" binding:"required"`
+	Class     string `json:"class" binding:"required"`
+	Moto      int    `json:"moto" binding:"required,min=1"`
+	Position  int    `json:"position" binding:"required,min=1"`
+	Status    string `json:"status" binding:"omitempty,oneof=finished dnf dns dq"`
+}
+
+func created(line, id int) importRowReport {
+	return importRowReport{Line: line, Action: "created", Id: id}
+}
+
+func updated(line, id int) importRowReport {
+	return importRowReport{Line: line, Action: "updated", Id: id}
+}
+
+func rejected(line int, reason string) importRowReport {
+	return importRowReport{Line: line, Action: "rejected", Reason: reason}
+}
+
+// ImportRiders creates or updates riders from a CSV or NDJSON file
+// @Summary Bulk import riders ** Auth Required **
+// @Description Import riders from CSV (text/csv) or NDJSON (application/x-ndjson). Riders are matched on number and name, so re-imports update instead of duplicating. Numbers are registered in the latest season like with single riders, and rows whose number is taken or reserved are rejected. Malformed lines and unknown columns or fields are rejected, and any rejected row rolls back the whole import.
+// @Tags import
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param dryRun query bool false "Validate and report without saving"
+// @Param format query string false "csv or ndjson, overrides the Content-Type"
+// @Success 200 {object} importReport
+// @Failure 400 {object} gin.H "Unreadable file"
+// @Failure 422 {object} importReport "One or more rows were rejected"
+// @Failure 500 {object} gin.H "Failed to import riders"
+// @Router /api/v1/import/riders [post]
+func (app *application) importRiders(c *gin.Context) {
+	user := app.GetUserFromContext(c)
+
+	season, err := app.models.Events.LatestSeason(database.DefaultSeriesId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events."})
+		return
+	}
+
+	var conflict *numberConflict
+	register := func(tx database.Models, line int, rider *database.Rider, report importRowReport) (importRowReport, error) {
+		err := registerNumber(tx, season, rider)
+		if errors.As(err, &conflict) {
+			return rejected(line, conflict.Error()), nil
+		}
+		if err != nil {
+			return importRowReport{}, err
+		}
+		return report, nil
+	}
+
+	app.runImport(c, func(tx database.Models, row importRow) (importRowReport, error) {
+		var rider database.Rider
+		if err := row.decode(&rider); err != nil {
+			return rejected(row.Line, err.Error()), nil
+		}
+
+		rider.OwnerId = user.Id
+		if rider.Status == "" {
+			rider.Status = "active"
+		}
+
+		if err := binding.Validator.ValidateStruct(&rider); err != nil {
+			return rejected(row.Line, err.Error()), nil
+		}
+
+		existing, err := tx.Riders.GetByNumberAndName(rider.Number, rider.FirstName, rider.LastName)
+		if err != nil {
+			return importRowReport{}, err
+		}
+
+		if existing == nil {
+			if err := tx.Riders.Insert(&rider); err != nil {
+				return importRowReport{}, err
+			}
+			return register(tx, row.Line, &rider, created(row.Line, rider.Id))
+		}
+
+		if existing.OwnerId != user.Id {
+			return rejected(row.Line, fmt.Sprintf("Rider #%d %s %s belongs to another user.", rider.Number, rider.FirstName, rider.LastName)), nil
+		}
+
+		rider.Id = existing.Id
+		if rider.CareerPoints == 0 {
+			rider.CareerPoints = existing.CareerPoints
+		}
+
+		if err := tx.Riders.Update(&rider); err != nil {
+			return importRowReport{}, err
+		}
+		return register(tx, row.Line, &rider, updated(row.Line, rider.Id))
+	}, nil)
+}
+
+// ImportResults creates or updates moto results for an event from a CSV or NDJSON file
+// @Summary Bulk import moto results ** Auth Required **
+// @Description Import moto results for an event from CSV (text/csv) or NDJSON (application/x-ndjson). Riders are matched on number and name, results on rider, class and moto. Points are paid on the event's series table, and rows in a class the series doesn't run are rejected. Results are only taken while the event is in progress or provisional. Malformed lines and unknown columns or fields are rejected, and any rejected row rolls back the whole import. Committed changes are broadcast to live subscribers as "leaderboard" deltas.
+// @Tags import
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param dryRun query bool false "Validate and report without saving"
+// @Param format query string false "csv or ndjson, overrides the Content-Type"
+// @Success 200 {object} importReport
+// @Failure 400 {object} gin.H "Invalid event ID or unreadable file"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 422 {object} importReport "One or more rows were rejected"
+// @Failure 409 {object} gin.H "Event not in progress or provisional"
+// @Failure 500 {object} gin.H "Failed to import results"
+// @Router /api/v1/events/{id}/import/results [post]
+func (app *application) importResults(c *gin.Context) {
+	eventId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event Id."})
+		return
+	}
+
+	event, err := app.models.Events.Get(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event."})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	if user.Id != event.OwnerId {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to import results for an event you don't own."})
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	series, ok := app.eventSeries(c, event)
+	if !ok {
+		return
+	}
+
+	before, err := app.models.Results.GetByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve results."})
+		return
+	}
+
+	positions := map[string]int{}
+	motos := map[motoSlot]bool{}
+
+	committed := app.runImport(c, func(tx database.Models, row importRow) (importRowReport, error) {
+		var input resultImportRow
+		if err := row.decode(&input); err != nil {
+			return rejected(row.Line, err.Error()), nil
+		}
+
+		if err := binding.Validator.ValidateStruct(&input); err != nil {
+			return rejected(row.Line, err.Error()), nil
+		}
+
+		if !series.HasClass(input.Class) {
+			return rejected(row.Line, fmt.Sprintf("%s doesn't run a %s class.", series.Name, input.Class)), nil
+		}
+
+		slot := fmt.Sprintf("%s/%d/%d", input.Class, input.Moto, input.Position)
+		if line, taken := positions[slot]; taken {
+			return rejected(row.Line, fmt.Sprintf("Position %d in %s moto %d is already taken on line %d.", input.Position, input.Class, input.Moto, line)), nil
+		}
+		positions[slot] = row.Line
+
+		rider, err := tx.Riders.GetByNumberAndName(input.Number, input.FirstName, input.LastName)
+		if err != nil {
+			return importRowReport{}, err
+		}
+		if rider == nil {
+			return rejected(row.Line, fmt.Sprintf("No rider #%d %s %s.", input.Number, input.FirstName, input.LastName)), nil
+		}
+
+		result := database.Result{
+			EventId:   event.Id,
+			RiderId:   rider.Id,
+			Class:     input.Class,
+			Moto:      input.Moto,
+			Position:  input.Position,
+			Status:    input.Status,
+			Team:      rider.Team,
+			BikeBrand: rider.BikeBrand,
+		}
+
+		if result.Status == "" {
+			result.Status = database.ResultFinished
+		}
+		if result.Status == database.ResultFinished {
+			result.Points = series.PointsFor(result.Position)
+		}
+
+		motos[motoSlot{result.Class, result.Moto}] = true
+
+		attendee, err := tx.Attendees.GetByEventAndAttendee(event.Id, rider.Id)
+		if err != nil {
+			return importRowReport{}, err
+		}
+		if attendee == nil {
+			if _, err := tx.Attendees.Insert(&database.Attendee{EventId: event.Id, RiderId: rider.Id, Class: result.Class}); err != nil {
+				return importRowReport{}, err
+			}
+		}
+
+		existing, err := tx.Results.GetByEventRiderMoto(event.Id, rider.Id, result.Class, result.Moto)
+		if err != nil {
+			return importRowReport{}, err
+		}
+
+		if existing == nil {
+			if err := tx.Results.Insert(&result); err != nil {
+				return importRowReport{}, err
+			}
+			return created(row.Line, result.Id), nil
+		}
+
+		result.Id = existing.Id
+		if err := tx.Results.Update(&result); err != nil {
+			return importRowReport{}, err
+		}
+		return updated(row.Line, result.Id), nil
+	}, func(tx database.Models) error {
+		// Imported rows are how riders finished, so motos with penalties
+		// standing are classified again on top of them, and the overall
+		// results of every class imported follow.
+		classes := map[string]bool{}
+		for moto := range motos {
+			classes[moto.class] = true
+			penalties, err := tx.Penalties.GetActiveByMoto(event.Id, moto.class, moto.moto)
+			if err != nil {
+				return err
+			}
+			if len(penalties) == 0 {
+				continue
+			}
+			if _, err := app.reclassifyMoto(tx, event.Id, moto.class, moto.moto, "Results imported", user.Id); err != nil {
+				return err
+			}
+		}
+
+		for class := range classes {
+			if err := tx.Overall.Recompute(event.Id, class); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if committed {
+		after, err := app.models.Results.GetByEvent(event.Id)
+		if err != nil {
+			log.Printf("import: reloading results of event %d: %v", event.Id, err)
+			return
+		}
+		app.publishResultDeltas(event.Id, before, after)
+	}
+}
+
+// runImport applies every row inside one transaction and responds with the
+// per-row report. Dry runs and imports with rejected rows are rolled back.
+// Otherwise finish, when given, runs in the same transaction before it is
+// committed, and failing it fails the import. It reports whether the
+// import was committed.
+func (app *application) runImport(c *gin.Context, apply func(tx database.Models, row importRow) (importRowReport, error), finish func(tx database.Models) error) bool {
+	rows, err := readImportRows(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return false
+	}
+
+	report := importReport{DryRun: c.Query("dryRun") == "true", Rows: []importRowReport{}}
+
+	err = app.models.Transaction(func(tx database.Models) error {
+		for _, row := range rows {
+			if row.err != nil {
+				report.Rejected++
+				report.Rows = append(report.Rows, rejected(row.Line, row.err.Error()))
+				continue
+			}
+
+			result, err := apply(tx, row)
+			if err != nil {
+				return err
+			}
+
+			switch result.Action {
+			case "created":
+				report.Created++
+			case "updated":
+				report.Updated++
+			default:
+				report.Rejected++
+			}
+			report.Rows = append(report.Rows, result)
+		}
+
+		if report.DryRun || report.Rejected > 0 {
+			return errRollback
+		}
+		if finish != nil {
+			return finish(tx)
+		}
+		return nil
+	})
+
+	if err != nil && err != errRollback {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import the file."})
+		return false
+	}
+
+	report.Committed = err == nil
+
+	if report.Rejected > 0 {
+		c.JSON(http.StatusUnprocessableEntity, report)
+		return false
+	}
+
+	c.JSON(http.StatusOK, report)
+	return report.Committed
+}
+
+// readImportRows reads the request body as CSV or NDJSON, picked by the
+// format query parameter or the Content-Type header.
+func readImportRows(c *gin.Context) ([]importRow, error) {
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+
+	format := c.Query("format")
+	if format == "" {
+		switch c.ContentType() {
+		case "text/csv", "application/csv":
+			format = "csv"
+		case "application/x-ndjson", "application/ndjson", "application/jsonl":
+			format = "ndjson"
+		default:
+			return nil, fmt.Errorf("send text/csv or application/x-ndjson, or set ?format=csv|ndjson")
+		}
+	}
+
+	switch format {
+	case "csv":
+		return readCSVRows(body)
+	case "ndjson":
+		return readNDJSONRows(body)
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+// readCSVRows reads a CSV file with a header row. Records that are
+// malformed or have the wrong number of fields become rejected rows, so the
+// rest of the file is still checked.
+func readCSVRows(r io.Reader) ([]importRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading CSV header: %w", err)
+	}
+
+	for i := range header {
+		header[i] = strings.ToLower(strings.TrimSpace(header[i]))
+	}
+
+	var rows []importRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, importRow{Line: parseErr.StartLine, err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]string, len(header))
+		for i, value := range record {
+			fields[header[i]] = strings.TrimSpace(value)
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, importRow{Line: line, columns: header, fields: fields})
+	}
+
+	return rows, nil
+}
+
+func readNDJSONRows(r io.Reader) ([]importRow, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+
+	var rows []importRow
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		rows = append(rows, importRow{Line: line, raw: append([]byte(nil), raw...)})
+	}
+
+	return rows, scanner.Err()
+}
+
+// decode fills dst from the row. CSV columns are matched to the struct's
+// json tags case-insensitively, and []int64 columns are semicolon separated.
+// Columns, like NDJSON fields, that dst has no place for are an error.
+func (r importRow) decode(dst any) error {
+	if r.raw != nil {
+		decoder := json.NewDecoder(bytes.NewReader(r.raw))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(dst)
+	}
+
+	v := reflect.ValueOf(dst).Elem()
+	t := v.Type()
+
+	known := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		known[strings.ToLower(strings.Split(t.Field(i).Tag.Get("json"), ",")[0])] = true
+	}
+	for _, column := range r.columns {
+		if column == "" || column == "-" || !known[column] {
+			return fmt.Errorf("unknown column %q", column)
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		value, ok := r.fields[strings.ToLower(name)]
+		if name == "" || !ok || value == "" {
+			continue
+		}
+
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.String:
+			field.SetString(value)
+		case reflect.Int:
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a number", name, value)
+			}
+			field.SetInt(int64(n))
+		case reflect.Int64:
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a number", name, value)
+			}
+			field.SetInt(n)
+		case reflect.Slice:
+			// Lists such as sector times are separated by semicolons so
+			// they fit in one CSV column.
+			if field.Type().Elem().Kind() != reflect.Int64 {
+				continue
+			}
+			parts := strings.Split(value, ";")
+			list := reflect.MakeSlice(field.Type(), len(parts), len(parts))
+			for j, part := range parts {
+				n, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+				if err != nil {
+					return fmt.Errorf("%s: %q is not a list of numbers", name, value)
+				}
+				list.Index(j).SetInt(n)
+			}
+			field.Set(list)
+		case reflect.Float64:
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not a number", name, value)
+			}
+			field.SetFloat(f)
+		case reflect.Bool:
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("%s: %q is not true or false", name, value)
+			}
+			field.SetBool(b)
+		}
+	}
+
+	return nil
+}
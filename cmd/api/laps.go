@@ -0,0 +1,395 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+type lapImportRow struct {
+	Number    int     `json:"number" binding:"required"`
+	Class     string  `json:"class"`
+	Lap       int     `json:"lap" binding:"required,min=1"`
+	LapTimeMs int64   `json:"lapTimeMs" binding:"required,min=1"`
+	SectorsMs []int64 `json:"sectorsMs" binding:"dive,min=1"`
+	Position  int     `json:"position" binding:"omitempty,min=1"`
+}
+
+// fastestLap points at the quickest lap of a moto.
+type fastestLap struct {
+	RiderId     int   `json:"riderId"`
+	RiderNumber int   `json:"riderNumber"`
+	Lap         int   `json:"lap"`
+	LapTimeMs   int64 `json:"lapTimeMs"`
+}
+
+// riderLapStats summarises one rider's laps. StdDevMs is the standard
+// deviation of their lap times, so lower is more consistent. IdealLapMs adds
+// up their best time in each sector and is 0 when no splits were recorded.
+type riderLapStats struct {
+	RiderId       int     `json:"riderId"`
+	RiderNumber   int     `json:"riderNumber"`
+	Laps          int     `json:"laps"`
+	TotalMs       int64   `json:"totalMs"`
+	BestLap       int     `json:"bestLap"`
+	BestLapMs     int64   `json:"bestLapMs"`
+	AverageMs     int64   `json:"averageMs"`
+	StdDevMs      int64   `json:"stdDevMs"`
+	BestSectorsMs []int64 `json:"bestSectorsMs"`
+	IdealLapMs    int64   `json:"idealLapMs"`
+}
+
+type chartPoint struct {
+	Lap   int   `json:"lap"`
+	Value int64 `json:"value"`
+}
+
+// riderSeries is one line of a chart, with a point per lap.
+type riderSeries struct {
+	RiderId     int          `json:"riderId"`
+	RiderNumber int          `json:"riderNumber"`
+	Points      []chartPoint `json:"points"`
+}
+
+// motoLaps is every lap of a moto along with the analysis coaches look at.
+// GapToLeader values are milliseconds behind whoever had the lowest total
+// time at the end of that lap; Positions values are positions.
+type motoLaps struct {
+	EventId     int             `json:"eventId"`
+	Class       string          `json:"class"`
+	Moto        int             `json:"moto"`
+	FastestLap  *fastestLap     `json:"fastestLap"`
+	Riders      []riderLapStats `json:"riders"`
+	GapToLeader []riderSeries   `json:"gapToLeader"`
+	Positions   []riderSeries   `json:"positions"`
+	Laps        []*database.Lap `json:"laps"`
+}
+
+// ImportLaps records lap times for a moto from a CSV or NDJSON file
+// @Summary Bulk import lap times ** Auth Required **
+// @Description Import lap times for a moto from CSV (text/csv) or NDJSON (application/x-ndjson) with number, class, lap, lapTimeMs, sectorsMs and position. In CSV, sector splits are separated by semicolons. Riders are matched on class and number among the event's entries, laps on rider, class and lap, so re-sending a lap updates it. Malformed lines and unknown columns or fields are rejected, and any rejected row rolls back the whole import. Holeshots and laps led not entered by hand are derived from the saved laps.
+// @Tags laps
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param moto path int true "Moto number"
+// @Param class query string false "Class for rows that don't give one"
+// @Param dryRun query bool false "Validate and report without saving"
+// @Param format query string false "csv or ndjson, overrides the Content-Type"
+// @Success 200 {object} importReport
+// @Failure 400 {object} gin.H "Invalid event ID, moto or unreadable file"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event not in progress or provisional"
+// @Failure 422 {object} importReport "One or more rows were rejected"
+// @Failure 500 {object} gin.H "Failed to import laps"
+// @Router /api/v1/events/{id}/motos/{moto}/laps [post]
+func (app *application) importLaps(c *gin.Context) {
+	eventId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event Id."})
+		return
+	}
+
+	moto, err := strconv.Atoi(c.Param("moto"))
+	if err != nil || moto < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+		return
+	}
+
+	event, err := app.models.Events.Get(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event."})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	if user.Id != event.OwnerId {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to import laps for an event you don't own."})
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	entries, err := app.models.Attendees.GetAttendeesByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve attendees."})
+		return
+	}
+
+	riders := map[entryKey]int{}
+	for _, rider := range entries {
+		riders[entryKey{rider.Class, rider.Number}] = rider.Id
+	}
+
+	defaultClass := c.Query("class")
+	seen := map[string]int{}
+
+	committed := app.runImport(c, func(tx database.Models, row importRow) (importRowReport, error) {
+		var input lapImportRow
+		if err := row.decode(&input); err != nil {
+			return rejected(row.Line, err.Error()), nil
+		}
+
+		if err := binding.Validator.ValidateStruct(&input); err != nil {
+			return rejected(row.Line, err.Error()), nil
+		}
+
+		if input.Class == "" {
+			input.Class = defaultClass
+		}
+		if input.Class == "" {
+			return rejected(row.Line, "No class given."), nil
+		}
+
+		riderId, ok := riders[entryKey{input.Class, input.Number}]
+		if !ok {
+			return rejected(row.Line, fmt.Sprintf("Rider #%d is not entered in %s at this event.", input.Number, input.Class)), nil
+		}
+
+		key := fmt.Sprintf("%s/%d/%d", input.Class, input.Number, input.Lap)
+		if line, dup := seen[key]; dup {
+			return rejected(row.Line, fmt.Sprintf("Lap %d of #%d in %s is already on line %d.", input.Lap, input.Number, input.Class, line)), nil
+		}
+		seen[key] = row.Line
+
+		lap := database.Lap{
+			EventId:   event.Id,
+			RiderId:   riderId,
+			Class:     input.Class,
+			Moto:      moto,
+			Lap:       input.Lap,
+			LapTimeMs: input.LapTimeMs,
+			SectorsMs: input.SectorsMs,
+			Position:  input.Position,
+		}
+
+		existing, err := tx.Laps.GetByRiderLap(event.Id, riderId, lap.Class, moto, lap.Lap)
+		if err != nil {
+			return importRowReport{}, err
+		}
+
+		if existing == nil {
+			if err := tx.Laps.Insert(&lap); err != nil {
+				return importRowReport{}, err
+			}
+			return created(row.Line, lap.Id), nil
+		}
+
+		lap.Id = existing.Id
+		if err := tx.Laps.Update(&lap); err != nil {
+			return importRowReport{}, err
+		}
+		return updated(row.Line, lap.Id), nil
+	}, nil)
+
+	if committed {
+		app.deriveMotoAwards(event.Id, moto)
+	}
+}
+
+// GetMotoLaps returns the lap times of a moto with analysis
+// @Summary Lap times and analysis for a moto
+// @Description Returns every recorded lap of a moto with the fastest lap, each rider's average, consistency (standard deviation) and ideal lap, and chart series for the gap to the leader and position on each lap. The class can be left out when only one class has laps in the moto.
+// @Tags laps
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param moto path int true "Moto number"
+// @Param class query string false "Class, e.g. 450"
+// @Success 200 {object} motoLaps
+// @Failure 400 {object} gin.H "Invalid event ID, moto or missing class"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve laps"
+// @Router /api/v1/events/{id}/motos/{moto}/laps [get]
+func (app *application) getMotoLaps(c *gin.Context) {
+	eventId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event Id."})
+		return
+	}
+
+	moto, err := strconv.Atoi(c.Param("moto"))
+	if err != nil || moto < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+		return
+	}
+
+	event, err := app.models.Events.Get(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event."})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return
+	}
+
+	class := c.Query("class")
+	if class == "" {
+		classes, err := app.models.Laps.GetClassesByMoto(event.Id, moto)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve laps."})
+			return
+		}
+		if len(classes) > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("This moto has laps in more than one class, pick one of %v.", classes)})
+			return
+		}
+		if len(classes) == 1 {
+			class = classes[0]
+		}
+	}
+
+	laps, err := app.models.Laps.GetByMoto(event.Id, class, moto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve laps."})
+		return
+	}
+
+	c.JSON(http.StatusOK, analyseLaps(event.Id, class, moto, laps))
+}
+
+func analyseLaps(eventId int, class string, moto int, laps []*database.Lap) motoLaps {
+	analysis := motoLaps{
+		EventId:     eventId,
+		Class:       class,
+		Moto:        moto,
+		Riders:      []riderLapStats{},
+		GapToLeader: []riderSeries{},
+		Positions:   []riderSeries{},
+		Laps:        laps,
+	}
+
+	byRider := map[int][]*database.Lap{}
+	var riderIds []int
+	for _, lap := range laps {
+		if _, ok := byRider[lap.RiderId]; !ok {
+			riderIds = append(riderIds, lap.RiderId)
+		}
+		byRider[lap.RiderId] = append(byRider[lap.RiderId], lap)
+
+		if analysis.FastestLap == nil || lap.LapTimeMs < analysis.FastestLap.LapTimeMs {
+			analysis.FastestLap = &fastestLap{RiderId: lap.RiderId, RiderNumber: lap.RiderNumber, Lap: lap.Lap, LapTimeMs: lap.LapTimeMs}
+		}
+	}
+
+	// elapsed[rider][lap] is the rider's total time at the end of that lap,
+	// only known while their laps are unbroken from lap one.
+	elapsed := map[int]map[int]int64{}
+	lastLap := 0
+
+	for _, riderId := range riderIds {
+		riderLaps := byRider[riderId]
+		sort.Slice(riderLaps, func(i, j int) bool { return riderLaps[i].Lap < riderLaps[j].Lap })
+
+		stats := riderLapStats{RiderId: riderId, RiderNumber: riderLaps[0].RiderNumber, BestSectorsMs: []int64{}}
+		elapsed[riderId] = map[int]int64{}
+
+		var total int64
+		for i, lap := range riderLaps {
+			stats.Laps++
+			stats.TotalMs += lap.LapTimeMs
+			if stats.BestLapMs == 0 || lap.LapTimeMs < stats.BestLapMs {
+				stats.BestLapMs = lap.LapTimeMs
+				stats.BestLap = lap.Lap
+			}
+
+			for s, sector := range lap.SectorsMs {
+				if s == len(stats.BestSectorsMs) {
+					stats.BestSectorsMs = append(stats.BestSectorsMs, sector)
+				} else if sector < stats.BestSectorsMs[s] {
+					stats.BestSectorsMs[s] = sector
+				}
+			}
+
+			if lap.Lap == i+1 {
+				total += lap.LapTimeMs
+				elapsed[riderId][lap.Lap] = total
+				if lap.Lap > lastLap {
+					lastLap = lap.Lap
+				}
+			}
+		}
+
+		mean := float64(stats.TotalMs) / float64(stats.Laps)
+		var variance float64
+		for _, lap := range riderLaps {
+			d := float64(lap.LapTimeMs) - mean
+			variance += d * d
+		}
+		stats.AverageMs = int64(math.Round(mean))
+		stats.StdDevMs = int64(math.Round(math.Sqrt(variance / float64(stats.Laps))))
+
+		for _, sector := range stats.BestSectorsMs {
+			stats.IdealLapMs += sector
+		}
+
+		analysis.Riders = append(analysis.Riders, stats)
+	}
+
+	sort.SliceStable(analysis.Riders, func(i, j int) bool {
+		a, b := analysis.Riders[i], analysis.Riders[j]
+		if a.Laps != b.Laps {
+			return a.Laps > b.Laps
+		}
+		return a.TotalMs < b.TotalMs
+	})
+
+	positions := map[int]map[int]int{}
+	for _, lap := range laps {
+		if lap.Position > 0 {
+			if positions[lap.RiderId] == nil {
+				positions[lap.RiderId] = map[int]int{}
+			}
+			positions[lap.RiderId][lap.Lap] = lap.Position
+		}
+	}
+
+	gaps := map[int][]chartPoint{}
+	places := map[int][]chartPoint{}
+
+	for n := 1; n <= lastLap; n++ {
+		var done []int
+		for _, riderId := range riderIds {
+			if _, ok := elapsed[riderId][n]; ok {
+				done = append(done, riderId)
+			}
+		}
+		sort.SliceStable(done, func(i, j int) bool {
+			return elapsed[done[i]][n] < elapsed[done[j]][n]
+		})
+
+		for rank, riderId := range done {
+			gaps[riderId] = append(gaps[riderId], chartPoint{Lap: n, Value: elapsed[riderId][n] - elapsed[done[0]][n]})
+
+			// Timing systems know about riders who were lapped; fall back
+			// to ranking total time only when they didn't send a position.
+			position := positions[riderId][n]
+			if position == 0 {
+				position = rank + 1
+			}
+			places[riderId] = append(places[riderId], chartPoint{Lap: n, Value: int64(position)})
+		}
+	}
+
+	for _, stats := range analysis.Riders {
+		if points, ok := gaps[stats.RiderId]; ok {
+			analysis.GapToLeader = append(analysis.GapToLeader, riderSeries{RiderId: stats.RiderId, RiderNumber: stats.RiderNumber, Points: points})
+			analysis.Positions = append(analysis.Positions, riderSeries{RiderId: stats.RiderId, RiderNumber: stats.RiderNumber, Points: places[stats.RiderId]})
+		}
+	}
+
+	return analysis
+}
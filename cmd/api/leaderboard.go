@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/timing"
+)
+
+// leaderboardChange is one rider whose place on a leaderboard moved, or who
+// appeared on it for the first time, in which case PreviousPosition is 0.
+type leaderboardChange struct {
+	RiderId          int    `json:"riderId,omitempty"`
+	RiderNumber      int    `json:"riderNumber"`
+	Position         int    `json:"position"`
+	PreviousPosition int    `json:"previousPosition"`
+	Laps             int    `json:"laps,omitempty"`
+	Points           int    `json:"points,omitempty"`
+	Status           string `json:"status,omitempty"`
+}
+
+// leaderboardDelta is published as a "leaderboard" message whenever live
+// timing or recorded results change the order of a moto. Source is "timing"
+// for the running order and "results" for official results.
+type leaderboardDelta struct {
+	EventId int                 `json:"eventId"`
+	Class   string              `json:"class"`
+	Moto    int                 `json:"moto"`
+	Source  string              `json:"source"`
+	Changes []leaderboardChange `json:"changes"`
+}
+
+func classTopic(eventId int, class string) string {
+	return fmt.Sprintf("event:%d:class:%s", eventId, class)
+}
+
+func riderTopic(riderId int) string {
+	return fmt.Sprintf("rider:%d", riderId)
+}
+
+// publishTimingDelta compares a moto's running order before and after a
+// batch of crossings and publishes the riders who moved or gained a lap.
+func (app *application) publishTimingDelta(previous, board timing.Board) {
+	before := map[int]timing.Standing{}
+	for _, standing := range previous.Standings {
+		before[standing.RiderNumber] = standing
+	}
+
+	var changes []leaderboardChange
+	for _, standing := range board.Standings {
+		old, seen := before[standing.RiderNumber]
+		if seen && old.Position == standing.Position && old.Laps == standing.Laps {
+			continue
+		}
+
+		changes = append(changes, leaderboardChange{
+			RiderNumber:      standing.RiderNumber,
+			Position:         standing.Position,
+			PreviousPosition: old.Position,
+			Laps:             standing.Laps,
+		})
+	}
+
+	if len(changes) == 0 {
+		return
+	}
+
+	riders, err := app.models.Attendees.GetAttendeesByEvent(board.EventId)
+	if err != nil {
+		log.Printf("live: loading riders of event %d: %v", board.EventId, err)
+	}
+
+	ids := map[entryKey]int{}
+	for _, rider := range riders {
+		ids[entryKey{rider.Class, rider.Number}] = rider.Id
+	}
+	for i := range changes {
+		changes[i].RiderId = ids[entryKey{board.Class, changes[i].RiderNumber}]
+	}
+
+	app.publishDelta(leaderboardDelta{EventId: board.EventId, Class: board.Class, Moto: board.Moto, Source: "timing", Changes: changes})
+}
+
+// entryKey is a rider's number in the class they are entered in at an
+// event. Numbers are only unique within a class.
+type entryKey struct {
+	class  string
+	number int
+}
+
+// motoSlot is one class's moto at an event.
+type motoSlot struct {
+	class string
+	moto  int
+}
+
+// publishResultDeltas compares an event's results before and after they
+// were recorded and publishes a delta for every moto that changed.
+func (app *application) publishResultDeltas(eventId int, before, after []*database.Result) {
+	previous := map[string]*database.Result{}
+	for _, result := range before {
+		previous[fmt.Sprintf("%s/%d/%d", result.Class, result.Moto, result.RiderId)] = result
+	}
+
+	riders, err := app.models.Attendees.GetAttendeesByEvent(eventId)
+	if err != nil {
+		log.Printf("live: loading riders of event %d: %v", eventId, err)
+	}
+
+	numbers := map[int]int{}
+	for _, rider := range riders {
+		numbers[rider.Id] = rider.Number
+	}
+
+	changed := map[motoSlot][]leaderboardChange{}
+	for _, result := range after {
+		old := previous[fmt.Sprintf("%s/%d/%d", result.Class, result.Moto, result.RiderId)]
+		if old != nil && old.Position == result.Position && old.Points == result.Points && old.Status == result.Status {
+			continue
+		}
+
+		change := leaderboardChange{
+			RiderId:     result.RiderId,
+			RiderNumber: numbers[result.RiderId],
+			Position:    result.Position,
+			Points:      result.Points,
+			Status:      result.Status,
+		}
+		if old != nil {
+			change.PreviousPosition = old.Position
+		}
+
+		key := motoSlot{result.Class, result.Moto}
+		changed[key] = append(changed[key], change)
+	}
+
+	slots := make([]motoSlot, 0, len(changed))
+	for key := range changed {
+		slots = append(slots, key)
+	}
+	sort.Slice(slots, func(i, j int) bool {
+		if slots[i].class != slots[j].class {
+			return slots[i].class < slots[j].class
+		}
+		return slots[i].moto < slots[j].moto
+	})
+
+	for _, key := range slots {
+		app.publishDelta(leaderboardDelta{EventId: eventId, Class: key.class, Moto: key.moto, Source: "results", Changes: changed[key]})
+	}
+}
+
+// publishDelta sends a delta to the event, class and moto topics, and the
+// part of it about each rider to that rider's topic.
+func (app *application) publishDelta(delta leaderboardDelta) {
+	app.live.Publish(eventTopic(delta.EventId), "leaderboard", delta)
+	app.live.Publish(classTopic(delta.EventId, delta.Class), "leaderboard", delta)
+	app.live.Publish(motoTopic(delta.EventId, delta.Class, delta.Moto), "leaderboard", delta)
+
+	for _, change := range delta.Changes {
+		if change.RiderId == 0 {
+			continue
+		}
+		own := delta
+		own.Changes = []leaderboardChange{change}
+		app.live.Publish(riderTopic(change.RiderId), "leaderboard", own)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/timing"
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+// liveKeepAlive is how often an idle live stream sends a comment so proxies
+// and load balancers don't close it.
+const liveKeepAlive = 15 * time.Second
+
+type crossingsRequest struct {
+	Class     string            `json:"class" binding:"required"`
+	GateDrop  *time.Time        `json:"gateDrop"`
+	Crossings []timing.Crossing `json:"crossings" binding:"required,dive"`
+}
+
+func eventTopic(eventId int) string {
+	return fmt.Sprintf("event:%d", eventId)
+}
+
+// motoTopic names one class's moto, as each class runs its own moto 1 and 2.
+func motoTopic(eventId int, class string, moto int) string {
+	return fmt.Sprintf("event:%d:class:%s:moto:%d", eventId, class, moto)
+}
+
+// IngestCrossings records transponder crossings for a moto
+// @Summary Ingest transponder crossings ** Auth Required **
+// @Description Accepts a batch of loop crossings for a moto, recomputes lap times and the running order, and broadcasts the result to live subscribers along with a "leaderboard" delta of the riders who moved. Crossings on the "finish" loop count laps. Retried batches are ignored.
+// @Tags live
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param moto path int true "Moto number"
+// @Param crossings body crossingsRequest true "Crossings"
+// @Success 200 {object} timing.Board
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event not in progress or provisional"
+// @Failure 500 {object} gin.H "Failed to retrieve the event"
+// @Router /api/v1/events/{id}/motos/{moto}/crossings [post]
+func (app *application) ingestCrossings(c *gin.Context) {
+	eventId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event Id."})
+		return
+	}
+
+	moto, err := strconv.Atoi(c.Param("moto"))
+	if err != nil || moto < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+		return
+	}
+
+	var request crossingsRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := app.models.Events.Get(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event."})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	if user.Id != event.OwnerId {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to send timing for an event you don't own."})
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	previous, _ := app.timing.Board(event.Id, request.Class, moto)
+	board := app.timing.Ingest(event.Id, request.Class, moto, request.GateDrop, request.Crossings)
+
+	app.live.Publish(eventTopic(event.Id), "standings", board)
+	app.live.Publish(classTopic(event.Id, board.Class), "standings", board)
+	app.live.Publish(motoTopic(event.Id, board.Class, moto), "standings", board)
+	app.publishTimingDelta(previous, board)
+
+	c.JSON(http.StatusOK, board)
+}
+
+// GetLiveTiming streams live running order as Server-Sent Events
+// @Summary Live running order (Server-Sent Events)
+// @Description Streams "standings" events for an event, one class of it or one class's moto, as crossings are ingested, and "leaderboard" deltas when timing or recorded results change the order. New clients first get the latest standings; clients reconnecting with Last-Event-ID get every update they missed that is still buffered.
+// @Tags live
+// @Produce text/event-stream
+// @Param id path int true "Event ID"
+// @Param class query string false "Only this class"
+// @Param moto query int false "Only this moto of the class, which is then required"
+// @Param Last-Event-ID header int false "Resume after this event id"
+// @Success 200 "Event stream"
+// @Failure 400 {object} gin.H "Invalid event ID or moto, or a moto without a class"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve the event"
+// @Router /api/v1/events/{id}/live [get]
+func (app *application) getLiveTiming(c *gin.Context) {
+	eventId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event Id."})
+		return
+	}
+
+	event, err := app.models.Events.Get(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event."})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return
+	}
+
+	topic := eventTopic(event.Id)
+	class := c.Query("class")
+	if class != "" {
+		topic = classTopic(event.Id, class)
+	}
+	if value := c.Query("moto"); value != "" {
+		moto, err := strconv.Atoi(value)
+		if err != nil || moto < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+			return
+		}
+		if class == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A moto needs a class."})
+			return
+		}
+		topic = motoTopic(event.Id, class, moto)
+	}
+
+	lastId := c.GetHeader("Last-Event-ID")
+	if lastId == "" {
+		lastId = c.Query("lastEventId")
+	}
+	resumeFrom, _ := strconv.ParseInt(lastId, 10, 64)
+
+	sub := app.live.Subscribe([]string{topic}, resumeFrom)
+	defer sub.Close()
+
+	// The server's write timeout is meant for ordinary requests, not streams.
+	http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(liveKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return false
+			}
+			c.Render(-1, sse.Event{
+				Id:    strconv.FormatInt(msg.Id, 10),
+				Event: msg.Event,
+				Data:  msg.Data,
+			})
+			return true
+		case <-keepAlive.C:
+			io.WriteString(w, ": keep-alive\n\n")
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
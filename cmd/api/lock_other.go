@@ -0,0 +1,15 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"time"
+)
+
+// lockFile would take the migration lock, but file locks aren't available
+// here, so migrating on start is refused rather than risk two processes
+// migrating at once.
+func lockFile(path string, timeout time.Duration) (func(), error) {
+	return nil, errors.New("MIGRATE_ON_START is not supported on this platform, run 'migrate up' instead")
+}
@@ -8,6 +8,8 @@ import (
 
 	"github.com/bcantrell1/pro-motocross-api/internal/database"
 	"github.com/bcantrell1/pro-motocross-api/internal/env"
+	"github.com/bcantrell1/pro-motocross-api/internal/storage"
+	"github.com/bcantrell1/pro-motocross-api/internal/timing"
 
 	_ "github.com/joho/godotenv/autoload"
 	_ "github.com/mattn/go-sqlite3"
@@ -25,13 +27,24 @@ import (
 // @security BearerAuth
 
 type application struct {
-	port      int
-	jwtSecret string
-	models    database.Models
+	port          int
+	jwtSecret     string
+	baseURL       string
+	models        database.Models
+	timing        *timing.Tracker
+	live          *timing.Hub
+	media         storage.Store
+	mediaMaxBytes int64
 }
 
+const dbPath = "./data.db"
+
 func main() {
-	db, err := sql.Open("sqlite3", "./data.db")
+	if err := checkSchema(dbPath, env.GetEnvBool("MIGRATE_ON_START", false)); err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		log.Fatal((err))
 	}
@@ -40,9 +53,14 @@ func main() {
 
 	models := database.NewModels(db)
 	app := &application{
-		port:      env.GetEnvInt("PORT", 8080),
-		jwtSecret: env.GetEnvString("JWT_SECRET", "my-super-secret"),
-		models:    models,
+		port:          env.GetEnvInt("PORT", 8080),
+		jwtSecret:     env.GetEnvString("JWT_SECRET", "my-super-secret"),
+		baseURL:       env.GetEnvString("BASE_URL", "http://localhost:8080"),
+		models:        models,
+		timing:        timing.NewTracker(),
+		live:          timing.NewHub(1000, 64),
+		media:         storage.NewLocal(env.GetEnvString("MEDIA_DIR", "./media")),
+		mediaMaxBytes: int64(env.GetEnvInt("MEDIA_MAX_BYTES", 10<<20)),
 	}
 
 	if err := app.serve(); err != nil {
@@ -0,0 +1,348 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// mediaExtensions are the image types accepted for upload, by the content
+// type sniffed from their bytes.
+var mediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// maxMediaPixels caps the decoded size of an upload, so a small file that
+// claims huge dimensions can't exhaust memory.
+const maxMediaPixels = 40_000_000
+
+// UploadRiderMedia uploads a photo of a rider
+// @Summary Upload rider media ** Auth Required **
+// @Description Uploads a headshot of a rider or a photo of their bike livery as a multipart form. The type is sniffed from the file itself; JPEG, PNG and GIF images are accepted, up to 10 MB unless configured otherwise. Small and medium JPEG thumbnails are made alongside the original.
+// @Tags riders
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path int true "Rider ID"
+// @Param file formData file true "Image"
+// @Param kind formData string true "headshot or bike"
+// @Param caption formData string false "Caption"
+// @Success 201 {object} database.Media
+// @Failure 400 {object} gin.H "Invalid rider ID or form"
+// @Failure 403 {object} gin.H "Not the rider's owner"
+// @Failure 404 {object} gin.H "Rider not found"
+// @Failure 413 {object} gin.H "Image too large"
+// @Failure 415 {object} gin.H "Not a JPEG, PNG or GIF image"
+// @Failure 422 {object} gin.H "Unreadable image"
+// @Failure 500 {object} gin.H "Failed to store the image"
+// @Router /api/v1/riders/{id}/media [post]
+func (app *application) uploadRiderMedia(c *gin.Context) {
+	rider, ok := app.ownedRider(c, "upload media for")
+	if !ok {
+		return
+	}
+
+	tooLarge := fmt.Sprintf("Images can be at most %d KB.", app.mediaMaxBytes>>10)
+
+	// Leave room for the rest of the form around the file.
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, app.mediaMaxBytes+1<<20)
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxBytes *http.MaxBytesError
+		if errors.As(err, &maxBytes) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Attach the image as the file field of a multipart form."})
+		return
+	}
+
+	defer file.Close()
+
+	kind := c.Request.FormValue("kind")
+	if kind != database.MediaHeadshot && kind != database.MediaBike {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Kind must be headshot or bike."})
+		return
+	}
+
+	if header.Size > app.mediaMaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, app.mediaMaxBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read the upload."})
+		return
+	}
+	if int64(len(data)) > app.mediaMaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": tooLarge})
+		return
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := mediaExtensions[contentType]
+	if !ok {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Upload a JPEG, PNG or GIF image."})
+		return
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The image could not be read."})
+		return
+	}
+	if config.Width*config.Height > maxMediaPixels {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("The image is %dx%d, which is too many pixels.", config.Width, config.Height)})
+		return
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "The image could not be read."})
+		return
+	}
+
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store the image."})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	media := database.Media{
+		RiderId:     rider.Id,
+		Kind:        kind,
+		Key:         fmt.Sprintf("riders/%d/%s%s", rider.Id, hex.EncodeToString(name), ext),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Width:       config.Width,
+		Height:      config.Height,
+		Caption:     c.Request.FormValue("caption"),
+		UploadedBy:  user.Id,
+	}
+
+	if err := app.storeMedia(c.Request.Context(), &media, data, img); err != nil {
+		app.deleteMediaBlobs(&media)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store the image."})
+		return
+	}
+
+	if err := app.models.Media.Insert(&media); err != nil {
+		app.deleteMediaBlobs(&media)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store the image."})
+		return
+	}
+
+	app.mediaURLs(&media)
+	c.JSON(http.StatusCreated, media)
+}
+
+// GetRiderMedia lists a rider's media
+// @Summary Get rider media
+// @Description Returns a rider's headshots and bike photos, newest first, with URLs of the original and its thumbnails.
+// @Tags riders
+// @Produce json
+// @Param id path int true "Rider ID"
+// @Param kind query string false "headshot or bike"
+// @Success 200 {array} database.Media
+// @Success 301 "Rider was merged into another; Location gives their new address"
+// @Failure 400 {object} gin.H "Invalid rider ID"
+// @Failure 404 {object} gin.H "Rider not found"
+// @Failure 500 {object} gin.H "Failed to retrieve media"
+// @Router /api/v1/riders/{id}/media [get]
+func (app *application) getRiderMedia(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+		return
+	}
+
+	rider, err := app.models.Riders.Get(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+		return
+	}
+	if rider == nil {
+		if app.redirectMergedRider(c, id) {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rider not found."})
+		return
+	}
+
+	media, err := app.models.Media.GetByRider(rider.Id, c.Query("kind"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve media."})
+		return
+	}
+
+	for _, item := range media {
+		app.mediaURLs(item)
+	}
+
+	c.JSON(http.StatusOK, media)
+}
+
+// DeleteRiderMedia deletes a photo of a rider
+// @Summary Delete rider media ** Auth Required **
+// @Description Deletes an image of a rider along with its thumbnails.
+// @Tags riders
+// @Param id path int true "Rider ID"
+// @Param mediaId path int true "Media ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} gin.H "Invalid rider or media ID"
+// @Failure 403 {object} gin.H "Not the rider's owner"
+// @Failure 404 {object} gin.H "Rider or media not found"
+// @Failure 500 {object} gin.H "Failed to delete the media"
+// @Router /api/v1/riders/{id}/media/{mediaId} [delete]
+func (app *application) deleteRiderMedia(c *gin.Context) {
+	rider, ok := app.ownedRider(c, "delete media of")
+	if !ok {
+		return
+	}
+
+	mediaId, err := strconv.Atoi(c.Param("mediaId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media Id."})
+		return
+	}
+
+	media, err := app.models.Media.Get(mediaId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve media."})
+		return
+	}
+	if media == nil || media.RiderId != rider.Id {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found."})
+		return
+	}
+
+	if err := app.models.Media.Delete(media.Id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the media."})
+		return
+	}
+
+	app.deleteMediaBlobs(media)
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetMediaFile serves a stored image
+// @Summary Get a media file
+// @Description Serves an uploaded image or thumbnail by the key in its URL. Keys are never reused, so responses can be cached indefinitely.
+// @Tags riders
+// @Produce image/jpeg,image/png,image/gif
+// @Param key path string true "Media key"
+// @Success 200 {file} file
+// @Failure 404 {object} gin.H "Media not found"
+// @Failure 500 {object} gin.H "Failed to read the media"
+// @Router /api/v1/media/{key} [get]
+func (app *application) getMediaFile(c *gin.Context) {
+	key := c.Param("key")[1:]
+
+	blob, err := app.media.Get(c.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Media not found."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read the media."})
+		return
+	}
+
+	defer blob.Close()
+
+	c.DataFromReader(http.StatusOK, -1, mime.TypeByExtension(path.Ext(key)), blob, map[string]string{
+		"Cache-Control": "public, max-age=31536000, immutable",
+	})
+}
+
+// ownedRider loads the rider named by the id path parameter, responding
+// with an error if there is none or the user doesn't own it.
+func (app *application) ownedRider(c *gin.Context, action string) (*database.Rider, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+		return nil, false
+	}
+
+	rider, err := app.models.Riders.Get(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+		return nil, false
+	}
+	if rider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rider not found."})
+		return nil, false
+	}
+
+	user := app.GetUserFromContext(c)
+	if user.Id != rider.OwnerId {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("You are not authorized to %s a rider you don't own.", action)})
+		return nil, false
+	}
+
+	return rider, true
+}
+
+// storeMedia puts the original image and its thumbnails in blob storage.
+func (app *application) storeMedia(ctx context.Context, media *database.Media, data []byte, img image.Image) error {
+	if err := app.media.Put(ctx, media.Key, bytes.NewReader(data)); err != nil {
+		return err
+	}
+
+	for _, size := range thumbnailSizes {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, thumbnail(img, size.edge), &jpeg.Options{Quality: 85}); err != nil {
+			return err
+		}
+		if err := app.media.Put(ctx, thumbnailKey(media.Key, size.name), &buf); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteMediaBlobs removes an image and its thumbnails from blob storage.
+// Failures are only logged, as the media is already gone from the rider.
+func (app *application) deleteMediaBlobs(media *database.Media) {
+	keys := []string{media.Key}
+	for _, size := range thumbnailSizes {
+		keys = append(keys, thumbnailKey(media.Key, size.name))
+	}
+
+	for _, key := range keys {
+		if err := app.media.Delete(context.Background(), key); err != nil {
+			log.Printf("media: deleting %s: %v", key, err)
+		}
+	}
+}
+
+func (app *application) mediaURLs(media *database.Media) {
+	media.URL = app.baseURL + "/api/v1/media/" + media.Key
+	media.Thumbnails = map[string]string{}
+	for _, size := range thumbnailSizes {
+		media.Thumbnails[size.name] = app.baseURL + "/api/v1/media/" + thumbnailKey(media.Key, size.name)
+	}
+}
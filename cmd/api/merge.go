@@ -0,0 +1,288 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/match"
+	"github.com/gin-gonic/gin"
+)
+
+// How two riders compare on a detail either may have left blank.
+const (
+	detailMatch   = "match"
+	detailDiffers = "differs"
+	detailUnknown = "unknown"
+)
+
+// minNameSimilarity is how alike two names must be before a pair is scored
+// at all. Riders with different names aren't duplicates however much else
+// they share.
+const minNameSimilarity = 0.75
+
+// defaultDuplicateThreshold is the lowest score listed unless the caller
+// asks otherwise. Two riders with the same name and number reach it even
+// when nothing else is known about them.
+const defaultDuplicateThreshold = 0.7
+
+// duplicateSignals are what a duplicate score is made of.
+type duplicateSignals struct {
+	Name        float64 `json:"name"`
+	Number      bool    `json:"number"`
+	DateOfBirth string  `json:"dateOfBirth"`
+	Nationality string  `json:"nationality"`
+}
+
+// riderDuplicate is a pair of riders who may be the same person. Rider is
+// the older of the two, the one usually kept when they are merged.
+type riderDuplicate struct {
+	Rider     *database.Rider  `json:"rider"`
+	Duplicate *database.Rider  `json:"duplicate"`
+	Score     float64          `json:"score"`
+	Signals   duplicateSignals `json:"signals"`
+}
+
+type riderMergeRequest struct {
+	DuplicateId int `json:"duplicateId" binding:"required"`
+}
+
+// riderMerge is the rider a duplicate was merged into and how many rows of
+// each table moved over.
+type riderMerge struct {
+	Rider    *database.Rider  `json:"rider"`
+	MergedId int              `json:"mergedId"`
+	Moved    map[string]int64 `json:"moved"`
+}
+
+// mergeConflict is a merge refused because the riders raced each other.
+type mergeConflict struct {
+	message string
+}
+
+func (e *mergeConflict) Error() string {
+	return e.message
+}
+
+// GetDuplicateRiders lists riders who may have been entered twice
+// @Summary Find duplicate riders ** Admin Required **
+// @Description Scores every pair of riders with similar names on how likely they are to be the same person. The name counts for 60% of the score, sharing a race number 15%, date of birth 15% and nationality 10%; a detail either rider left blank counts half. Pairs are listed best first.
+// @Tags riders
+// @Produce json
+// @Param threshold query number false "Lowest score to list, from 0 to 1" default(0.7)
+// @Success 200 {array} riderDuplicate
+// @Failure 400 {object} gin.H "Invalid threshold"
+// @Failure 403 {object} gin.H "Not an administrator"
+// @Failure 500 {object} gin.H "Failed to retrieve riders"
+// @Router /api/v1/riders/duplicates [get]
+func (app *application) getDuplicateRiders(c *gin.Context) {
+	threshold := defaultDuplicateThreshold
+	if value := c.Query("threshold"); value != "" {
+		var err error
+		threshold, err = strconv.ParseFloat(value, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The threshold must be a number from 0 to 1."})
+			return
+		}
+	}
+
+	riders, err := app.models.Riders.GetAll(0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve riders."})
+		return
+	}
+
+	sort.Slice(riders, func(i, j int) bool { return riders[i].Id < riders[j].Id })
+
+	duplicates := []riderDuplicate{}
+	for i, rider := range riders {
+		for _, other := range riders[i+1:] {
+			duplicate, ok := scoreDuplicate(rider, other)
+			if ok && duplicate.Score >= threshold {
+				duplicates = append(duplicates, duplicate)
+			}
+		}
+	}
+
+	sort.SliceStable(duplicates, func(i, j int) bool { return duplicates[i].Score > duplicates[j].Score })
+
+	c.JSON(http.StatusOK, duplicates)
+}
+
+// scoreDuplicate scores how likely two riders are to be the same person,
+// reporting false for names too far apart to be worth scoring.
+func scoreDuplicate(rider, other *database.Rider) (riderDuplicate, bool) {
+	signals := duplicateSignals{
+		Name:        math.Round(match.Similarity(rider.FirstName+" "+rider.LastName, other.FirstName+" "+other.LastName)*1000) / 1000,
+		Number:      rider.Number == other.Number,
+		DateOfBirth: compareDetail(birthDate(rider.DateOfBirth), birthDate(other.DateOfBirth)),
+		Nationality: compareDetail(rider.Nationality, other.Nationality),
+	}
+	if signals.Name < minNameSimilarity {
+		return riderDuplicate{}, false
+	}
+
+	score := 0.6 * signals.Name
+	if signals.Number {
+		score += 0.15
+	}
+	score += 0.15 * detailWeight(signals.DateOfBirth)
+	score += 0.1 * detailWeight(signals.Nationality)
+
+	return riderDuplicate{
+		Rider:     rider,
+		Duplicate: other,
+		Score:     math.Round(score*1000) / 1000,
+		Signals:   signals,
+	}, true
+}
+
+func compareDetail(a, b string) string {
+	a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	switch {
+	case a == "" || b == "":
+		return detailUnknown
+	case strings.EqualFold(a, b):
+		return detailMatch
+	}
+	return detailDiffers
+}
+
+// birthDate reduces a stored date of birth to its date, or blank for
+// riders without one, whose date reads back as the zero time.
+func birthDate(value string) string {
+	date := value[:min(len(value), len("2006-01-02"))]
+	if date == "0001-01-01" {
+		return ""
+	}
+	return date
+}
+
+func detailWeight(detail string) float64 {
+	switch detail {
+	case detailMatch:
+		return 1
+	case detailUnknown:
+		return 0.5
+	}
+	return 0
+}
+
+// MergeRider merges a duplicate rider into this one
+// @Summary Merge a duplicate rider ** Admin Required **
+// @Description Moves everything recorded against the duplicate (entries, results, overall results, laps, laps led, holeshots, qualifying times, penalties, protests, registered numbers and media) to this rider in one transaction, fills in details this rider is missing from the duplicate, and deletes the duplicate. Where both riders hold the same registration or event entry, this rider's is kept. The duplicate's Id redirects here afterwards. Riders who raced in the same moto can't be merged.
+// @Tags riders
+// @Accept json
+// @Produce json
+// @Param id path int true "ID of the rider to keep"
+// @Param merge body riderMergeRequest true "Duplicate to merge"
+// @Success 200 {object} riderMerge
+// @Failure 400 {object} gin.H "Invalid rider ID or request body"
+// @Failure 403 {object} gin.H "Not an administrator"
+// @Failure 404 {object} gin.H "Rider not found"
+// @Failure 409 {object} gin.H "The riders raced each other"
+// @Failure 500 {object} gin.H "Failed to merge the riders"
+// @Router /api/v1/riders/{id}/merge [post]
+func (app *application) mergeRider(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+		return
+	}
+
+	var request riderMergeRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.DuplicateId == id {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A rider can't be merged into themselves."})
+		return
+	}
+
+	for _, riderId := range []int{id, request.DuplicateId} {
+		rider, err := app.models.Riders.Get(riderId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+			return
+		}
+		if rider == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Rider %d not found.", riderId)})
+			return
+		}
+	}
+
+	user := app.GetUserFromContext(c)
+	merge := riderMerge{MergedId: request.DuplicateId}
+
+	err = app.models.Transaction(func(tx database.Models) error {
+		shared, err := tx.Riders.RacedTogether(request.DuplicateId, id)
+		if err != nil {
+			return err
+		}
+		if shared != nil {
+			return &mergeConflict{fmt.Sprintf("Both riders raced %s moto %d at event %d, so they can't be the same rider.", shared.Class, shared.Moto, shared.EventId)}
+		}
+
+		// The duplicate's motos are classified again once they are the
+		// canonical rider's, in case the two split an event between them.
+		results, err := tx.Results.GetByRider(request.DuplicateId)
+		if err != nil {
+			return err
+		}
+
+		if merge.Moved, err = tx.Riders.Merge(id, request.DuplicateId, user.Id); err != nil {
+			return err
+		}
+
+		type eventClass struct {
+			eventId int
+			class   string
+		}
+		done := map[eventClass]bool{}
+		for _, result := range results {
+			key := eventClass{result.EventId, result.Class}
+			if done[key] {
+				continue
+			}
+			done[key] = true
+			if err := tx.Overall.Recompute(result.EventId, result.Class); err != nil {
+				return err
+			}
+		}
+
+		merge.Rider, err = tx.Riders.Get(id)
+		return err
+	})
+	var conflict *mergeConflict
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge the riders."})
+		return
+	}
+
+	c.JSON(http.StatusOK, merge)
+}
+
+// redirectMergedRider sends requests for a rider who was merged away on to
+// the rider they were merged into, reporting whether it did.
+func (app *application) redirectMergedRider(c *gin.Context, id int) bool {
+	toId, err := app.models.Riders.Redirect(id)
+	if err != nil || toId == 0 {
+		return false
+	}
+
+	location := *c.Request.URL
+	location.Path = strings.Replace(location.Path, "/riders/"+strconv.Itoa(id), "/riders/"+strconv.Itoa(toId), 1)
+	c.Redirect(http.StatusMovedPermanently, location.String())
+	return true
+}
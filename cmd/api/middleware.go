@@ -1,15 +1,31 @@
 package main
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 )
 
 func (app *application) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			user, err := app.userFromAPIKey(apiKey)
+			if err != nil {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				c.Abort()
+				return
+			}
+
+			c.Set("user", user)
+
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
@@ -24,37 +40,69 @@ func (app *application) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(app.jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		user, err := app.userFromToken(tokenString)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 			c.Abort()
 			return
 		}
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		c.Set("user", user)
+
+		c.Next()
+	}
+}
+
+// AdminMiddleware lets through only users flagged as administrators. It
+// runs after AuthMiddleware.
+func (app *application) AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := app.GetUserFromContext(c)
+		if !user.IsAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only administrators can do that."})
 			c.Abort()
 			return
 		}
 
-		userId := claims["userId"].(float64)
+		c.Next()
+	}
+}
 
-		user, err := app.models.Users.Get(int(userId))
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized access"})
-			c.Abort()
-			return
+func (app *application) userFromToken(tokenString string) (*database.User, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, jwt.ErrSignatureInvalid
 		}
+		return []byte(app.jwtSecret), nil
+	})
 
-		c.Set("user", user)
+	if err != nil || !token.Valid {
+		return nil, errors.New("Invalid token")
+	}
 
-		c.Next()
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, errors.New("Invalid token")
 	}
+
+	userId, ok := claims["userId"].(float64)
+	if !ok {
+		return nil, errors.New("Invalid token")
+	}
+
+	user, err := app.models.Users.Get(int(userId))
+	if err != nil || user == nil {
+		return nil, errors.New("Unauthorized access")
+	}
+
+	return user, nil
+}
+
+func (app *application) userFromAPIKey(key string) (*database.User, error) {
+	user, err := app.models.APIKeys.GetUserByKey(key)
+	if err != nil || user == nil {
+		return nil, errors.New("Invalid API key")
+	}
+
+	return user, nil
 }
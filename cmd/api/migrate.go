@@ -0,0 +1,71 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/bcantrell1/pro-motocross-api/cmd/migrate/migrations"
+	"github.com/golang-migrate/migrate"
+)
+
+// checkSchema compares the database against the migrations embedded in the
+// binary. It refuses a schema that is dirty or newer than the binary, and
+// applies pending migrations when migrateOnStart is set.
+func checkSchema(dbPath string, migrateOnStart bool) error {
+	latest, err := migrations.Latest()
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+
+	m, err := migrations.New(db)
+	if err != nil {
+		db.Close()
+		return err
+	}
+
+	defer m.Close()
+
+	current, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("database schema is dirty at version %d, fix it with 'migrate force'", current)
+	}
+
+	if current > latest {
+		return fmt.Errorf("database schema version %d is newer than this binary supports (%d)", current, latest)
+	}
+
+	if current == latest {
+		return nil
+	}
+
+	if !migrateOnStart {
+		log.Printf("Database schema is at version %d but %d is available, run 'migrate up' or set MIGRATE_ON_START=true", current, latest)
+		return nil
+	}
+
+	unlock, err := lockFile(dbPath+".lock", 30*time.Second)
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
+		return err
+	}
+
+	log.Printf("Database schema migrated from version %d to %d", current, latest)
+
+	return nil
+}
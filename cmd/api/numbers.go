@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	reservedChampion = "champion"
+	reservedCareer   = "career"
+)
+
+type reservedNumber struct {
+	Number    int    `json:"number"`
+	RiderId   int    `json:"riderId"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+	Reason    string `json:"reason"`
+}
+
+type numberBoard struct {
+	Season    int                     `json:"season"`
+	Class     string                  `json:"class"`
+	Taken     []*database.RiderNumber `json:"taken"`
+	Reserved  []reservedNumber        `json:"reserved"`
+	Available []int                   `json:"available"`
+}
+
+// numberConflict is a number a rider can't have, with the reason why.
+type numberConflict struct {
+	message string
+}
+
+func (e *numberConflict) Error() string {
+	return e.message
+}
+
+// numberRegistry is who holds or has a claim on each number in a season
+// and class.
+type numberRegistry struct {
+	season   int
+	class    string
+	taken    map[int]*database.RiderNumber
+	reserved map[int]reservedNumber
+	champion *int
+}
+
+// GetNumbers shows which numbers are taken in a class
+// @Summary Get the number registry
+// @Description Returns the numbers taken in a season's class, those reserved and those still available. #1 is reserved for the defending champion, the rider on top of the class's rider standings the season before. A rider keeps a claim on the number they last ran in the class, until they register another or someone else is assigned it.
+// @Tags riders
+// @Produce json
+// @Param season query int false "Season, defaults to the latest"
+// @Param class query string true "Class"
+// @Success 200 {object} numberBoard
+// @Failure 400 {object} gin.H "Invalid season or missing class"
+// @Failure 500 {object} gin.H "Failed to retrieve numbers"
+// @Router /api/v1/numbers [get]
+func (app *application) getNumbers(c *gin.Context) {
+	season, ok := app.seasonParam(c, database.DefaultSeriesId)
+	if !ok {
+		return
+	}
+
+	class := c.Query("class")
+	if class == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give the class to show numbers for."})
+		return
+	}
+
+	registry, err := loadNumberRegistry(app.models, season, class)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve numbers."})
+		return
+	}
+
+	board := numberBoard{Season: season, Class: class, Taken: []*database.RiderNumber{}, Reserved: []reservedNumber{}, Available: []int{}}
+	for number := 1; number <= database.MaxRiderNumber; number++ {
+		if taken, ok := registry.taken[number]; ok {
+			board.Taken = append(board.Taken, taken)
+		} else if reserved, ok := registry.reserved[number]; ok {
+			board.Reserved = append(board.Reserved, reserved)
+		} else if number != database.ChampionNumber {
+			board.Available = append(board.Available, number)
+		}
+	}
+
+	c.JSON(http.StatusOK, board)
+}
+
+// registerNumber registers a rider's number in their class for a season,
+// or returns a numberConflict if it belongs to someone else. Riders without
+// a class aren't in any registry.
+func registerNumber(tx database.Models, season int, rider *database.Rider) error {
+	if rider.Class == "" {
+		return nil
+	}
+
+	registry, err := loadNumberRegistry(tx, season, rider.Class)
+	if err != nil {
+		return err
+	}
+
+	if err := registry.check(rider.Id, rider.Number); err != nil {
+		return err
+	}
+
+	return tx.Numbers.Assign(season, rider.Class, rider.Number, rider.Id)
+}
+
+func loadNumberRegistry(models database.Models, season int, class string) (*numberRegistry, error) {
+	registry := &numberRegistry{
+		season:   season,
+		class:    class,
+		taken:    map[int]*database.RiderNumber{},
+		reserved: map[int]reservedNumber{},
+	}
+
+	numbers, err := models.Numbers.GetBySeason(season, class)
+	if err != nil {
+		return nil, err
+	}
+
+	registered := map[int]int{}
+	for _, number := range numbers {
+		registry.taken[number.Number] = number
+		registered[number.RiderId] = number.Number
+	}
+
+	history, err := models.Numbers.GetHistory(season, class)
+	if err != nil {
+		return nil, err
+	}
+
+	// Later seasons overwrite earlier ones, leaving each number's last
+	// holder and each rider's last number other than #1.
+	holders := map[int]*database.RiderNumber{}
+	careerNumbers := map[int]int{}
+	for _, number := range history {
+		if number.Number == database.ChampionNumber {
+			continue
+		}
+		holders[number.Number] = number
+		careerNumbers[number.RiderId] = number.Number
+	}
+
+	for number, holder := range holders {
+		if careerNumbers[holder.RiderId] != number || registry.taken[number] != nil {
+			continue
+		}
+		if current, ok := registered[holder.RiderId]; ok && current != database.ChampionNumber {
+			continue
+		}
+		registry.reserved[number] = reservedNumber{Number: number, RiderId: holder.RiderId, FirstName: holder.FirstName, LastName: holder.LastName, Reason: reservedCareer}
+	}
+
+	// Only Pro Motocross hands out #1, to last season's champion.
+	series, err := models.Series.Get(database.DefaultSeriesId)
+	if err != nil {
+		return nil, err
+	}
+	if series != nil {
+		if registry.champion, err = seasonChampion(models, series, season-1, class); err != nil {
+			return nil, err
+		}
+	}
+
+	if registry.champion != nil && registry.taken[database.ChampionNumber] == nil {
+		champion, err := models.Riders.Get(*registry.champion)
+		if err != nil {
+			return nil, err
+		}
+		if champion != nil {
+			registry.reserved[database.ChampionNumber] = reservedNumber{
+				Number: database.ChampionNumber, RiderId: champion.Id, FirstName: champion.FirstName, LastName: champion.LastName, Reason: reservedChampion,
+			}
+		}
+	}
+
+	return registry, nil
+}
+
+// check returns a numberConflict if the rider can't have the number.
+func (r *numberRegistry) check(riderId, number int) error {
+	if number < 1 || number > database.MaxRiderNumber {
+		return &numberConflict{fmt.Sprintf("Numbers run from 1 to %d.", database.MaxRiderNumber)}
+	}
+
+	if taken, ok := r.taken[number]; ok && taken.RiderId == riderId {
+		return nil
+	}
+
+	if number == database.ChampionNumber && (r.champion == nil || *r.champion != riderId) {
+		return &numberConflict{fmt.Sprintf("#1 is reserved for the %d %s champion.", r.season-1, r.class)}
+	}
+
+	if taken, ok := r.taken[number]; ok {
+		return &numberConflict{fmt.Sprintf("#%d is taken in %d %s by %s %s.", number, r.season, r.class, taken.FirstName, taken.LastName)}
+	}
+
+	if reserved, ok := r.reserved[number]; ok && reserved.RiderId != riderId {
+		return &numberConflict{fmt.Sprintf("#%d is reserved in %d %s for %s %s, who ran it last.", number, r.season, r.class, reserved.FirstName, reserved.LastName)}
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOverall returns the overall classification of an event
+// @Summary Get event overall results
+// @Description Returns each class's overall classification, worked out from the moto results whenever they change. Riders with more motos come first, then the lowest combined moto finish, with ties going to the better finish in the last moto. Finishes lists each moto position, 0 where the rider has no result.
+// @Tags events
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param class query string false "Only this class"
+// @Success 200 {array} database.OverallResult
+// @Failure 400 {object} gin.H "Invalid event ID"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve overall results"
+// @Router /api/v1/events/{id}/overall [get]
+func (app *application) getOverall(c *gin.Context) {
+	event, ok := app.eventParam(c)
+	if !ok {
+		return
+	}
+
+	overall, err := app.models.Overall.GetByEvent(event.Id, c.Query("class"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve overall results."})
+		return
+	}
+
+	results, err := app.models.Results.GetByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve overall results."})
+		return
+	}
+
+	motos := map[string]int{}
+	finishes := map[motoSlot]map[int]int{}
+	for _, result := range results {
+		motos[result.Class] = max(motos[result.Class], result.Moto)
+		slot := motoSlot{result.Class, result.Moto}
+		if finishes[slot] == nil {
+			finishes[slot] = map[int]int{}
+		}
+		finishes[slot][result.RiderId] = result.Position
+	}
+
+	for _, result := range overall {
+		result.Finishes = make([]int, motos[result.Class])
+		for i := range result.Finishes {
+			result.Finishes[i] = finishes[motoSlot{result.Class, i + 1}][result.RiderId]
+		}
+	}
+
+	c.JSON(http.StatusOK, overall)
+}
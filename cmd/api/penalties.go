@@ -0,0 +1,583 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+type penaltyRequest struct {
+	RiderId   int    `json:"riderId" binding:"required"`
+	Class     string `json:"class" binding:"required"`
+	Type      string `json:"type" binding:"required,oneof=positions time points dq"`
+	Value     int    `json:"value" binding:"min=0"`
+	Reason    string `json:"reason" binding:"required,min=3"`
+	ProtestId *int   `json:"protestId"`
+}
+
+type officialRequest struct {
+	Class string `json:"class" binding:"required"`
+}
+
+// motoAmendment is a penalty change along with the moto results it led to.
+type motoAmendment struct {
+	Penalty  *database.Penalty  `json:"penalty"`
+	Revision *database.Revision `json:"revision"`
+}
+
+// GetPenalties lists the penalties of an event
+// @Summary Get event penalties
+// @Description Returns every penalty issued at an event in the order issued, rescinded ones included.
+// @Tags penalties
+// @Produce json
+// @Param id path int true "Event ID"
+// @Success 200 {array} database.Penalty
+// @Failure 400 {object} gin.H "Invalid event ID"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve penalties"
+// @Router /api/v1/events/{id}/penalties [get]
+func (app *application) getPenalties(c *gin.Context) {
+	event, ok := app.eventParam(c)
+	if !ok {
+		return
+	}
+
+	penalties, err := app.models.Penalties.GetByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve penalties."})
+		return
+	}
+
+	c.JSON(http.StatusOK, penalties)
+}
+
+// CreatePenalty penalises a rider's moto result
+// @Summary Issue a penalty ** Auth Required **
+// @Description Attaches a penalty to a rider's moto result and reclassifies the moto. Types are positions (drop value places), time (add value milliseconds to the rider's moto time, which needs lap times), points (deduct value points, which can take a moto below zero) and dq. Penalties coming from a protest must reference an upheld protest about the same moto. Every reclassification is kept as a result revision, and changes are broadcast as "leaderboard" deltas.
+// @Tags penalties
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param moto path int true "Moto number"
+// @Param penalty body penaltyRequest true "Penalty"
+// @Success 201 {object} motoAmendment
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event not in progress or provisional"
+// @Failure 422 {object} gin.H "No result to penalise or protest not upheld"
+// @Failure 500 {object} gin.H "Failed to issue the penalty"
+// @Router /api/v1/events/{id}/motos/{moto}/penalties [post]
+func (app *application) createPenalty(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "issue penalties in")
+	if !ok {
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	moto, err := strconv.Atoi(c.Param("moto"))
+	if err != nil || moto < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+		return
+	}
+
+	var request penaltyRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Type != database.PenaltyDQ && request.Value < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A %s penalty needs a value above zero.", request.Type)})
+		return
+	}
+
+	result, err := app.models.Results.GetByEventRiderMoto(event.Id, request.RiderId, request.Class, moto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve results."})
+		return
+	}
+	if result == nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Rider %d has no result in %s moto %d.", request.RiderId, request.Class, moto)})
+		return
+	}
+
+	if request.Type == database.PenaltyTime {
+		laps, err := app.models.Laps.GetByMoto(event.Id, request.Class, moto)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve lap times."})
+			return
+		}
+		if _, timed := motoTimes(laps)[request.RiderId]; !timed {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Time penalties need the rider's lap times for the moto."})
+			return
+		}
+	}
+
+	if request.ProtestId != nil {
+		protest, err := app.models.Protests.Get(*request.ProtestId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve protest."})
+			return
+		}
+		if protest == nil || protest.EventId != event.Id || protest.Class != request.Class || protest.Moto != moto {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Protest %d is not about this moto.", *request.ProtestId)})
+			return
+		}
+		if protest.Status != database.ProtestUpheld {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Protest %d has not been upheld.", protest.Id)})
+			return
+		}
+	}
+
+	user := app.GetUserFromContext(c)
+
+	penalty := database.Penalty{
+		EventId:   event.Id,
+		Class:     request.Class,
+		Moto:      moto,
+		RiderId:   request.RiderId,
+		Type:      request.Type,
+		Value:     request.Value,
+		Reason:    request.Reason,
+		IssuedBy:  user.Id,
+		ProtestId: request.ProtestId,
+	}
+	if penalty.Type == database.PenaltyDQ {
+		penalty.Value = 0
+	}
+
+	before, err := app.models.Results.GetByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve results."})
+		return
+	}
+
+	amendment := motoAmendment{Penalty: &penalty}
+	err = app.models.Transaction(func(tx database.Models) error {
+		if err := tx.Penalties.Insert(&penalty); err != nil {
+			return err
+		}
+		amendment.Revision, err = app.reclassifyMoto(tx, event.Id, penalty.Class, moto, fmt.Sprintf("Penalty %d: %s", penalty.Id, penalty.Reason), user.Id)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to issue the penalty."})
+		return
+	}
+
+	app.publishAmendment(event.Id, before)
+
+	c.JSON(http.StatusCreated, amendment)
+}
+
+// RescindPenalty withdraws a penalty
+// @Summary Rescind a penalty ** Auth Required **
+// @Description Withdraws a penalty and reclassifies its moto without it. The penalty stays on record as rescinded.
+// @Tags penalties
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param penaltyId path int true "Penalty ID"
+// @Success 200 {object} motoAmendment
+// @Failure 400 {object} gin.H "Invalid penalty ID"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Penalty not found"
+// @Failure 409 {object} gin.H "Penalty already rescinded, or event not in progress or provisional"
+// @Failure 500 {object} gin.H "Failed to rescind the penalty"
+// @Router /api/v1/events/{id}/penalties/{penaltyId} [delete]
+func (app *application) rescindPenalty(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "rescind penalties in")
+	if !ok {
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	penaltyId, err := strconv.Atoi(c.Param("penaltyId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid penalty Id."})
+		return
+	}
+
+	penalty, err := app.models.Penalties.Get(penaltyId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve penalty."})
+		return
+	}
+	if penalty == nil || penalty.EventId != event.Id {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Penalty not found."})
+		return
+	}
+	if penalty.RescindedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Penalty has already been rescinded."})
+		return
+	}
+
+	before, err := app.models.Results.GetByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve results."})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	amendment := motoAmendment{Penalty: penalty}
+	err = app.models.Transaction(func(tx database.Models) error {
+		if err := tx.Penalties.Rescind(penalty, user.Id); err != nil {
+			return err
+		}
+		amendment.Revision, err = app.reclassifyMoto(tx, event.Id, penalty.Class, penalty.Moto, fmt.Sprintf("Penalty %d rescinded", penalty.Id), user.Id)
+		return err
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rescind the penalty."})
+		return
+	}
+
+	app.publishAmendment(event.Id, before)
+
+	c.JSON(http.StatusOK, amendment)
+}
+
+// GetMotoRevisions returns the result history of a moto
+// @Summary Get moto result history
+// @Description Returns every revision of a class's moto results, oldest first. The first revision is the order they finished in; later ones follow penalties and when results were declared official. Revisions after the official one are amendments.
+// @Tags penalties
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param moto path int true "Moto number"
+// @Param class query string true "Class"
+// @Success 200 {array} database.Revision
+// @Failure 400 {object} gin.H "Invalid moto or class"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve revisions"
+// @Router /api/v1/events/{id}/motos/{moto}/revisions [get]
+func (app *application) getMotoRevisions(c *gin.Context) {
+	event, ok := app.eventParam(c)
+	if !ok {
+		return
+	}
+
+	moto, err := strconv.Atoi(c.Param("moto"))
+	if err != nil || moto < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+		return
+	}
+
+	class := c.Query("class")
+	if class == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A class is required."})
+		return
+	}
+
+	revisions, err := app.models.Penalties.GetRevisions(event.Id, class, moto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve revisions."})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// DeclareOfficial makes a moto's results official
+// @Summary Declare moto results official ** Auth Required **
+// @Description Records the current results of a class's moto as official. Penalties issued or rescinded afterwards produce amended revisions.
+// @Tags penalties
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param moto path int true "Moto number"
+// @Param official body officialRequest true "Class"
+// @Success 201 {object} database.Revision
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Already official, or event not in progress or provisional"
+// @Failure 422 {object} gin.H "No results"
+// @Failure 500 {object} gin.H "Failed to declare results official"
+// @Router /api/v1/events/{id}/motos/{moto}/official [post]
+func (app *application) declareOfficial(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "declare results official for")
+	if !ok {
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	moto, err := strconv.Atoi(c.Param("moto"))
+	if err != nil || moto < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid moto."})
+		return
+	}
+
+	var request officialRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := app.models.Results.GetByMoto(event.Id, request.Class, moto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve results."})
+		return
+	}
+	if len(results) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("There are no %s moto %d results to declare official.", request.Class, moto)})
+		return
+	}
+
+	revisions, err := app.models.Penalties.GetRevisions(event.Id, request.Class, moto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve revisions."})
+		return
+	}
+	if n := len(revisions); n > 0 && revisions[n-1].State == database.RevisionOfficial {
+		c.JSON(http.StatusConflict, gin.H{"error": "These results are already official."})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+
+	revision := database.Revision{
+		EventId:   event.Id,
+		Class:     request.Class,
+		Moto:      moto,
+		State:     database.RevisionOfficial,
+		Reason:    "Declared official",
+		Results:   results,
+		CreatedBy: &user.Id,
+	}
+	if err := app.models.Penalties.InsertRevision(&revision); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to declare results official."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, revision)
+}
+
+// publishAmendment broadcasts what a reclassification changed. The change
+// is already committed, so failures are only logged.
+func (app *application) publishAmendment(eventId int, before []*database.Result) {
+	after, err := app.models.Results.GetByEvent(eventId)
+	if err != nil {
+		log.Printf("penalties: reloading results of event %d: %v", eventId, err)
+		return
+	}
+	app.publishResultDeltas(eventId, before, after)
+}
+
+// reclassifyMoto works out a moto's results again from how the riders
+// finished and the penalties that stand, along with the overall results,
+// and records them as a new revision.
+// The first time a moto is reclassified its results as they finished are
+// kept as the first revision.
+func (app *application) reclassifyMoto(tx database.Models, eventId int, class string, moto int, reason string, userId int) (*database.Revision, error) {
+	series, err := tx.Series.GetByEvent(eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := tx.Results.GetByMoto(eventId, class, moto)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, err := tx.Penalties.GetRevisions(eventId, class, moto)
+	if err != nil {
+		return nil, err
+	}
+
+	state := database.RevisionProvisional
+	if n := len(revisions); n == 0 {
+		finished := database.Revision{EventId: eventId, Class: class, Moto: moto, State: state, Reason: "As finished", Results: results}
+		if err := tx.Penalties.InsertRevision(&finished); err != nil {
+			return nil, err
+		}
+	} else if revisions[n-1].State != database.RevisionProvisional {
+		state = database.RevisionAmended
+	}
+
+	penalties, err := tx.Penalties.GetActiveByMoto(eventId, class, moto)
+	if err != nil {
+		return nil, err
+	}
+
+	laps, err := tx.Laps.GetByMoto(eventId, class, moto)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range classifyMoto(series, results, penalties, motoTimes(laps)) {
+		if err := tx.Results.Update(result); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Overall.Recompute(eventId, class); err != nil {
+		return nil, err
+	}
+
+	revision := database.Revision{EventId: eventId, Class: class, Moto: moto, State: state, Reason: reason, Results: results, CreatedBy: &userId}
+	if err := tx.Penalties.InsertRevision(&revision); err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// motoTime is how many laps a rider completed in a moto and how long they
+// took.
+type motoTime struct {
+	laps    int
+	totalMs int64
+}
+
+func motoTimes(laps []*database.Lap) map[int]motoTime {
+	times := map[int]motoTime{}
+	for _, lap := range laps {
+		t := times[lap.RiderId]
+		t.laps++
+		t.totalMs += lap.LapTimeMs
+		times[lap.RiderId] = t
+	}
+	return times
+}
+
+var statusOrder = map[string]int{
+	database.ResultFinished: 0,
+	database.ResultDNF:      1,
+	database.ResultDNS:      2,
+	database.ResultDQ:       3,
+}
+
+// classifyMoto orders a moto's results from how the riders finished and
+// applies the penalties: disqualifications first, then time penalties,
+// which move a rider back behind everyone on the same lap who is now
+// quicker, then position drops, and finally points deductions. Riders who
+// did not finish follow the finishers. Points are paid on the series' table.
+// It returns the results that changed.
+func classifyMoto(series *database.Series, results []*database.Result, penalties []*database.Penalty, times map[int]motoTime) []*database.Result {
+	type classified struct {
+		position int
+		points   int
+		status   string
+	}
+
+	previous := map[int]classified{}
+	for _, result := range results {
+		previous[result.Id] = classified{result.Position, result.Points, result.Status}
+	}
+
+	disqualified := map[int]bool{}
+	timeAdded := map[int]int64{}
+	drops := map[int]int{}
+	deductions := map[int]int{}
+	var timed, dropped []int
+
+	for _, penalty := range penalties {
+		switch penalty.Type {
+		case database.PenaltyDQ:
+			disqualified[penalty.RiderId] = true
+		case database.PenaltyTime:
+			if _, ok := timeAdded[penalty.RiderId]; !ok {
+				timed = append(timed, penalty.RiderId)
+			}
+			timeAdded[penalty.RiderId] += int64(penalty.Value)
+		case database.PenaltyPositions:
+			if _, ok := drops[penalty.RiderId]; !ok {
+				dropped = append(dropped, penalty.RiderId)
+			}
+			drops[penalty.RiderId] += penalty.Value
+		case database.PenaltyPoints:
+			deductions[penalty.RiderId] += penalty.Value
+		}
+	}
+
+	for _, result := range results {
+		result.Status = result.FinishStatus
+		if disqualified[result.RiderId] {
+			result.Status = database.ResultDQ
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if statusOrder[a.Status] != statusOrder[b.Status] {
+			return statusOrder[a.Status] < statusOrder[b.Status]
+		}
+		return a.FinishPosition < b.FinishPosition
+	})
+
+	finishers := 0
+	for finishers < len(results) && results[finishers].Status == database.ResultFinished {
+		finishers++
+	}
+
+	index := func(riderId int) int {
+		for i := 0; i < finishers; i++ {
+			if results[i].RiderId == riderId {
+				return i
+			}
+		}
+		return -1
+	}
+
+	adjusted := func(riderId int) int64 {
+		return times[riderId].totalMs + timeAdded[riderId]
+	}
+
+	for _, riderId := range timed {
+		i := index(riderId)
+		if i < 0 {
+			continue
+		}
+		own := times[riderId]
+		for i+1 < finishers {
+			next, ok := times[results[i+1].RiderId]
+			if !ok || next.laps != own.laps || adjusted(results[i+1].RiderId) >= adjusted(riderId) {
+				break
+			}
+			results[i], results[i+1] = results[i+1], results[i]
+			i++
+		}
+	}
+
+	for _, riderId := range dropped {
+		i := index(riderId)
+		if i < 0 {
+			continue
+		}
+		for n := drops[riderId]; n > 0 && i+1 < finishers; n-- {
+			results[i], results[i+1] = results[i+1], results[i]
+			i++
+		}
+	}
+
+	changed := []*database.Result{}
+	for i, result := range results {
+		result.Position = i + 1
+		result.Points = 0
+		if result.Status == database.ResultFinished {
+			result.Points = series.PointsFor(result.Position)
+		}
+		result.Points -= deductions[result.RiderId]
+
+		if previous[result.Id] != (classified{result.Position, result.Points, result.Status}) {
+			changed = append(changed, result)
+		}
+	}
+
+	return changed
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+type protestRequest struct {
+	Class   string `json:"class" binding:"required"`
+	Moto    int    `json:"moto" binding:"required,min=1"`
+	RiderId *int   `json:"riderId"`
+	Reason  string `json:"reason" binding:"required,min=10"`
+}
+
+type protestDecision struct {
+	Status   string `json:"status" binding:"required,oneof=under_review upheld denied"`
+	Decision string `json:"decision"`
+}
+
+// FileProtest protests a moto result
+// @Summary File a protest ** Auth Required **
+// @Description Files a protest against a class's moto results, or against one rider's result when riderId is given. Protests start out filed and are decided by the event owner. Protests can only be filed while the event is in progress or provisional.
+// @Tags penalties
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param protest body protestRequest true "Protest"
+// @Success 201 {object} database.Protest
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event not in progress or provisional"
+// @Failure 422 {object} gin.H "No result to protest"
+// @Failure 500 {object} gin.H "Failed to file the protest"
+// @Router /api/v1/events/{id}/protests [post]
+func (app *application) fileProtest(c *gin.Context) {
+	event, ok := app.eventParam(c)
+	if !ok {
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	var request protestRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := app.models.Results.GetByMoto(event.Id, request.Class, request.Moto)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve results."})
+		return
+	}
+	if len(results) == 0 {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("There are no %s moto %d results to protest.", request.Class, request.Moto)})
+		return
+	}
+
+	if request.RiderId != nil {
+		found := false
+		for _, result := range results {
+			found = found || result.RiderId == *request.RiderId
+		}
+		if !found {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Rider %d has no result in %s moto %d.", *request.RiderId, request.Class, request.Moto)})
+			return
+		}
+	}
+
+	user := app.GetUserFromContext(c)
+
+	protest := database.Protest{
+		EventId: event.Id,
+		Class:   request.Class,
+		Moto:    request.Moto,
+		RiderId: request.RiderId,
+		FiledBy: user.Id,
+		Reason:  request.Reason,
+	}
+	if err := app.models.Protests.Insert(&protest); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to file the protest."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, protest)
+}
+
+// GetProtests lists the protests of an event
+// @Summary Get event protests
+// @Description Returns the protests filed at an event, oldest first, optionally only those with one status.
+// @Tags penalties
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param status query string false "filed, under_review, upheld or denied"
+// @Success 200 {array} database.Protest
+// @Failure 400 {object} gin.H "Invalid event ID"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve protests"
+// @Router /api/v1/events/{id}/protests [get]
+func (app *application) getProtests(c *gin.Context) {
+	event, ok := app.eventParam(c)
+	if !ok {
+		return
+	}
+
+	protests, err := app.models.Protests.GetByEvent(event.Id, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve protests."})
+		return
+	}
+
+	c.JSON(http.StatusOK, protests)
+}
+
+// DecideProtest moves a protest through review
+// @Summary Review or decide a protest ** Auth Required **
+// @Description Moves a filed protest under review, or upholds or denies it with the reasons given in decision. Upheld and denied protests are closed. Upholding a protest does not change results by itself; issue a penalty referencing the protest to do that. Protests can only be decided while the event is in progress or provisional.
+// @Tags penalties
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param protestId path int true "Protest ID"
+// @Param decision body protestDecision true "New status"
+// @Success 200 {object} database.Protest
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Protest not found"
+// @Failure 409 {object} gin.H "Event not in progress or provisional, or protest can't move to that status"
+// @Failure 500 {object} gin.H "Failed to update the protest"
+// @Router /api/v1/events/{id}/protests/{protestId} [put]
+func (app *application) decideProtest(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "decide protests for")
+	if !ok {
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	protestId, err := strconv.Atoi(c.Param("protestId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid protest Id."})
+		return
+	}
+
+	var request protestDecision
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Status != database.ProtestUnderReview && request.Decision == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Give the reasons for the decision."})
+		return
+	}
+
+	protest, err := app.models.Protests.Get(protestId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve protest."})
+		return
+	}
+	if protest == nil || protest.EventId != event.Id {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Protest not found."})
+		return
+	}
+
+	if !database.ProtestCanMove(protest.Status, request.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("A protest that is %s can't be moved to %s.", protest.Status, request.Status)})
+		return
+	}
+
+	protest.Status = request.Status
+	protest.Decision = request.Decision
+	if err := app.models.Protests.UpdateStatus(protest); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the protest."})
+		return
+	}
+
+	c.JSON(http.StatusOK, protest)
+}
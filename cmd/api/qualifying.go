@@ -0,0 +1,446 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+type qualifyingTimesRequest struct {
+	Times []qualifyingTimeInput `json:"times" binding:"required,dive"`
+}
+
+type qualifyingTimeInput struct {
+	RiderId   int   `json:"riderId" binding:"required"`
+	BestLapMs int64 `json:"bestLapMs" binding:"required,min=1"`
+}
+
+type qualifyingSessionTimes struct {
+	database.QualifyingSession
+	Times []*database.QualifyingTime `json:"times"`
+}
+
+// qualifyingRanking is a rider's place in the combined qualifying order,
+// which takes each rider's best lap from any session. Session is the number
+// of the session that lap was set in.
+type qualifyingRanking struct {
+	Position    int    `json:"position"`
+	RiderId     int    `json:"riderId"`
+	RiderNumber int    `json:"riderNumber"`
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	BestLapMs   int64  `json:"bestLapMs"`
+	Session     int    `json:"session"`
+	GapMs       int64  `json:"gapMs"`
+	Qualified   bool   `json:"qualified"`
+}
+
+// gatePick is a rider's turn to pick a starting gate, first pick first.
+type gatePick struct {
+	Pick        int `json:"pick"`
+	RiderId     int `json:"riderId"`
+	RiderNumber int `json:"riderNumber"`
+}
+
+// classQualifying is everything qualifying decides for one class. Moto 1
+// gate pick follows the combined order; Moto 2 follows the Moto 1 finish
+// and stays empty until Moto 1 results are recorded.
+type classQualifying struct {
+	Class     string                   `json:"class"`
+	Cut       int                      `json:"cut"`
+	Sessions  []qualifyingSessionTimes `json:"sessions"`
+	Combined  []qualifyingRanking      `json:"combined"`
+	GatePick1 []gatePick               `json:"gatePickMoto1"`
+	GatePick2 []gatePick               `json:"gatePickMoto2"`
+}
+
+// GetQualifying returns qualifying results and gate-pick order for an event
+// @Summary Qualifying and gate pick
+// @Description Returns each class's qualifying sessions, the combined ranking by best lap across sessions, which riders made the top-40 cut, and the gate-pick order for both motos.
+// @Tags qualifying
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param class query string false "Only this class, e.g. 450"
+// @Success 200 {array} classQualifying
+// @Failure 400 {object} gin.H "Invalid event ID"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 500 {object} gin.H "Failed to retrieve qualifying"
+// @Router /api/v1/events/{id}/qualifying [get]
+func (app *application) getQualifying(c *gin.Context) {
+	eventId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event Id."})
+		return
+	}
+
+	event, err := app.models.Events.Get(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event."})
+		return
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return
+	}
+
+	sessions, err := app.models.Qualifying.GetSessionsByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve qualifying."})
+		return
+	}
+
+	times, err := app.models.Qualifying.GetTimesByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve qualifying."})
+		return
+	}
+
+	results, err := app.models.Results.GetByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve results."})
+		return
+	}
+
+	classes := []classQualifying{}
+	for _, class := range qualifyingClasses(sessions) {
+		if filter := c.Query("class"); filter != "" && filter != class {
+			continue
+		}
+		classes = append(classes, rankQualifying(class, sessions, times, results))
+	}
+
+	c.JSON(http.StatusOK, classes)
+}
+
+// CreateQualifyingSession adds a qualifying session to an event
+// @Summary Add a qualifying session ** Auth Required **
+// @Description Adds a numbered qualifying session for a class the series runs, e.g. session 1 and 2 of the 450 class. Cancelled and official events take no new sessions.
+// @Tags qualifying
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param session body database.QualifyingSession true "Session"
+// @Success 201 {object} database.QualifyingSession
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event not found"
+// @Failure 409 {object} gin.H "Event cancelled or official, or session already exists"
+// @Failure 500 {object} gin.H "Failed to create the session"
+// @Router /api/v1/events/{id}/qualifying [post]
+func (app *application) createQualifyingSession(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "add qualifying to")
+	if !ok {
+		return
+	}
+
+	if event.Status == database.EventCancelled || event.Status == database.EventOfficial {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Qualifying can't be added to an event that is %s.", event.Status)})
+		return
+	}
+
+	var session database.QualifyingSession
+	if err := c.ShouldBindJSON(&session); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series, ok := app.eventSeries(c, event)
+	if !ok {
+		return
+	}
+	if !series.HasClass(session.Class) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s doesn't run a %s class.", series.Name, session.Class)})
+		return
+	}
+
+	session.EventId = event.Id
+	if session.Name == "" {
+		session.Name = fmt.Sprintf("%s Qualifying %d", session.Class, session.Number)
+	}
+
+	existing, err := app.models.Qualifying.GetSessionByNumber(event.Id, session.Class, session.Number)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the session."})
+		return
+	}
+	if existing != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "That session already exists."})
+		return
+	}
+
+	if err := app.models.Qualifying.InsertSession(&session); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the session."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+// RecordQualifyingTimes records best laps for a qualifying session
+// @Summary Record qualifying times ** Auth Required **
+// @Description Records each rider's best lap in a session. Sending a rider again replaces their time. Riders must be entered in the event.
+// @Tags qualifying
+// @Accept json
+// @Produce json
+// @Param id path int true "Event ID"
+// @Param sessionId path int true "Session ID"
+// @Param times body qualifyingTimesRequest true "Best laps"
+// @Success 200 {object} qualifyingSessionTimes
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not the event owner"
+// @Failure 404 {object} gin.H "Event or session not found"
+// @Failure 409 {object} gin.H "Event not in progress or provisional"
+// @Failure 422 {object} gin.H "Rider not entered in the event"
+// @Failure 500 {object} gin.H "Failed to record times"
+// @Router /api/v1/events/{id}/qualifying/{sessionId}/times [post]
+func (app *application) recordQualifyingTimes(c *gin.Context) {
+	event, ok := app.ownedEvent(c, "record qualifying for")
+	if !ok {
+		return
+	}
+
+	if !takesResults(c, event) {
+		return
+	}
+
+	sessionId, err := strconv.Atoi(c.Param("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session Id."})
+		return
+	}
+
+	session, err := app.models.Qualifying.GetSession(sessionId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the session."})
+		return
+	}
+	if session == nil || session.EventId != event.Id {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found."})
+		return
+	}
+
+	var request qualifyingTimesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	errNotEntered := errors.New("not entered")
+	var missing int
+
+	err = app.models.Transaction(func(tx database.Models) error {
+		for _, input := range request.Times {
+			attendee, err := tx.Attendees.GetByEventAndAttendee(event.Id, input.RiderId)
+			if err != nil {
+				return err
+			}
+			if attendee == nil || !attendee.Active() {
+				missing = input.RiderId
+				return errNotEntered
+			}
+
+			if err := tx.Qualifying.SaveTime(&database.QualifyingTime{SessionId: session.Id, RiderId: input.RiderId, BestLapMs: input.BestLapMs}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == errNotEntered {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Rider %d is not entered in this event.", missing)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record times."})
+		return
+	}
+
+	times, err := app.models.Qualifying.GetTimesByEvent(event.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve qualifying."})
+		return
+	}
+
+	response := qualifyingSessionTimes{QualifyingSession: *session, Times: []*database.QualifyingTime{}}
+	for _, t := range times {
+		if t.SessionId == session.Id {
+			response.Times = append(response.Times, t)
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ownedEvent loads the event in the id parameter and checks the current user
+// owns it, responding with the error itself when not.
+func (app *application) ownedEvent(c *gin.Context, action string) (*database.Event, bool) {
+	event, ok := app.eventParam(c)
+	if !ok {
+		return nil, false
+	}
+
+	user := app.GetUserFromContext(c)
+	if user.Id != event.OwnerId {
+		c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("You are not authorized to %s an event you don't own.", action)})
+		return nil, false
+	}
+
+	return event, true
+}
+
+// takesResults responds with a conflict unless the event is running or has
+// provisional results, the only times race data can be recorded for it.
+func takesResults(c *gin.Context, event *database.Event) bool {
+	if !database.EventTakesResults(event.Status) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Results can only be posted while the event is in progress or provisional."})
+		return false
+	}
+	return true
+}
+
+// eventParam loads the event named by the id path parameter, responding with
+// an error if there is none.
+func (app *application) eventParam(c *gin.Context) (*database.Event, bool) {
+	eventId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event Id."})
+		return nil, false
+	}
+
+	event, err := app.models.Events.Get(eventId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve event."})
+		return nil, false
+	}
+	if event == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found."})
+		return nil, false
+	}
+
+	return event, true
+}
+
+func qualifyingClasses(sessions []*database.QualifyingSession) []string {
+	var classes []string
+	for _, session := range sessions {
+		if len(classes) == 0 || classes[len(classes)-1] != session.Class {
+			classes = append(classes, session.Class)
+		}
+	}
+	return classes
+}
+
+// rankQualifying combines a class's sessions into one order by best lap.
+// Ties go to the rider with the quicker second-best lap, then the lower
+// number.
+func rankQualifying(class string, sessions []*database.QualifyingSession, times []*database.QualifyingTime, results []*database.Result) classQualifying {
+	qualifying := classQualifying{
+		Class:     class,
+		Cut:       database.QualifyingCut,
+		Sessions:  []qualifyingSessionTimes{},
+		Combined:  []qualifyingRanking{},
+		GatePick1: []gatePick{},
+		GatePick2: []gatePick{},
+	}
+
+	sessionNumbers := map[int]int{}
+	for _, session := range sessions {
+		if session.Class != class {
+			continue
+		}
+		sessionNumbers[session.Id] = session.Number
+
+		entry := qualifyingSessionTimes{QualifyingSession: *session, Times: []*database.QualifyingTime{}}
+		for _, t := range times {
+			if t.SessionId == session.Id {
+				entry.Times = append(entry.Times, t)
+			}
+		}
+		qualifying.Sessions = append(qualifying.Sessions, entry)
+	}
+
+	byRider := map[int][]*database.QualifyingTime{}
+	for _, t := range times {
+		if _, ok := sessionNumbers[t.SessionId]; ok {
+			byRider[t.RiderId] = append(byRider[t.RiderId], t)
+		}
+	}
+
+	for _, riderTimes := range byRider {
+		sort.Slice(riderTimes, func(i, j int) bool { return riderTimes[i].BestLapMs < riderTimes[j].BestLapMs })
+		best := riderTimes[0]
+		qualifying.Combined = append(qualifying.Combined, qualifyingRanking{
+			RiderId:     best.RiderId,
+			RiderNumber: best.RiderNumber,
+			FirstName:   best.FirstName,
+			LastName:    best.LastName,
+			BestLapMs:   best.BestLapMs,
+			Session:     sessionNumbers[best.SessionId],
+		})
+	}
+
+	secondBest := func(riderId int) int64 {
+		if riderTimes := byRider[riderId]; len(riderTimes) > 1 {
+			return riderTimes[1].BestLapMs
+		}
+		return 1<<63 - 1
+	}
+
+	sort.Slice(qualifying.Combined, func(i, j int) bool {
+		a, b := qualifying.Combined[i], qualifying.Combined[j]
+		if a.BestLapMs != b.BestLapMs {
+			return a.BestLapMs < b.BestLapMs
+		}
+		if sa, sb := secondBest(a.RiderId), secondBest(b.RiderId); sa != sb {
+			return sa < sb
+		}
+		return a.RiderNumber < b.RiderNumber
+	})
+
+	qualified := map[int]bool{}
+	for i := range qualifying.Combined {
+		ranking := &qualifying.Combined[i]
+		ranking.Position = i + 1
+		ranking.GapMs = ranking.BestLapMs - qualifying.Combined[0].BestLapMs
+		ranking.Qualified = ranking.Position <= database.QualifyingCut
+
+		if ranking.Qualified {
+			qualified[ranking.RiderId] = true
+			qualifying.GatePick1 = append(qualifying.GatePick1, gatePick{Pick: ranking.Position, RiderId: ranking.RiderId, RiderNumber: ranking.RiderNumber})
+		}
+	}
+
+	// Moto 2 gate pick goes by the Moto 1 finish. Qualified riders without a
+	// Moto 1 result pick last, in qualifying order.
+	picked := map[int]bool{}
+	for _, result := range results {
+		if result.Class != class || result.Moto != 1 || !qualified[result.RiderId] {
+			continue
+		}
+		picked[result.RiderId] = true
+		qualifying.GatePick2 = append(qualifying.GatePick2, gatePick{RiderId: result.RiderId})
+	}
+
+	if len(qualifying.GatePick2) > 0 {
+		for _, pick := range qualifying.GatePick1 {
+			if !picked[pick.RiderId] {
+				qualifying.GatePick2 = append(qualifying.GatePick2, gatePick{RiderId: pick.RiderId})
+			}
+		}
+
+		numbers := map[int]int{}
+		for _, pick := range qualifying.GatePick1 {
+			numbers[pick.RiderId] = pick.RiderNumber
+		}
+		for i := range qualifying.GatePick2 {
+			qualifying.GatePick2[i].Pick = i + 1
+			qualifying.GatePick2[i].RiderNumber = numbers[qualifying.GatePick2[i].RiderId]
+		}
+	}
+
+	return qualifying
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -11,13 +12,15 @@ import (
 
 // CreateRider creates a new rider
 // @Summary Create a new rider ** Auth Required **
-// @Description Create a new rider with the provided details
+// @Description Create a new rider with the provided details. A rider with a class has their number registered in it for the season, which fails if the number is taken or reserved for someone else; see the number registry.
 // @Tags riders
 // @Accept json
 // @Produce json
 // @Param rider body database.Rider true "Rider data"
+// @Param season query int false "Season to register the number for, defaults to the latest"
 // @Success 201 {object} database.Rider
 // @Failure 400 {object} gin.H "Invalid request body"
+// @Failure 409 {object} gin.H "Number taken or reserved"
 // @Failure 500 {object} gin.H "Failed to create the rider"
 // @Router /api/v1/riders [post]
 func (app *application) createRider(c *gin.Context) {
@@ -28,10 +31,25 @@ func (app *application) createRider(c *gin.Context) {
 		return
 	}
 
+	season, ok := app.seasonParam(c, database.DefaultSeriesId)
+	if !ok {
+		return
+	}
+
 	user := app.GetUserFromContext(c)
 	rider.OwnerId = user.Id
 
-	err := app.models.Riders.Insert(&rider)
+	err := app.models.Transaction(func(tx database.Models) error {
+		if err := tx.Riders.Insert(&rider); err != nil {
+			return err
+		}
+		return registerNumber(tx, season, &rider)
+	})
+	var conflict *numberConflict
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the rider."})
 		fmt.Println(err.Error())
@@ -48,6 +66,7 @@ func (app *application) createRider(c *gin.Context) {
 // @Produce json
 // @Param id path int true "Rider ID"
 // @Success 200 {object} database.Rider
+// @Success 301 "Rider was merged into another; Location gives their new address"
 // @Failure 400 {object} gin.H "Invalid rider ID"
 // @Failure 404 {object} gin.H "No rider found at that ID"
 // @Failure 500 {object} gin.H "Server failed to get the requested rider"
@@ -57,31 +76,103 @@ func (app *application) getRider(c *gin.Context) {
 
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+		return
 	}
 
 	rider, err := app.models.Riders.Get(id)
 
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server failed to get the requested rider."})
+		return
+	}
+
 	if rider == nil {
+		if app.redirectMergedRider(c, id) {
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{"error": "No rider found at that Id."})
+		return
 	}
 
+	c.JSON(http.StatusOK, rider)
+}
+
+type riderStats struct {
+	RiderId       int                       `json:"riderId"`
+	Career        []*database.RiderStatLine `json:"career"`
+	Seasons       []*database.RiderStatLine `json:"seasons"`
+	Championships []database.Championship   `json:"championships"`
+}
+
+// GetRiderStats returns a rider's career and season statistics
+// @Summary Rider career statistics
+// @Description Computes a rider's career and per-season record in each class of each series from stored results: overall wins, podiums, top-5s and top-10s, moto wins, average finish, DNF rate, points and championships. Championships are the classes the rider topped in the rider standings of seasons whose last event has passed.
+// @Tags riders
+// @Produce json
+// @Param id path int true "Rider ID"
+// @Success 200 {object} riderStats
+// @Success 301 "Rider was merged into another; Location gives their new address"
+// @Failure 400 {object} gin.H "Invalid rider ID"
+// @Failure 404 {object} gin.H "Rider not found"
+// @Failure 500 {object} gin.H "Failed to compute statistics"
+// @Router /api/v1/riders/{id}/stats [get]
+func (app *application) getRiderStats(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Server failed to get the requested rider."})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rider Id."})
+		return
 	}
 
-	c.JSON(http.StatusOK, rider)
+	rider, err := app.models.Riders.Get(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+		return
+	}
+	if rider == nil {
+		if app.redirectMergedRider(c, id) {
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rider not found."})
+		return
+	}
+
+	seasons, err := app.models.RiderStats.GetSeasons(rider.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute statistics."})
+		return
+	}
+
+	championships, err := app.riderChampionships(rider.Id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute statistics."})
+		return
+	}
+
+	c.JSON(http.StatusOK, riderStats{
+		RiderId:       rider.Id,
+		Career:        database.Career(seasons, championships),
+		Seasons:       seasons,
+		Championships: championships,
+	})
 }
 
 // GetAllRiders returns all riders
 // @Summary Get all riders
-// @Description Get a list of all riders
+// @Description Get a list of all riders, optionally only those who have entered or raced in one series
 // @Tags riders
 // @Produce json
+// @Param series query string false "Only riders in this series, by ID or slug"
 // @Success 200 {array} database.Rider
+// @Failure 404 {object} gin.H "Series not found"
 // @Failure 500 {object} gin.H "Server failed to get all riders"
 // @Router /api/v1/riders [get]
 func (app *application) getAllRiders(c *gin.Context) {
-	riders, err := app.models.Riders.GetAll()
+	seriesId, ok := app.seriesFilter(c)
+	if !ok {
+		return
+	}
+
+	riders, err := app.models.Riders.GetAll(seriesId)
 
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Sever failed to get all riders."})
@@ -92,15 +183,17 @@ func (app *application) getAllRiders(c *gin.Context) {
 
 // UpdateRider updates an existing rider
 // @Summary Update a rider ** Auth Required **
-// @Description Update an existing rider with the provided details
+// @Description Update an existing rider with the provided details. The rider's number is registered in their class for the season, replacing the one registered before, which fails if the number is taken or reserved for someone else.
 // @Tags riders
 // @Accept json
 // @Produce json
 // @Param id path int true "Rider ID"
 // @Param rider body database.Rider true "Updated rider data"
+// @Param season query int false "Season to register the number for, defaults to the latest"
 // @Success 200 {object} database.Rider
 // @Failure 400 {object} gin.H "Invalid rider ID or request body"
 // @Failure 404 {object} gin.H "Rider not found"
+// @Failure 409 {object} gin.H "Number taken or reserved"
 // @Failure 500 {object} gin.H "Failed to update rider"
 // @Router /api/v1/riders/{id} [put]
 func (app *application) updateRider(c *gin.Context) {
@@ -137,7 +230,23 @@ func (app *application) updateRider(c *gin.Context) {
 
 	updatedRider.Id = id
 
-	if err := app.models.Riders.Update(updatedRider); err != nil {
+	season, ok := app.seasonParam(c, database.DefaultSeriesId)
+	if !ok {
+		return
+	}
+
+	err = app.models.Transaction(func(tx database.Models) error {
+		if err := tx.Riders.Update(updatedRider); err != nil {
+			return err
+		}
+		return registerNumber(tx, season, updatedRider)
+	})
+	var conflict *numberConflict
+	if errors.As(err, &conflict) {
+		c.JSON(http.StatusConflict, gin.H{"error": conflict.Error()})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update rider!"})
 		return
 	}
@@ -147,11 +256,13 @@ func (app *application) updateRider(c *gin.Context) {
 
 // DeleteRider deletes a rider
 // @Summary Delete a rider ** Auth Required **
-// @Description Delete a rider by their ID
+// @Description Delete a rider by their ID, along with their media
 // @Tags riders
 // @Param id path int true "Rider ID"
 // @Success 204 "No Content"
 // @Failure 400 {object} gin.H "Invalid rider ID"
+// @Failure 401 {object} gin.H "Not the rider's owner"
+// @Failure 404 {object} gin.H "Rider not found"
 // @Failure 500 {object} gin.H "Failed to delete the rider"
 // @Router /api/v1/riders/{id} [delete]
 func (app *application) deleteRider(c *gin.Context) {
@@ -169,13 +280,38 @@ func (app *application) deleteRider(c *gin.Context) {
 		return
 	}
 
-	if user.Id != existingRider.Id {
+	if existingRider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Rider not found."})
+		return
+	}
+
+	if user.Id != existingRider.OwnerId {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "You are not authorized to delete the rider!"})
 		return
 	}
 
-	if err := app.models.Riders.Delete(id); err != nil {
+	media, err := app.models.Media.GetByRider(id, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the rider."})
+		return
+	}
+
+	err = app.models.Transaction(func(tx database.Models) error {
+		if err := tx.Media.DeleteByRider(id); err != nil {
+			return err
+		}
+		if err := tx.Numbers.DeleteByRider(id); err != nil {
+			return err
+		}
+		return tx.Riders.Delete(id)
+	})
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the rider."})
+		return
+	}
+
+	for _, item := range media {
+		app.deleteMediaBlobs(item)
 	}
 
 	c.JSON(http.StatusNoContent, nil)
@@ -20,11 +20,44 @@ func (app *application) routes() http.Handler {
 		v1.GET("/events/:id", app.getEvent)
 
 		v1.GET("/riders", app.getAllRiders)
+		v1.GET("/riders/compare", app.compareRiders)
 		v1.GET("/riders/:id", app.getRider)
+		v1.GET("/riders/:id/calendar.ics", app.getRiderCalendar)
+		v1.GET("/riders/:id/stats", app.getRiderStats)
+		v1.GET("/riders/:id/media", app.getRiderMedia)
+		v1.GET("/media/*key", app.getMediaFile)
+		v1.GET("/numbers", app.getNumbers)
+
+		v1.GET("/series", app.getAllSeries)
+		v1.GET("/series/:id", app.getSeries)
+		v1.GET("/series/:id/scoring", app.getScoringRules)
+
+		v1.GET("/calendar.ics", app.getCalendar)
 
 		v1.GET("/events/:id/attendees", app.getAttendeesForEvent)
+		v1.GET("/events/:id/entries", app.getEntries)
+		v1.GET("/events/:id/live", app.getLiveTiming)
+		v1.GET("/events/:id/motos/:moto/laps", app.getMotoLaps)
+		v1.GET("/events/:id/qualifying", app.getQualifying)
+		v1.GET("/events/:id/awards", app.getEventAwards)
+		v1.GET("/events/:id/overall", app.getOverall)
+		v1.GET("/events/:id/penalties", app.getPenalties)
+		v1.GET("/events/:id/protests", app.getProtests)
+		v1.GET("/events/:id/motos/:moto/revisions", app.getMotoRevisions)
+
+		v1.GET("/stats/holeshots", app.getHoleshotStats)
+		v1.GET("/stats/laps-led", app.getLapsLedStats)
+		v1.GET("/standings/riders", app.getRiderStandings)
+		v1.GET("/standings/manufacturers", app.getManufacturerStandings)
+		v1.GET("/standings/teams", app.getTeamStandings)
+		v1.GET("/standings/title-race", app.getTitleRace)
+		v1.POST("/standings/title-race", app.whatIfTitleRace)
+		v1.GET("/ws", app.liveSocket)
 		v1.GET("/attendees/:id/events", app.getEventsByAttendee)
 
+		v1.GET("/export/:dataset", app.exportDataset)
+		v1.GET("/export/:dataset/schema", app.getExportSchema)
+
 		v1.POST("/auth/register", app.registerUser)
 		v1.POST("/auth/login", app.login)
 	}
@@ -35,13 +68,55 @@ func (app *application) routes() http.Handler {
 		authGroup.POST("/events", app.createEvent)
 		authGroup.PUT("/events/:id", app.updateEvent)
 		authGroup.DELETE("/events/:id", app.deleteEvent)
+		authGroup.PUT("/events/:id/status", app.updateEventStatus)
+		authGroup.POST("/events/:id/reschedule", app.rescheduleEvent)
+
+		authGroup.POST("/series", app.createSeries)
+		authGroup.PUT("/series/:id", app.updateSeries)
+		authGroup.PUT("/series/:id/scoring", app.setScoringRules)
+		authGroup.DELETE("/series/:id/scoring", app.deleteScoringRules)
 
 		authGroup.POST("/riders", app.createRider)
 		authGroup.PUT("/riders/:id", app.updateRider)
 		authGroup.DELETE("/riders/:id", app.deleteRider)
+		authGroup.POST("/riders/:id/media", app.uploadRiderMedia)
+		authGroup.DELETE("/riders/:id/media/:mediaId", app.deleteRiderMedia)
 
 		authGroup.POST("/events/:id/attendees/:riderId", app.addAttendeeToEvent)
 		authGroup.DELETE("/events/:id/attendees/:riderId", app.deleteAttendeeFromEvent)
+		authGroup.POST("/events/:id/attendees/:riderId/confirm", app.confirmEntry)
+		authGroup.PUT("/events/:id/capacity", app.setClassCapacity)
+		authGroup.GET("/entries/pending", app.getPendingEntries)
+		authGroup.POST("/events/:id/entries/:entryId/approve", app.approveEntry)
+		authGroup.POST("/events/:id/entries/:entryId/reject", app.rejectEntry)
+
+		authGroup.POST("/events/:id/motos/:moto/crossings", app.ingestCrossings)
+		authGroup.POST("/events/:id/motos/:moto/laps", app.importLaps)
+		authGroup.PUT("/events/:id/motos/:moto/holeshot", app.setHoleshot)
+		authGroup.PUT("/events/:id/motos/:moto/laps-led", app.setLapsLed)
+
+		authGroup.POST("/events/:id/motos/:moto/penalties", app.createPenalty)
+		authGroup.DELETE("/events/:id/penalties/:penaltyId", app.rescindPenalty)
+		authGroup.POST("/events/:id/motos/:moto/official", app.declareOfficial)
+		authGroup.POST("/events/:id/protests", app.fileProtest)
+		authGroup.PUT("/events/:id/protests/:protestId", app.decideProtest)
+
+		authGroup.POST("/events/:id/qualifying", app.createQualifyingSession)
+		authGroup.POST("/events/:id/qualifying/:sessionId/times", app.recordQualifyingTimes)
+
+		authGroup.POST("/auth/keys", app.createAPIKey)
+		authGroup.GET("/auth/keys", app.getAPIKeys)
+		authGroup.DELETE("/auth/keys/:id", app.deleteAPIKey)
+
+		authGroup.POST("/import/riders", app.importRiders)
+		authGroup.POST("/events/:id/import/results", app.importResults)
+	}
+
+	adminGroup := authGroup.Group("/")
+	adminGroup.Use(app.AdminMiddleware())
+	{
+		adminGroup.GET("/riders/duplicates", app.getDuplicateRiders)
+		adminGroup.POST("/riders/:id/merge", app.mergeRider)
 	}
 
 	g.GET("/swagger/*any", func(c *gin.Context) {
@@ -0,0 +1,535 @@
+package main
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/scoring"
+	"github.com/gin-gonic/gin"
+)
+
+// Where a season's scoring rules come from: saved for the season, saved
+// for every season of the series, or the series' points table.
+const (
+	rulesFromSeason = "season"
+	rulesFromSeries = "series"
+	rulesFromTable  = "table"
+)
+
+type seriesScoring struct {
+	Series string        `json:"series"`
+	Season int           `json:"season"`
+	Source string        `json:"source"`
+	Rules  scoring.Rules `json:"rules"`
+}
+
+// riderStanding is a rider's place in the championship along with who they
+// are.
+type riderStanding struct {
+	scoring.Standing
+	Number    int    `json:"number"`
+	FirstName string `json:"firstName"`
+	LastName  string `json:"lastName"`
+}
+
+type riderStandings struct {
+	Series    string          `json:"series"`
+	Season    int             `json:"season"`
+	Class     string          `json:"class"`
+	Source    string          `json:"source"`
+	Standings []riderStanding `json:"standings"`
+}
+
+// GetScoringRules returns how a series scores a season
+// @Summary Series scoring rules
+// @Description Returns the points rules a series scores a season by. Source says where they come from: saved for the season, saved for every season of the series, or, when nothing is saved, the series' points table on every moto.
+// @Tags series
+// @Produce json
+// @Param id path string true "Series ID or slug"
+// @Param season query int false "Season, defaults to the latest"
+// @Success 200 {object} seriesScoring
+// @Failure 400 {object} gin.H "Invalid season"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to retrieve the scoring rules"
+// @Router /api/v1/series/{id}/scoring [get]
+func (app *application) getScoringRules(c *gin.Context) {
+	series, ok := app.lookupSeries(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	season, ok := app.seasonParam(c, series.Id)
+	if !ok {
+		return
+	}
+
+	rules, source, err := scoringRules(app.models, series, season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the scoring rules."})
+		return
+	}
+
+	c.JSON(http.StatusOK, seriesScoring{Series: series.Slug, Season: season, Source: source, Rules: rules})
+}
+
+// SetScoringRules saves how a series scores a season
+// @Summary Set series scoring rules ** Auth Required **
+// @Description Saves the points rules for a season of a series, or for every season without its own when no season is given. Basis is moto (the default) to score every moto or overall to score the overall finish at each round. Points is paid by finishing position, multipliers scale a round's finish points, dropWorst rounds are left out of the total and bonuses pay per holeshot, per lap led or for pole (the top qualifier). Only the series owner can change its rules.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path string true "Series ID or slug"
+// @Param season query int false "Season, or every season when left out"
+// @Param rules body scoring.Rules true "Scoring rules"
+// @Success 200 {object} database.ScoringRules
+// @Failure 400 {object} gin.H "Invalid season or rules"
+// @Failure 403 {object} gin.H "Not the series owner"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to save the scoring rules"
+// @Router /api/v1/series/{id}/scoring [put]
+func (app *application) setScoringRules(c *gin.Context) {
+	series, season, ok := app.ownedSeriesSeason(c, "change the scoring of")
+	if !ok {
+		return
+	}
+
+	var rules scoring.Rules
+	if err := c.ShouldBindJSON(&rules); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if rules.Basis == "" {
+		rules.Basis = scoring.BasisMoto
+	}
+	if err := rules.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scoring rules: " + err.Error() + "."})
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	saved := database.ScoringRules{SeriesId: series.Id, Season: season, Rules: rules, UpdatedBy: &user.Id}
+	if err := app.models.Scoring.Save(&saved); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save the scoring rules."})
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+// DeleteScoringRules drops the rules saved for a season of a series
+// @Summary Delete series scoring rules ** Auth Required **
+// @Description Removes the rules saved for a season of a series, or those for every season when no season is given, so the season falls back to the series rules or its points table.
+// @Tags series
+// @Param id path string true "Series ID or slug"
+// @Param season query int false "Season, or every season when left out"
+// @Success 204 "No Content"
+// @Failure 400 {object} gin.H "Invalid season"
+// @Failure 403 {object} gin.H "Not the series owner"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to delete the scoring rules"
+// @Router /api/v1/series/{id}/scoring [delete]
+func (app *application) deleteScoringRules(c *gin.Context) {
+	series, season, ok := app.ownedSeriesSeason(c, "change the scoring of")
+	if !ok {
+		return
+	}
+
+	if err := app.models.Scoring.Delete(series.Id, season); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete the scoring rules."})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetRiderStandings returns the riders' championship
+// @Summary Rider championship standings
+// @Description Scores a season of a series under its scoring rules and ranks the riders per class, with what each scored at every round. Ties on points go to more wins, then the better score at the latest round. Missed rounds score 0; cancelled events are not rounds.
+// @Tags standings
+// @Produce json
+// @Param series query string false "Series ID or slug, defaults to Pro Motocross"
+// @Param season query int false "Season, defaults to the latest"
+// @Param class query string false "Only this class"
+// @Success 200 {array} riderStandings
+// @Failure 400 {object} gin.H "Invalid season"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to compute standings"
+// @Router /api/v1/standings/riders [get]
+func (app *application) getRiderStandings(c *gin.Context) {
+	series, ok := app.seriesParam(c)
+	if !ok {
+		return
+	}
+
+	season, ok := app.seasonParam(c, series.Id)
+	if !ok {
+		return
+	}
+
+	standings, err := app.riderStandings(series, season, c.Query("class"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute standings."})
+		return
+	}
+
+	c.JSON(http.StatusOK, standings)
+}
+
+// ownedSeriesSeason loads the series named by the id path parameter and the
+// season query parameter, 0 when left out, for its owner.
+func (app *application) ownedSeriesSeason(c *gin.Context, action string) (*database.Series, int, bool) {
+	series, ok := app.lookupSeries(c, c.Param("id"))
+	if !ok {
+		return nil, 0, false
+	}
+
+	user := app.GetUserFromContext(c)
+	if series.OwnerId == nil || *series.OwnerId != user.Id {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to " + action + " a series you don't own."})
+		return nil, 0, false
+	}
+
+	season := 0
+	if value := c.Query("season"); value != "" {
+		var err error
+		if season, err = strconv.Atoi(value); err != nil || season < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid season."})
+			return nil, 0, false
+		}
+	}
+
+	return series, season, true
+}
+
+// scoringRules returns the rules a series scores a season by and where
+// they come from.
+func scoringRules(models database.Models, series *database.Series, season int) (scoring.Rules, string, error) {
+	saved, err := models.Scoring.Get(series.Id, season)
+	if err != nil {
+		return scoring.Rules{}, "", err
+	}
+
+	switch {
+	case saved == nil:
+		return scoring.Rules{Basis: scoring.BasisMoto, Points: series.Points, Multipliers: []scoring.Multiplier{}, Bonuses: []scoring.Bonus{}}, rulesFromTable, nil
+	case saved.Season == 0:
+		return saved.Rules, rulesFromSeries, nil
+	default:
+		return saved.Rules, rulesFromSeason, nil
+	}
+}
+
+// deductionKey is a rider's moto, which points penalties are issued
+// against.
+type deductionKey struct {
+	eventId int
+	class   string
+	moto    int
+	riderId int
+}
+
+// seasonScores is everything a season of a series is scored from, leaving
+// out cancelled events. The standings, the title race and the champion all
+// come from it so they can't disagree.
+type seasonScores struct {
+	series     *database.Series
+	season     int
+	rules      scoring.Rules
+	source     string
+	events     []*database.Event
+	rounds     []int
+	roundOf    map[int]int
+	results    []*database.Result
+	awards     map[string][]scoring.Award
+	deductions map[deductionKey]int
+}
+
+func loadSeasonScores(models database.Models, series *database.Series, season int) (*seasonScores, error) {
+	rules, source, err := scoringRules(models, series, season)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := models.Events.GetBySeason(series.Id, season)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := models.Results.GetBySeason(series.Id, season)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := &seasonScores{series: series, season: season, rules: rules, source: source, roundOf: map[int]int{}, deductions: map[deductionKey]int{}}
+
+	// Events without a round number count by their place in the calendar.
+	// Cancelled events are not rounds at all: they pay no points and
+	// can't be dropped.
+	seen := map[int]bool{}
+	for i, event := range events {
+		if event.Status == database.EventCancelled {
+			continue
+		}
+		scores.events = append(scores.events, event)
+
+		round := event.Round
+		if round == 0 {
+			round = i + 1
+		}
+		scores.roundOf[event.Id] = round
+		if !seen[round] {
+			seen[round] = true
+			scores.rounds = append(scores.rounds, round)
+		}
+
+		penalties, err := models.Penalties.GetByEvent(event.Id)
+		if err != nil {
+			return nil, err
+		}
+		for _, penalty := range penalties {
+			if penalty.Type == database.PenaltyPoints && penalty.RescindedAt == nil {
+				scores.deductions[deductionKey{penalty.EventId, penalty.Class, penalty.Moto, penalty.RiderId}] += penalty.Value
+			}
+		}
+	}
+
+	for _, result := range results {
+		if _, ok := scores.roundOf[result.EventId]; ok {
+			scores.results = append(scores.results, &result.Result)
+		}
+	}
+
+	if scores.awards, err = scoringAwards(models, rules, scores.events, scores.roundOf); err != nil {
+		return nil, err
+	}
+
+	return scores, nil
+}
+
+// classes lists the classes with results in the season, or just the one
+// given if it has any.
+func (s *seasonScores) classes(only string) []string {
+	seen := map[string]bool{}
+	var classes []string
+	for _, result := range s.results {
+		if seen[result.Class] || (only != "" && result.Class != only) {
+			continue
+		}
+		seen[result.Class] = true
+		classes = append(classes, result.Class)
+	}
+	sort.Strings(classes)
+	return classes
+}
+
+// evaluate ranks a class, scoring extra results as if they had been raced.
+func (s *seasonScores) evaluate(class string, extra []*database.Result) []scoring.Standing {
+	var results []*database.Result
+	for _, result := range append(append([]*database.Result{}, s.results...), extra...) {
+		if result.Class == class {
+			results = append(results, result)
+		}
+	}
+
+	return scoring.Evaluate(s.rules, s.rounds, s.finishes(results), s.awards[class])
+}
+
+// finished reports whether the last event of the season has passed.
+func (s *seasonScores) finished() bool {
+	if len(s.events) == 0 {
+		return false
+	}
+	last := ""
+	for _, event := range s.events {
+		last = max(last, event.Date[:min(len(event.Date), len("2006-01-02"))])
+	}
+	return last < time.Now().Format("2006-01-02")
+}
+
+// riderStandings scores every class of a season, or just the one given.
+func (app *application) riderStandings(series *database.Series, season int, class string) ([]riderStandings, error) {
+	scores, err := loadSeasonScores(app.models, series, season)
+	if err != nil {
+		return nil, err
+	}
+
+	riders := map[int]*database.Rider{}
+	all := []riderStandings{}
+	for _, class := range scores.classes(class) {
+		classStandings := riderStandings{Series: series.Slug, Season: season, Class: class, Source: scores.source, Standings: []riderStanding{}}
+		for _, standing := range scores.evaluate(class, nil) {
+			rider, ok := riders[standing.RiderId]
+			if !ok {
+				if rider, err = app.models.Riders.Get(standing.RiderId); err != nil {
+					return nil, err
+				}
+				riders[standing.RiderId] = rider
+			}
+			if rider == nil {
+				continue
+			}
+			classStandings.Standings = append(classStandings.Standings, riderStanding{Standing: standing, Number: rider.Number, FirstName: rider.FirstName, LastName: rider.LastName})
+		}
+		all = append(all, classStandings)
+	}
+
+	return all, nil
+}
+
+// seasonChampion returns the rider on top of a class once the season is
+// over, or nil while it is still running or has no results.
+func seasonChampion(models database.Models, series *database.Series, season int, class string) (*int, error) {
+	scores, err := loadSeasonScores(models, series, season)
+	if err != nil {
+		return nil, err
+	}
+
+	return scores.champion(class), nil
+}
+
+func (s *seasonScores) champion(class string) *int {
+	if !s.finished() {
+		return nil
+	}
+	standings := s.evaluate(class, nil)
+	if len(standings) == 0 {
+		return nil
+	}
+	return &standings[0].RiderId
+}
+
+// riderChampionships finds the classes a rider won in seasons that are
+// over, scored as the rider standings are.
+func (app *application) riderChampionships(riderId int) ([]database.Championship, error) {
+	raced, err := app.models.RiderStats.GetRacedSeasons(riderId)
+	if err != nil {
+		return nil, err
+	}
+
+	type seriesSeason struct {
+		seriesId int
+		season   int
+	}
+	seasons := map[seriesSeason]*seasonScores{}
+
+	championships := []database.Championship{}
+	for _, r := range raced {
+		key := seriesSeason{r.SeriesId, r.Season}
+		scores, ok := seasons[key]
+		if !ok {
+			series, err := app.models.Series.Get(r.SeriesId)
+			if err != nil {
+				return nil, err
+			}
+			if series == nil {
+				continue
+			}
+			if scores, err = loadSeasonScores(app.models, series, r.Season); err != nil {
+				return nil, err
+			}
+			seasons[key] = scores
+		}
+
+		if champion := scores.champion(r.Class); champion != nil && *champion == riderId {
+			championships = append(championships, database.Championship{Series: scores.series.Slug, Season: r.Season, Class: r.Class})
+		}
+	}
+
+	return championships, nil
+}
+
+// finishes turns a class's results into the finishes the rules score:
+// every moto, or each round's overall classification, with the points
+// penalties took off them.
+func (s *seasonScores) finishes(results []*database.Result) []scoring.Finish {
+	var finishes []scoring.Finish
+	if s.rules.Basis != scoring.BasisOverall {
+		for _, result := range results {
+			finishes = append(finishes, scoring.Finish{
+				Round:     s.roundOf[result.EventId],
+				RiderId:   result.RiderId,
+				Position:  result.Position,
+				Finished:  result.Status == database.ResultFinished,
+				Deduction: s.deductions[deductionKey{result.EventId, result.Class, result.Moto, result.RiderId}],
+			})
+		}
+		return finishes
+	}
+
+	byEvent := map[int][]*database.Result{}
+	deducted := map[int]map[int]int{}
+	var eventIds []int
+	for _, result := range results {
+		if _, ok := byEvent[result.EventId]; !ok {
+			eventIds = append(eventIds, result.EventId)
+			deducted[result.EventId] = map[int]int{}
+		}
+		byEvent[result.EventId] = append(byEvent[result.EventId], result)
+		deducted[result.EventId][result.RiderId] += s.deductions[deductionKey{result.EventId, result.Class, result.Moto, result.RiderId}]
+	}
+
+	for _, eventId := range eventIds {
+		for _, overall := range database.ClassifyOverall(byEvent[eventId]) {
+			finishes = append(finishes, scoring.Finish{
+				Round:     s.roundOf[eventId],
+				RiderId:   overall.RiderId,
+				Position:  overall.Position,
+				Finished:  true,
+				Deduction: deducted[eventId][overall.RiderId],
+			})
+		}
+	}
+	return finishes
+}
+
+// scoringAwards collects the holeshots, laps led and poles of a season by
+// class, but only those the rules pay a bonus for.
+func scoringAwards(models database.Models, rules scoring.Rules, events []*database.Event, roundOf map[int]int) (map[string][]scoring.Award, error) {
+	awards := map[string][]scoring.Award{}
+
+	for _, event := range events {
+		round := roundOf[event.Id]
+
+		if rules.BonusFor(scoring.BonusHoleshot) != 0 {
+			holeshots, err := models.Awards.GetHoleshotsByEvent(event.Id)
+			if err != nil {
+				return nil, err
+			}
+			for _, h := range holeshots {
+				awards[h.Class] = append(awards[h.Class], scoring.Award{Round: round, RiderId: h.RiderId, Kind: scoring.BonusHoleshot, Count: 1})
+			}
+		}
+
+		if rules.BonusFor(scoring.BonusLapLed) != 0 {
+			lapsLed, err := models.Awards.GetLapsLedByEvent(event.Id)
+			if err != nil {
+				return nil, err
+			}
+			for _, l := range lapsLed {
+				awards[l.Class] = append(awards[l.Class], scoring.Award{Round: round, RiderId: l.RiderId, Kind: scoring.BonusLapLed, Count: l.Laps})
+			}
+		}
+
+		if rules.BonusFor(scoring.BonusPole) != 0 {
+			sessions, err := models.Qualifying.GetSessionsByEvent(event.Id)
+			if err != nil {
+				return nil, err
+			}
+			times, err := models.Qualifying.GetTimesByEvent(event.Id)
+			if err != nil {
+				return nil, err
+			}
+			for _, class := range qualifyingClasses(sessions) {
+				if combined := rankQualifying(class, sessions, times, nil).Combined; len(combined) > 0 {
+					awards[class] = append(awards[class], scoring.Award{Round: round, RiderId: combined[0].RiderId, Kind: scoring.BonusPole, Count: 1})
+				}
+			}
+		}
+	}
+
+	return awards, nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/gin-gonic/gin"
+)
+
+// seriesSlug is what a series slug looks like: lowercase words joined by
+// hyphens, so it reads cleanly in a query string.
+var seriesSlug = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// GetAllSeries returns every series
+// @Summary Get all series
+// @Description Lists the championships events are raced in, with the classes each runs and the points it pays per moto.
+// @Tags series
+// @Produce json
+// @Success 200 {array} database.Series
+// @Failure 500 {object} gin.H "Failed to retrieve series"
+// @Router /api/v1/series [get]
+func (app *application) getAllSeries(c *gin.Context) {
+	series, err := app.models.Series.GetAll()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve series."})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// GetSeries returns a single series
+// @Summary Get a series
+// @Description Returns a series by its ID or slug.
+// @Tags series
+// @Produce json
+// @Param id path string true "Series ID or slug"
+// @Success 200 {object} database.Series
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to retrieve the series"
+// @Router /api/v1/series/{id} [get]
+func (app *application) getSeries(c *gin.Context) {
+	series, ok := app.lookupSeries(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// CreateSeries creates a new series
+// @Summary Create a series ** Auth Required **
+// @Description Creates a series owned by the caller. Classes limit which classes its events run, and can be left empty to allow any. Points is what each moto pays by finishing position, and motosPerEvent how many points-paying motos a class runs per round; both default to the Pro Motocross rules.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param series body database.Series true "Series"
+// @Success 201 {object} database.Series
+// @Failure 400 {object} gin.H "Invalid request body"
+// @Failure 409 {object} gin.H "Slug already in use"
+// @Failure 500 {object} gin.H "Failed to create the series"
+// @Router /api/v1/series [post]
+func (app *application) createSeries(c *gin.Context) {
+	var series database.Series
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !app.validSeries(c, &series) {
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	series.OwnerId = &user.Id
+
+	if err := app.models.Series.Insert(&series); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create the series."})
+		return
+	}
+
+	c.JSON(http.StatusCreated, series)
+}
+
+// UpdateSeries updates a series
+// @Summary Update a series ** Auth Required **
+// @Description Replaces a series' name, slug, classes and scoring. Only the owner can change a series; the series the API ships with have no owner and can't be changed. Points already awarded are not recalculated.
+// @Tags series
+// @Accept json
+// @Produce json
+// @Param id path string true "Series ID or slug"
+// @Param series body database.Series true "Series"
+// @Success 200 {object} database.Series
+// @Failure 400 {object} gin.H "Invalid request body"
+// @Failure 403 {object} gin.H "Not the series owner"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 409 {object} gin.H "Slug already in use"
+// @Failure 500 {object} gin.H "Failed to update the series"
+// @Router /api/v1/series/{id} [put]
+func (app *application) updateSeries(c *gin.Context) {
+	existing, ok := app.lookupSeries(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	user := app.GetUserFromContext(c)
+	if existing.OwnerId == nil || *existing.OwnerId != user.Id {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to update a series you don't own."})
+		return
+	}
+
+	var series database.Series
+	if err := c.ShouldBindJSON(&series); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	series.Id = existing.Id
+	if !app.validSeries(c, &series) {
+		return
+	}
+
+	if err := app.models.Series.Update(&series); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the series."})
+		return
+	}
+
+	series.OwnerId = existing.OwnerId
+	series.CreatedAt = existing.CreatedAt
+
+	c.JSON(http.StatusOK, series)
+}
+
+// validSeries checks a series' slug is well formed and not taken by another
+// series, responding with the error itself when it isn't.
+func (app *application) validSeries(c *gin.Context, series *database.Series) bool {
+	if !seriesSlug.MatchString(series.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The slug can only hold lowercase letters, digits and single hyphens."})
+		return false
+	}
+
+	// Numeric slugs would be mistaken for ids wherever a series is looked up.
+	if _, err := strconv.Atoi(series.Slug); err == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The slug can't be just a number."})
+		return false
+	}
+
+	taken, err := app.models.Series.GetBySlug(series.Slug)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve series."})
+		return false
+	}
+	if taken != nil && taken.Id != series.Id {
+		c.JSON(http.StatusConflict, gin.H{"error": "Another series already uses that slug."})
+		return false
+	}
+
+	return true
+}
+
+// seriesParam reads the series query parameter, by ID or slug, defaulting to
+// Pro Motocross. It responds with the error itself when it fails.
+func (app *application) seriesParam(c *gin.Context) (*database.Series, bool) {
+	return app.lookupSeries(c, c.DefaultQuery("series", strconv.Itoa(database.DefaultSeriesId)))
+}
+
+// seriesFilter reads the series query parameter for lists that cover every
+// series unless one is asked for, returning zero when it isn't.
+func (app *application) seriesFilter(c *gin.Context) (int, bool) {
+	if c.Query("series") == "" {
+		return 0, true
+	}
+
+	series, ok := app.seriesParam(c)
+	if !ok {
+		return 0, false
+	}
+	return series.Id, true
+}
+
+// lookupSeries finds a series by ID or slug, responding with an error if
+// there is none.
+func (app *application) lookupSeries(c *gin.Context, value string) (*database.Series, bool) {
+	var series *database.Series
+	var err error
+	if id, convErr := strconv.Atoi(value); convErr == nil {
+		series, err = app.models.Series.Get(id)
+	} else {
+		series, err = app.models.Series.GetBySlug(value)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the series."})
+		return nil, false
+	}
+	if series == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Series not found."})
+		return nil, false
+	}
+
+	return series, true
+}
+
+// knownSeries checks the series an event is put in exists, responding with
+// the error itself when it doesn't. Events without one go in Pro Motocross.
+func (app *application) knownSeries(c *gin.Context, event *database.Event) bool {
+	if event.SeriesId == 0 {
+		event.SeriesId = database.DefaultSeriesId
+	}
+
+	series, err := app.models.Series.Get(event.SeriesId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the series."})
+		return false
+	}
+	if series == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "There is no series with that Id."})
+		return false
+	}
+
+	return true
+}
+
+// eventSeries loads the series an event is raced in.
+func (app *application) eventSeries(c *gin.Context, event *database.Event) (*database.Series, bool) {
+	series, err := app.models.Series.Get(event.SeriesId)
+	if err != nil || series == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve the event's series."})
+		return nil, false
+	}
+	return series, true
+}
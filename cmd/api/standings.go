@@ -0,0 +1,243 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"sort"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/scoring"
+	"github.com/gin-gonic/gin"
+)
+
+// roundPoints is what a manufacturer or team scored at one event.
+type roundPoints struct {
+	EventId   int    `json:"eventId"`
+	EventName string `json:"eventName"`
+	Round     int    `json:"round"`
+	Points    int    `json:"points"`
+}
+
+type standingRow struct {
+	Position int           `json:"position"`
+	Name     string        `json:"name"`
+	Points   int           `json:"points"`
+	Wins     int           `json:"motoWins"`
+	Rounds   []roundPoints `json:"rounds"`
+}
+
+type championshipStandings struct {
+	Series    string        `json:"series"`
+	Season    int           `json:"season"`
+	Class     string        `json:"class"`
+	Standings []standingRow `json:"standings"`
+}
+
+// standingName picks the manufacturer or team a result counts towards.
+type standingName func(result *database.Result) string
+
+// GetManufacturerStandings returns the manufacturers' championship
+// @Summary Manufacturer championship standings
+// @Description Scores each moto, or each round's overall finish when the series' scoring rules say so, for the best-placed bike of every brand, using the bike each rider raced that day. Finishes pay the rules' points table and each round is scaled by its multiplier, as in the rider standings; rider bonuses, penalty deductions and dropped rounds don't count. Points are totalled per class with a per-round breakdown, and ties are broken by wins. Cancelled events don't count.
+// @Tags standings
+// @Produce json
+// @Param series query string false "Series ID or slug, defaults to Pro Motocross"
+// @Param season query int false "Season, defaults to the latest"
+// @Param class query string false "Only this class"
+// @Success 200 {array} championshipStandings
+// @Failure 400 {object} gin.H "Invalid season"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to compute standings"
+// @Router /api/v1/standings/manufacturers [get]
+func (app *application) getManufacturerStandings(c *gin.Context) {
+	app.writeStandings(c, func(result *database.Result) string { return result.BikeBrand }, true)
+}
+
+// GetTeamStandings returns the teams' championship
+// @Summary Team championship standings
+// @Description Scores each moto, or each round's overall finish when the series' scoring rules say so, for every rider on a team, using the team each rider raced for that day. Finishes pay the rules' points table and each round is scaled by its multiplier, as in the rider standings; rider bonuses, penalty deductions and dropped rounds don't count. Points are totalled per class with a per-round breakdown, and ties are broken by wins. Cancelled events don't count.
+// @Tags standings
+// @Produce json
+// @Param series query string false "Series ID or slug, defaults to Pro Motocross"
+// @Param season query int false "Season, defaults to the latest"
+// @Param class query string false "Only this class"
+// @Success 200 {array} championshipStandings
+// @Failure 400 {object} gin.H "Invalid season"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to compute standings"
+// @Router /api/v1/standings/teams [get]
+func (app *application) getTeamStandings(c *gin.Context) {
+	app.writeStandings(c, func(result *database.Result) string { return result.Team }, false)
+}
+
+// placing is where a manufacturer's or team's rider finished.
+type placing struct {
+	name     string
+	position int
+	finished bool
+}
+
+// standingRace is one moto, or one round's overall classification, that
+// manufacturers and teams score in.
+type standingRace struct {
+	event    *database.Event
+	round    int
+	placings []placing
+}
+
+func (app *application) writeStandings(c *gin.Context, name standingName, bestOnly bool) {
+	series, ok := app.seriesParam(c)
+	if !ok {
+		return
+	}
+
+	season, ok := app.seasonParam(c, series.Id)
+	if !ok {
+		return
+	}
+
+	scores, err := loadSeasonScores(app.models, series, season)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute standings."})
+		return
+	}
+
+	standings := []championshipStandings{}
+	for _, class := range scores.classes(c.Query("class")) {
+		standings = append(standings, championshipStandings{Series: series.Slug, Season: season, Class: class, Standings: tallyStandings(scores.rules, scores.races(class, name), bestOnly)})
+	}
+
+	c.JSON(http.StatusOK, standings)
+}
+
+// races splits a class's results into what the rules score, in race order:
+// every moto, or each round's overall classification. Overall, a rider
+// counts towards the name they raced their last moto of the round under.
+func (s *seasonScores) races(class string, name standingName) []standingRace {
+	events := map[int]*database.Event{}
+	for _, event := range s.events {
+		events[event.Id] = event
+	}
+
+	// Results come in race order, so each moto's results, and each round's,
+	// are contiguous.
+	var groups [][]*database.Result
+	for _, result := range s.results {
+		if result.Class != class {
+			continue
+		}
+		if n := len(groups); n > 0 {
+			first := groups[n-1][0]
+			if first.EventId == result.EventId && (s.rules.Basis == scoring.BasisOverall || first.Moto == result.Moto) {
+				groups[n-1] = append(groups[n-1], result)
+				continue
+			}
+		}
+		groups = append(groups, []*database.Result{result})
+	}
+
+	races := make([]standingRace, 0, len(groups))
+	for _, results := range groups {
+		race := standingRace{event: events[results[0].EventId], round: s.roundOf[results[0].EventId]}
+
+		if s.rules.Basis != scoring.BasisOverall {
+			for _, result := range results {
+				race.placings = append(race.placings, placing{name: name(result), position: result.Position, finished: result.Status == database.ResultFinished})
+			}
+		} else {
+			names := map[int]string{}
+			for _, result := range results {
+				names[result.RiderId] = name(result)
+			}
+			for _, overall := range database.ClassifyOverall(results) {
+				race.placings = append(race.placings, placing{name: names[overall.RiderId], position: overall.Position, finished: true})
+			}
+		}
+
+		races = append(races, race)
+	}
+
+	return races
+}
+
+// scoreRace gives each name what its best-placed rider's finish pays under
+// the rules, or with bestOnly false what all its riders' finishes pay,
+// along with the name of the winner.
+func scoreRace(rules scoring.Rules, placings []placing, bestOnly bool) (map[string]int, string) {
+	points := map[string]int{}
+	winner := ""
+	for _, p := range placings {
+		if p.name == "" {
+			continue
+		}
+
+		value := 0
+		if p.finished {
+			value = rules.PointsFor(p.position)
+			if p.position == 1 {
+				winner = p.name
+			}
+		}
+
+		if !bestOnly {
+			points[p.name] += value
+		} else if current, ok := points[p.name]; !ok || value > current {
+			points[p.name] = value
+		}
+	}
+	return points, winner
+}
+
+func tallyStandings(rules scoring.Rules, races []standingRace, bestOnly bool) []standingRow {
+	rows := map[string]*standingRow{}
+
+	for _, race := range races {
+		points, winner := scoreRace(rules, race.placings, bestOnly)
+
+		for key, value := range points {
+			row, ok := rows[key]
+			if !ok {
+				row = &standingRow{Name: key, Rounds: []roundPoints{}}
+				rows[key] = row
+			}
+			if key == winner {
+				row.Wins++
+			}
+
+			if n := len(row.Rounds); n > 0 && row.Rounds[n-1].EventId == race.event.Id {
+				row.Rounds[n-1].Points += value
+			} else {
+				row.Rounds = append(row.Rounds, roundPoints{EventId: race.event.Id, EventName: race.event.Name, Round: race.round, Points: value})
+			}
+		}
+	}
+
+	// Each round is scaled by its multiplier and rounded once, as in the
+	// rider standings.
+	standings := make([]standingRow, 0, len(rows))
+	for _, row := range rows {
+		for i := range row.Rounds {
+			round := &row.Rounds[i]
+			round.Points = int(math.Round(float64(round.Points) * rules.Factor(round.Round)))
+			row.Points += round.Points
+		}
+		standings = append(standings, *row)
+	}
+
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		return a.Name < b.Name
+	})
+
+	for i := range standings {
+		standings[i].Position = i + 1
+	}
+
+	return standings
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// thumbnailSizes are the thumbnails made of every uploaded image, by name,
+// with the longest edge each is scaled to fit.
+var thumbnailSizes = []struct {
+	name string
+	edge int
+}{
+	{"small", 160},
+	{"medium", 640},
+}
+
+// thumbnailKey is where a thumbnail of the image at key is stored.
+// Thumbnails are always JPEGs.
+func thumbnailKey(key, name string) string {
+	if dot := strings.LastIndex(key, "."); dot > strings.LastIndex(key, "/") {
+		key = key[:dot]
+	}
+	return key + "_" + name + ".jpg"
+}
+
+// thumbnail scales an image down to fit within edge pixels on its longest
+// side, averaging the source pixels behind each thumbnail pixel. Images that
+// already fit keep their size. Transparent areas are filled in white, since
+// JPEG has no alpha.
+func thumbnail(src image.Image, edge int) *image.RGBA {
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	thumbWidth, thumbHeight := width, height
+	if width > edge || height > edge {
+		if width >= height {
+			thumbWidth, thumbHeight = edge, max(1, height*edge/width)
+		} else {
+			thumbWidth, thumbHeight = max(1, width*edge/height), edge
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, thumbWidth, thumbHeight))
+	for y := 0; y < thumbHeight; y++ {
+		y0 := y * height / thumbHeight
+		y1 := max((y+1)*height/thumbHeight, y0+1)
+
+		for x := 0; x < thumbWidth; x++ {
+			x0 := x * width / thumbWidth
+			x1 := max((x+1)*width/thumbWidth, x0+1)
+
+			var r, g, b, a uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(bounds.Min.X+sx, bounds.Min.Y+sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+				}
+			}
+
+			n := uint64((y1 - y0) * (x1 - x0))
+			// The colours are premultiplied, so adding what alpha leaves
+			// uncovered lays the pixel over white.
+			white := 0xffff*n - a
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8((r + white) / n >> 8),
+				G: uint8((g + white) / n >> 8),
+				B: uint8((b + white) / n >> 8),
+				A: 0xff,
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,334 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/scoring"
+	"github.com/gin-gonic/gin"
+)
+
+// remainingRound is an event of the season with motos still to run.
+type remainingRound struct {
+	EventId   int    `json:"eventId"`
+	EventName string `json:"eventName"`
+	Round     int    `json:"round"`
+	Motos     []int  `json:"motos"`
+}
+
+// titleContender is a rider's place in the title race. MaxPoints is what
+// they finish with if they win every remaining moto, and a rider is
+// eliminated once that can no longer reach the leader's current points.
+type titleContender struct {
+	Position   int    `json:"position"`
+	RiderId    int    `json:"riderId"`
+	Number     int    `json:"number"`
+	FirstName  string `json:"firstName"`
+	LastName   string `json:"lastName"`
+	Points     int    `json:"points"`
+	Behind     int    `json:"behind"`
+	MaxPoints  int    `json:"maxPoints"`
+	Eliminated bool   `json:"eliminated"`
+}
+
+// titleRace is the state of a class championship. MagicNumber is the
+// combination of points the leader gains and the closest challenger fails
+// to score that clinches the title outright; it is 0 once clinched, and
+// while nobody has scored to challenge the leader.
+type titleRace struct {
+	Series          string           `json:"series"`
+	Season          int              `json:"season"`
+	Class           string           `json:"class"`
+	Remaining       []remainingRound `json:"remaining"`
+	RemainingMotos  int              `json:"remainingMotos"`
+	PointsAvailable int              `json:"pointsAvailable"`
+	LeaderId        int              `json:"leaderId,omitempty"`
+	MagicNumber     int              `json:"magicNumber"`
+	Clinched        bool             `json:"clinched"`
+	Standings       []titleContender `json:"standings"`
+}
+
+// hypotheticalMoto is a made-up finishing order for a moto still to run.
+type hypotheticalMoto struct {
+	EventId  int   `json:"eventId" binding:"required"`
+	Moto     int   `json:"moto" binding:"required,min=1"`
+	RiderIds []int `json:"riderIds" binding:"required,min=1"`
+}
+
+type whatIfRequest struct {
+	Series string             `json:"series"`
+	Season int                `json:"season"`
+	Class  string             `json:"class" binding:"required"`
+	Motos  []hypotheticalMoto `json:"motos" binding:"required,min=1,dive"`
+}
+
+type whatIf struct {
+	Current  titleRace `json:"current"`
+	Scenario titleRace `json:"scenario"`
+}
+
+// GetTitleRace returns who can still win a class championship
+// @Summary Championship title race
+// @Description Ranks a class under the series' scoring rules, as the rider standings do, and works out, from the motos still to run, the points each rider can still reach, who is mathematically eliminated and the leader's magic number to clinch. Motos still to run are counted at the series' motos per round of every event not cancelled, each paying a win scaled by its round's multiplier, a holeshot bonus and, per round, a pole bonus. Laps led bonuses are not counted.
+// @Tags standings
+// @Produce json
+// @Param series query string false "Series ID or slug, defaults to Pro Motocross"
+// @Param season query int false "Season, defaults to the latest"
+// @Param class query string true "Class"
+// @Success 200 {object} titleRace
+// @Failure 400 {object} gin.H "Invalid season or class"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 500 {object} gin.H "Failed to compute the title race"
+// @Router /api/v1/standings/title-race [get]
+func (app *application) getTitleRace(c *gin.Context) {
+	series, ok := app.seriesParam(c)
+	if !ok {
+		return
+	}
+
+	season, ok := app.seasonParam(c, series.Id)
+	if !ok {
+		return
+	}
+
+	class := c.Query("class")
+	if class == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A class is required."})
+		return
+	}
+
+	race, err := app.titleRace(series, season, class)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute the title race."})
+		return
+	}
+
+	c.JSON(http.StatusOK, race.outlook())
+}
+
+// WhatIfTitleRace plays out hypothetical results
+// @Summary Championship what-if scenarios
+// @Description Applies made-up finishing orders for motos still to run on top of the current standings and returns the title race before and after. Riders finish each moto in the order of riderIds and the season is scored again under the series' scoring rules. The series defaults to Pro Motocross. Nothing is saved.
+// @Tags standings
+// @Accept json
+// @Produce json
+// @Param scenario body whatIfRequest true "Hypothetical moto results"
+// @Success 200 {object} whatIf
+// @Failure 400 {object} gin.H "Invalid scenario"
+// @Failure 404 {object} gin.H "Series not found"
+// @Failure 422 {object} gin.H "Moto already run or unknown rider"
+// @Failure 500 {object} gin.H "Failed to compute the title race"
+// @Router /api/v1/standings/title-race [post]
+func (app *application) whatIfTitleRace(c *gin.Context) {
+	var request whatIfRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if request.Series == "" {
+		request.Series = strconv.Itoa(database.DefaultSeriesId)
+	}
+	series, ok := app.lookupSeries(c, request.Series)
+	if !ok {
+		return
+	}
+
+	if request.Season == 0 {
+		season, err := app.models.Events.LatestSeason(series.Id)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve events."})
+			return
+		}
+		request.Season = season
+	}
+
+	race, err := app.titleRace(series, request.Season, request.Class)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute the title race."})
+		return
+	}
+
+	var response whatIf
+	response.Current = race.outlook()
+
+	for _, moto := range request.Motos {
+		if !race.takeMoto(moto.EventId, moto.Moto) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Moto %d of event %d is not still to run, or is listed twice.", moto.Moto, moto.EventId)})
+			return
+		}
+
+		seen := map[int]bool{}
+		for i, riderId := range moto.RiderIds {
+			if seen[riderId] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Rider %d is listed twice in moto %d of event %d.", riderId, moto.Moto, moto.EventId)})
+				return
+			}
+			seen[riderId] = true
+
+			if _, ok := race.riders[riderId]; !ok {
+				rider, err := app.models.Riders.Get(riderId)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve rider."})
+					return
+				}
+				if rider == nil {
+					c.JSON(http.StatusUnprocessableEntity, gin.H{"error": fmt.Sprintf("Rider %d not found.", riderId)})
+					return
+				}
+				race.add(rider)
+			}
+			race.hypothetical = append(race.hypothetical, &database.Result{
+				EventId:  moto.EventId,
+				RiderId:  riderId,
+				Class:    race.class,
+				Moto:     moto.Moto,
+				Position: i + 1,
+				Status:   database.ResultFinished,
+			})
+		}
+	}
+
+	response.Scenario = race.outlook()
+
+	c.JSON(http.StatusOK, response)
+}
+
+// titleState is a class championship being worked out: the season's
+// scores, results made up for motos still to run and the motos left.
+type titleState struct {
+	scores       *seasonScores
+	class        string
+	riders       map[int]*titleContender
+	hypothetical []*database.Result
+	remaining    []remainingRound
+}
+
+func (app *application) titleRace(series *database.Series, season int, class string) (*titleState, error) {
+	scores, err := loadSeasonScores(app.models, series, season)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &titleState{scores: scores, class: class, riders: map[int]*titleContender{}}
+	scored := map[int]map[int]bool{}
+
+	for _, result := range scores.results {
+		if result.Class != class {
+			continue
+		}
+
+		if scored[result.EventId] == nil {
+			scored[result.EventId] = map[int]bool{}
+		}
+		scored[result.EventId][result.Moto] = true
+
+		if _, ok := state.riders[result.RiderId]; ok {
+			continue
+		}
+		rider, err := app.models.Riders.Get(result.RiderId)
+		if err != nil {
+			return nil, err
+		}
+		if rider != nil {
+			state.add(rider)
+		}
+	}
+
+	// The season's scores leave cancelled events out, so they have no motos
+	// left to run.
+	for _, event := range scores.events {
+		round := remainingRound{EventId: event.Id, EventName: event.Name, Round: scores.roundOf[event.Id], Motos: []int{}}
+		for moto := 1; moto <= series.MotosPerEvent; moto++ {
+			if !scored[event.Id][moto] {
+				round.Motos = append(round.Motos, moto)
+			}
+		}
+		if len(round.Motos) > 0 {
+			state.remaining = append(state.remaining, round)
+		}
+	}
+
+	return state, nil
+}
+
+func (state *titleState) add(rider *database.Rider) *titleContender {
+	contender := &titleContender{RiderId: rider.Id, Number: rider.Number, FirstName: rider.FirstName, LastName: rider.LastName}
+	state.riders[rider.Id] = contender
+	return contender
+}
+
+// takeMoto removes a moto from those still to run, reporting whether it was
+// there to take.
+func (state *titleState) takeMoto(eventId, moto int) bool {
+	for i, round := range state.remaining {
+		if round.EventId != eventId {
+			continue
+		}
+		for j, m := range round.Motos {
+			if m == moto {
+				state.remaining[i].Motos = append(round.Motos[:j:j], round.Motos[j+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// outlook ranks the riders under the series' scoring rules and works out
+// elimination and the magic number from the points still available.
+func (state *titleState) outlook() titleRace {
+	rules := state.scores.rules
+	race := titleRace{Series: state.scores.series.Slug, Season: state.scores.season, Class: state.class, Remaining: []remainingRound{}, Standings: []titleContender{}}
+
+	for _, round := range state.remaining {
+		if len(round.Motos) == 0 {
+			continue
+		}
+		race.Remaining = append(race.Remaining, remainingRound{EventId: round.EventId, EventName: round.EventName, Round: round.Round, Motos: append([]int{}, round.Motos...)})
+		race.RemainingMotos += len(round.Motos)
+
+		wins := len(round.Motos)
+		if rules.Basis == scoring.BasisOverall {
+			wins = 1
+		}
+		race.PointsAvailable += int(math.Round(float64(wins*rules.PointsFor(1))*rules.Factor(round.Round))) +
+			len(round.Motos)*rules.BonusFor(scoring.BonusHoleshot) + rules.BonusFor(scoring.BonusPole)
+	}
+
+	for _, standing := range state.scores.evaluate(state.class, state.hypothetical) {
+		contender, ok := state.riders[standing.RiderId]
+		if !ok {
+			continue
+		}
+		contender.Points = standing.Points
+		race.Standings = append(race.Standings, *contender)
+	}
+
+	if len(race.Standings) == 0 {
+		return race
+	}
+
+	leader := race.Standings[0]
+	race.LeaderId = leader.RiderId
+
+	for i := range race.Standings {
+		contender := &race.Standings[i]
+		contender.Position = i + 1
+		contender.Behind = leader.Points - contender.Points
+		contender.MaxPoints = contender.Points + race.PointsAvailable
+		contender.Eliminated = i > 0 && contender.MaxPoints < leader.Points
+	}
+
+	// The title is only clinched once there is a challenger and they can't
+	// reach the leader any more.
+	if len(race.Standings) > 1 {
+		race.MagicNumber = max(race.Standings[1].MaxPoints-leader.Points+1, 0)
+		race.Clinched = race.Standings[1].MaxPoints < leader.Points
+	}
+
+	return race
+}
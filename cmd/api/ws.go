@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/timing"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// wsPingInterval is how often the server pings; a client that hasn't
+	// answered within wsPongWait is considered gone.
+	wsPingInterval = 30 * time.Second
+	wsPongWait     = 60 * time.Second
+	wsWriteWait    = 10 * time.Second
+
+	wsMaxMessageSize = 4096
+	wsMaxTopics      = 50
+)
+
+// Topics are "event:12", "event:12:class:450", "event:12:class:450:moto:2"
+// and "rider:7".
+var wsTopicPattern = regexp.MustCompile(`^(event:\d+(:class:[A-Za-z0-9+-]+(:moto:\d+)?)?|rider:\d+)$`)
+
+// Clients authenticate with a token or API key rather than cookies, so any
+// origin may connect; native tablet apps don't send one at all.
+var wsUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	CheckOrigin:     func(r *http.Request) bool { return true },
+}
+
+// wsRequest is a message from the client. Action is "subscribe",
+// "unsubscribe" or "ping". LastId, on subscribe, resumes the topics after
+// that message id instead of starting from the latest snapshot.
+type wsRequest struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+	LastId int64    `json:"lastId"`
+}
+
+// wsResponse is a message from the server. Type is "message" for a
+// published update, "subscribed" with the full topic list after every
+// subscribe or unsubscribe, "pong", or "error".
+type wsResponse struct {
+	Type   string    `json:"type"`
+	Id     int64     `json:"id,omitempty"`
+	Topic  string    `json:"topic,omitempty"`
+	Event  string    `json:"event,omitempty"`
+	Time   time.Time `json:"time,omitzero"`
+	Data   any       `json:"data,omitempty"`
+	Topics []string  `json:"topics,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// LiveSocket is a WebSocket for following live leaderboards
+// @Summary Live leaderboards over WebSocket
+// @Description Upgrades to a WebSocket. Authenticate with a bearer token (Authorization header or token query parameter) or an API key (X-API-Key header or apiKey query parameter). Send {"action":"subscribe","topics":["event:12:class:450:moto:2","rider:7"]} to follow topics; topics are event:ID, event:ID:class:CLASS, event:ID:class:CLASS:moto:N and rider:ID. Updates arrive as {"type":"message","id":...,"topic":...,"event":"standings"|"leaderboard","data":...}. The server pings every 30 seconds. Clients that fall too far behind are closed with code 1013 and can reconnect with lastId to resume.
+// @Tags live
+// @Param token query string false "JWT, for clients that can't set headers"
+// @Param apiKey query string false "API key, for clients that can't set headers"
+// @Param topics query string false "Comma separated topics to subscribe to on connect"
+// @Param lastId query int false "Resume the initial topics after this message id"
+// @Success 101 "Switching Protocols"
+// @Failure 400 {object} gin.H "Invalid topic"
+// @Failure 401 {object} gin.H "Missing or invalid credentials"
+// @Router /api/v1/ws [get]
+func (app *application) liveSocket(c *gin.Context) {
+	if _, err := app.socketUser(c); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	var topics []string
+	if value := c.Query("topics"); value != "" {
+		topics = strings.Split(value, ",")
+		if err := validateTopics(topics); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	lastId, _ := strconv.ParseInt(c.Query("lastId"), 10, 64)
+
+	conn, err := wsUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// The upgrader has already responded.
+		return
+	}
+
+	defer conn.Close()
+
+	sub := app.live.Subscribe(topics, lastId)
+	defer sub.Close()
+
+	requests := make(chan wsRequest)
+	done := make(chan struct{})
+	stop := make(chan struct{})
+	defer close(stop)
+	go readSocket(conn, requests, done, stop)
+
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	write := func(response wsResponse) bool {
+		conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+		return conn.WriteJSON(response) == nil
+	}
+
+	if len(topics) > 0 && !write(wsResponse{Type: "subscribed", Topics: sub.Topics()}) {
+		return
+	}
+
+	var sent int64
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				// The hub dropped us for falling behind. Tell the client
+				// where to resume from instead of silently losing updates.
+				reason := fmt.Sprintf("too slow, resume with lastId %d", sent)
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, reason), time.Now().Add(wsWriteWait))
+				return
+			}
+			if !write(messageResponse(msg)) {
+				return
+			}
+			sent = msg.Id
+
+		case request := <-requests:
+			if !write(handleSocketRequest(sub, request)) {
+				return
+			}
+
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteWait)); err != nil {
+				return
+			}
+
+		case <-done:
+			return
+		}
+	}
+}
+
+// socketUser accepts the same credentials as AuthMiddleware, and also takes
+// them from the query string since browsers can't set headers on a
+// WebSocket handshake.
+func (app *application) socketUser(c *gin.Context) (*database.User, error) {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return app.userFromAPIKey(key)
+	}
+	if key := c.Query("apiKey"); key != "" {
+		return app.userFromAPIKey(key)
+	}
+
+	if token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+		return app.userFromToken(token)
+	}
+	if token := c.Query("token"); token != "" {
+		return app.userFromToken(token)
+	}
+
+	return nil, errors.New("A bearer token or API key is required")
+}
+
+// readSocket reads client messages until the connection fails or stop is
+// closed, keeping the read deadline moving as long as pongs come back.
+func readSocket(conn *websocket.Conn, requests chan<- wsRequest, done chan<- struct{}, stop <-chan struct{}) {
+	defer close(done)
+
+	conn.SetReadLimit(wsMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var request wsRequest
+		if err := json.Unmarshal(data, &request); err != nil {
+			request = wsRequest{Action: "invalid"}
+		}
+
+		conn.SetReadDeadline(time.Now().Add(wsPongWait))
+
+		select {
+		case requests <- request:
+		case <-stop:
+			return
+		}
+	}
+}
+
+func handleSocketRequest(sub *timing.Subscription, request wsRequest) wsResponse {
+	switch request.Action {
+	case "ping":
+		return wsResponse{Type: "pong"}
+
+	case "subscribe":
+		if err := validateTopics(request.Topics); err != nil {
+			return wsResponse{Type: "error", Error: err.Error()}
+		}
+		if len(sub.Topics())+len(request.Topics) > wsMaxTopics {
+			return wsResponse{Type: "error", Error: fmt.Sprintf("At most %d topics per connection.", wsMaxTopics)}
+		}
+		sub.Add(request.LastId, request.Topics...)
+		return wsResponse{Type: "subscribed", Topics: sub.Topics()}
+
+	case "unsubscribe":
+		sub.Remove(request.Topics...)
+		return wsResponse{Type: "subscribed", Topics: sub.Topics()}
+	}
+
+	return wsResponse{Type: "error", Error: `Unknown action, expected "subscribe", "unsubscribe" or "ping".`}
+}
+
+func validateTopics(topics []string) error {
+	if len(topics) == 0 {
+		return errors.New("No topics given.")
+	}
+	if len(topics) > wsMaxTopics {
+		return fmt.Errorf("At most %d topics per connection.", wsMaxTopics)
+	}
+	for _, topic := range topics {
+		if !wsTopicPattern.MatchString(topic) {
+			return fmt.Errorf("Invalid topic %q.", topic)
+		}
+	}
+	return nil
+}
+
+func messageResponse(msg timing.Message) wsResponse {
+	return wsResponse{
+		Type:  "message",
+		Id:    msg.Id,
+		Topic: msg.Topic,
+		Event: msg.Event,
+		Time:  msg.Time,
+		Data:  msg.Data,
+	}
+}
@@ -0,0 +1,401 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/match"
+)
+
+type count struct {
+	created int
+	updated int
+}
+
+// importer writes a matched archive to the database.
+type importer struct {
+	models  database.Models
+	series  *database.Series
+	ownerId int
+	counts  map[string]*count
+}
+
+func (im *importer) track(table string, created bool) {
+	c, ok := im.counts[table]
+	if !ok {
+		c = &count{}
+		im.counts[table] = c
+	}
+	if created {
+		c.created++
+	} else {
+		c.updated++
+	}
+}
+
+func (im *importer) load(archive []*archiveRider) error {
+	if err := im.ensureOwner(); err != nil {
+		return err
+	}
+
+	riders := map[*sheetRow]int{}
+	for _, a := range archive {
+		if a.RiderId == 0 {
+			if err := im.createRider(a); err != nil {
+				return err
+			}
+		}
+		for _, row := range a.Rows {
+			riders[row] = a.RiderId
+		}
+	}
+
+	motos := map[motoKey][]*sheetRow{}
+	dates := map[roundKey]string{}
+	var order []motoKey
+	for _, a := range archive {
+		for _, row := range a.Rows {
+			key := motoKey{roundKey{row.Season, row.Round}, row.Class, row.Moto}
+			if row.Date != "" {
+				dates[key.roundKey] = row.Date
+			}
+			if _, ok := motos[key]; !ok {
+				order = append(order, key)
+			}
+			motos[key] = append(motos[key], row)
+		}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if a.Season != b.Season {
+			return a.Season < b.Season
+		}
+		if a.Round != b.Round {
+			return a.Round < b.Round
+		}
+		if a.Class != b.Class {
+			return a.Class < b.Class
+		}
+		return a.Moto < b.Moto
+	})
+
+	events := map[roundKey]*database.Event{}
+	touched := map[int]map[string]bool{}
+
+	for _, key := range order {
+		event, ok := events[key.roundKey]
+		if !ok {
+			var err error
+			event, err = im.ensureEvent(motos[key][0], dates[key.roundKey])
+			if err != nil {
+				return err
+			}
+			events[key.roundKey] = event
+			touched[event.Id] = map[string]bool{}
+		}
+
+		if err := im.upsertMoto(event, motos[key], riders); err != nil {
+			return err
+		}
+		touched[event.Id][key.Class] = true
+	}
+
+	for eventId, classes := range touched {
+		for class := range classes {
+			if err := im.models.Overall.Recompute(eventId, class); err != nil {
+				return err
+			}
+		}
+	}
+
+	return im.registerNumbers(archive)
+}
+
+func (im *importer) ensureOwner() error {
+	user, err := im.models.Users.GetByEmail(archiveEmail)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		user = &database.User{Email: archiveEmail, Name: "Results Archive", Password: "!"}
+		if err := im.models.Users.Insert(user); err != nil {
+			return err
+		}
+	}
+
+	im.ownerId = user.Id
+	return nil
+}
+
+// createRider adds an archive rider who isn't in the database yet, as they
+// were in the last round they raced.
+func (im *importer) createRider(a *archiveRider) error {
+	first, last := match.Split(reorder(a.Name))
+
+	rider := &database.Rider{
+		OwnerId:   im.ownerId,
+		FirstName: first,
+		LastName:  last,
+		Number:    a.Latest.Number,
+		BikeBrand: a.Latest.Bike,
+		Class:     a.Latest.Class,
+		Status:    "active",
+	}
+
+	if err := im.models.Riders.Insert(rider); err != nil {
+		return err
+	}
+
+	im.track("riders", true)
+	a.RiderId = rider.Id
+	return nil
+}
+
+// reorder turns "Last, First" around to "First Last".
+func reorder(name string) string {
+	if last, first, ok := strings.Cut(name, ","); ok {
+		return strings.TrimSpace(first) + " " + strings.TrimSpace(last)
+	}
+	return name
+}
+
+// skipUnofficial leaves out the rows of rounds already in the database that
+// aren't official, returning those events so they can be reported. Their
+// results are still being settled, or the round was never raced.
+func skipUnofficial(models database.Models, seriesId int, rows []*sheetRow) ([]*sheetRow, []*database.Event, error) {
+	events := map[roundKey]*database.Event{}
+	var kept []*sheetRow
+	var skipped []*database.Event
+
+	for _, row := range rows {
+		key := roundKey{row.Season, row.Round}
+		event, ok := events[key]
+		if !ok {
+			var err error
+			event, err = models.Events.GetBySeasonAndRound(seriesId, row.Season, row.Round)
+			if err != nil {
+				return nil, nil, err
+			}
+			events[key] = event
+			if event != nil && event.Status != database.EventOfficial {
+				skipped = append(skipped, event)
+			}
+		}
+
+		if event == nil || event.Status == database.EventOfficial {
+			kept = append(kept, row)
+		}
+	}
+
+	return kept, skipped, nil
+}
+
+// ensureEvent finds the series' round a row was raced at, creating it and
+// its track when they aren't in the database. Events it creates are
+// official straight away, since their results are long settled. Existing
+// events must already be official. Rounds without a date are put on
+// January 1st of their season.
+func (im *importer) ensureEvent(row *sheetRow, date string) (*database.Event, error) {
+	existing, err := im.models.Events.GetBySeasonAndRound(im.series.Id, row.Season, row.Round)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.Status != database.EventOfficial {
+			return nil, fmt.Errorf("%s: %d round %d is %s, not official", row.where(), row.Season, row.Round, existing.Status)
+		}
+		im.track("events", false)
+		return existing, nil
+	}
+
+	track, err := im.models.Tracks.GetByName(row.Track)
+	if err != nil {
+		return nil, err
+	}
+	if track == nil {
+		track = &database.Track{Name: row.Track, Location: row.Track}
+		if err := im.models.Tracks.Insert(track); err != nil {
+			return nil, err
+		}
+		im.track("tracks", true)
+	}
+
+	if date == "" {
+		date = fmt.Sprintf("%d-01-01", row.Season)
+	}
+
+	event := &database.Event{
+		OwnerId:     im.ownerId,
+		Name:        track.Name,
+		Description: fmt.Sprintf("Round %d of the %d %s at %s.", row.Round, row.Season, im.series.Name, track.Name),
+		Date:        date,
+		Location:    track.Location,
+		Season:      row.Season,
+		Round:       row.Round,
+		TrackId:     &track.Id,
+		SeriesId:    im.series.Id,
+	}
+
+	if err := im.models.Events.Insert(event); err != nil {
+		return nil, err
+	}
+
+	event.Status = database.EventOfficial
+	if err := im.models.Events.UpdateStatus(event); err != nil {
+		return nil, err
+	}
+
+	im.track("events", true)
+	return event, nil
+}
+
+// upsertMoto writes a moto's results, classifying non-finishers after the
+// finishers in the order the sheet lists them.
+func (im *importer) upsertMoto(event *database.Event, rows []*sheetRow, riders map[*sheetRow]int) error {
+	var finished, others []*sheetRow
+	for _, row := range rows {
+		if row.Status == database.ResultFinished {
+			finished = append(finished, row)
+		} else {
+			others = append(others, row)
+		}
+	}
+	sort.SliceStable(finished, func(i, j int) bool { return finished[i].Position < finished[j].Position })
+	sort.SliceStable(others, func(i, j int) bool { return others[i].Line < others[j].Line })
+
+	// Sheets sometimes leave out finishers, so non-finishers go after the
+	// last position listed rather than after the number of finishers.
+	last := 0
+	if len(finished) > 0 {
+		last = finished[len(finished)-1].Position
+	}
+
+	seen := map[int]*sheetRow{}
+
+	for i, row := range append(finished, others...) {
+		riderId := riders[row]
+		if first, ok := seen[riderId]; ok {
+			return fmt.Errorf("%s: %s is already in this moto on %s", row.where(), row.Rider, first.where())
+		}
+		seen[riderId] = row
+
+		result := &database.Result{
+			EventId:   event.Id,
+			RiderId:   riderId,
+			Class:     row.Class,
+			Moto:      row.Moto,
+			Position:  row.Position,
+			Status:    row.Status,
+			BikeBrand: row.Bike,
+		}
+
+		if row.Status == database.ResultFinished {
+			result.Points = im.series.PointsFor(row.Position)
+		} else {
+			result.Position = last + i - len(finished) + 1
+		}
+
+		if err := im.ensureAttendee(event.Id, riderId, row.Class); err != nil {
+			return err
+		}
+
+		existing, err := im.models.Results.GetByEventRiderMoto(event.Id, riderId, row.Class, row.Moto)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			im.track("results", true)
+			if err := im.models.Results.Insert(result); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result.Id = existing.Id
+		result.Team = existing.Team
+		im.track("results", false)
+		if err := im.models.Results.Update(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (im *importer) ensureAttendee(eventId, riderId int, class string) error {
+	existing, err := im.models.Attendees.GetByEventAndAttendee(eventId, riderId)
+	if err != nil || existing != nil {
+		return err
+	}
+
+	_, err = im.models.Attendees.Insert(&database.Attendee{EventId: eventId, RiderId: riderId, Class: class})
+	return err
+}
+
+// registerNumbers records the number each rider raced under in each
+// season, leaving alone numbers already registered to someone and riders
+// who already have a number that season. A rider racing two classes in a
+// season keeps the number of the first.
+func (im *importer) registerNumbers(archive []*archiveRider) error {
+	classes := map[int][]string{}
+	for _, a := range archive {
+		for _, row := range a.Rows {
+			if !containsString(classes[row.Season], row.Class) {
+				classes[row.Season] = append(classes[row.Season], row.Class)
+			}
+		}
+	}
+	for season := range classes {
+		for _, class := range im.series.Classes {
+			if !containsString(classes[season], class) {
+				classes[season] = append(classes[season], class)
+			}
+		}
+	}
+
+	registered := map[int][]*database.RiderNumber{}
+	for season, seasonClasses := range classes {
+		for _, class := range seasonClasses {
+			numbers, err := im.models.Numbers.GetBySeason(season, class)
+			if err != nil {
+				return err
+			}
+			registered[season] = append(registered[season], numbers...)
+		}
+	}
+
+	for _, a := range archive {
+		for _, row := range a.Rows {
+			free := true
+			for _, n := range registered[row.Season] {
+				if n.RiderId == a.RiderId || (n.Class == row.Class && n.Number == row.Number) {
+					free = false
+					break
+				}
+			}
+			if !free {
+				continue
+			}
+
+			if err := im.models.Numbers.Assign(row.Season, row.Class, row.Number, a.RiderId); err != nil {
+				return err
+			}
+			registered[row.Season] = append(registered[row.Season], &database.RiderNumber{Season: row.Season, Class: row.Class, Number: row.Number, RiderId: a.RiderId})
+		}
+	}
+
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
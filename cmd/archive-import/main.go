@@ -0,0 +1,160 @@
+// Command archive-import loads historical results kept in spreadsheets into
+// the database, creating the events, tracks and riders they need.
+//
+// Each sheet is a CSV file with a header row naming its columns, in any
+// order:
+//
+//	season    year the round was raced in, such as 2009
+//	round     round number within the season
+//	track     track the round was raced at, the same on every row of a round
+//	class     class the moto was for, such as 250 or 450
+//	moto      moto number within the class
+//	position  finishing position, or DNF, DNS or DQ
+//	rider     rider's name as written, "First Last" or "Last, First"
+//	number    number the rider raced under
+//	bike      bike brand (optional)
+//	date      date of the round as YYYY-MM-DD (optional, defaults to
+//	          January 1st of the season)
+//
+// Riders on the sheets are matched to riders already in the database by
+// name, tolerating differences in case, accents, punctuation, word order
+// and initials. A name that only nearly matches also needs the same race
+// number, which settles close calls too. By default the
+// command only writes a report of how every rider was matched. Ambiguous
+// riders are listed first; fill in their riderId with a rider's Id, or
+// "new" to create them, and pass the report back with -resolve. Nothing is
+// written until -commit is given and every rider is settled, and then the
+// whole archive goes in one transaction.
+//
+// Non-finishers are classified after the finishers of their moto in the
+// order they appear on the sheet. Points come from the series' points
+// table. Rounds that aren't in the database yet are created as official.
+// Rounds that are but aren't official yet, or were cancelled, belong to
+// whoever runs them, so their rows are skipped and listed instead.
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// archiveEmail owns the events and riders the archive creates. Its password
+// is not a bcrypt hash, so nobody can log in as it.
+const archiveEmail = "archive@pro-motocross.local"
+
+func main() {
+	dbPath := flag.String("db", "./data.db", "path to the SQLite database")
+	seriesSlug := flag.String("series", "pro-motocross", "slug of the series the archive is for")
+	commit := flag.Bool("commit", false, "write the archive to the database instead of only reporting")
+	resolvePath := flag.String("resolve", "", "reviewed report settling ambiguous riders")
+	reportPath := flag.String("report", "", "where to write the match report (default stdout)")
+	threshold := flag.Float64("threshold", 0.88, "lowest name similarity, from 0 to 1, considered a possible match")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		log.Fatal("usage: archive-import [flags] sheet.csv...")
+	}
+
+	rows, err := readSheets(flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	resolutions := map[string]string{}
+	if *resolvePath != "" {
+		resolutions, err = readResolutions(*resolvePath)
+		if err != nil {
+			log.Fatalf("%s: %v", *resolvePath, err)
+		}
+	}
+
+	db, err := sql.Open("sqlite3", *dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer db.Close()
+
+	models := database.NewModels(db)
+
+	series, err := models.Series.GetBySlug(*seriesSlug)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if series == nil {
+		log.Fatalf("there is no %s series", *seriesSlug)
+	}
+	for _, row := range rows {
+		if !series.HasClass(row.Class) {
+			log.Fatalf("%s: %s doesn't run a %s class", row.where(), series.Name, row.Class)
+		}
+	}
+
+	rows, skipped, err := skipUnofficial(models, series.Id, rows)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, event := range skipped {
+		log.Printf("skipping %d round %d: %s (event %d) is %s, not official", event.Season, event.Round, event.Name, event.Id, event.Status)
+	}
+
+	existing, err := models.Riders.GetAll(0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	archive := collectRiders(rows)
+	if err := matchRiders(archive, existing, resolutions, *threshold); err != nil {
+		log.Fatal(err)
+	}
+
+	var report io.Writer = os.Stdout
+	if *reportPath != "" {
+		f, err := os.Create(*reportPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		report = f
+	}
+	if err := writeReport(report, archive); err != nil {
+		log.Fatal(err)
+	}
+
+	decisions := map[string]int{}
+	for _, a := range archive {
+		decisions[a.Decision]++
+	}
+	log.Printf("%d rows, %d riders: %d exact, %d fuzzy, %d resolved, %d new, %d ambiguous", len(rows), len(archive),
+		decisions[decisionExact], decisions[decisionFuzzy], decisions[decisionResolved], decisions[decisionNew], decisions[decisionAmbiguous])
+
+	if !*commit {
+		return
+	}
+	if decisions[decisionAmbiguous] > 0 {
+		log.Fatalf("%d riders are ambiguous; settle them in the report and pass it back with -resolve", decisions[decisionAmbiguous])
+	}
+
+	im := &importer{series: series, counts: map[string]*count{}}
+	err = models.Transaction(func(tx database.Models) error {
+		im.models = tx
+		return im.load(archive)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for _, table := range []string{"tracks", "events", "riders", "results"} {
+		if c, ok := im.counts[table]; ok {
+			fmt.Fprintf(os.Stderr, "%-8s %d created, %d updated\n", table, c.created, c.updated)
+		}
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"github.com/bcantrell1/pro-motocross-api/internal/match"
+)
+
+// How an archive rider was tied to a rider in the database.
+const (
+	decisionExact     = "exact"
+	decisionFuzzy     = "fuzzy"
+	decisionResolved  = "resolved"
+	decisionNew       = "new"
+	decisionAmbiguous = "ambiguous"
+)
+
+// numberBonus is added to a candidate's score when they race under a
+// number the archive rider used, enough to settle a close call but not to
+// make a poor name match good.
+const numberBonus = 0.05
+
+// margin is how far the best candidate must lead the next before a fuzzy
+// match is taken without review.
+const margin = 0.05
+
+// reportCandidates is how many candidates the report lists per rider.
+const reportCandidates = 3
+
+// archiveRider is everyone on the sheets written under one name, give or
+// take case, accents and punctuation.
+type archiveRider struct {
+	Name       string
+	Key        string
+	Numbers    []int
+	Rows       []*sheetRow
+	Latest     *sheetRow
+	Decision   string
+	RiderId    int
+	Candidates []candidate
+}
+
+// candidate is a rider an archive rider might be: one in the database, or
+// another new rider on the sheets whose name is spelled almost the same.
+type candidate struct {
+	Rider   *database.Rider
+	Archive *archiveRider
+	Score   float64
+}
+
+// collectRiders groups the sheet rows by rider name, in the order the
+// riders first appear.
+func collectRiders(rows []*sheetRow) []*archiveRider {
+	byKey := map[string]*archiveRider{}
+	var riders []*archiveRider
+
+	for _, row := range rows {
+		key := match.Normalize(row.Rider)
+		rider, ok := byKey[key]
+		if !ok {
+			rider = &archiveRider{Name: row.Rider, Key: key}
+			byKey[key] = rider
+			riders = append(riders, rider)
+		}
+
+		rider.Rows = append(rider.Rows, row)
+		if !containsInt(rider.Numbers, row.Number) {
+			rider.Numbers = append(rider.Numbers, row.Number)
+		}
+		if rider.Latest == nil || row.Season > rider.Latest.Season || (row.Season == rider.Latest.Season && row.Round > rider.Latest.Round) {
+			rider.Latest = row
+		}
+	}
+
+	return riders
+}
+
+// matchRiders decides which rider in the database each archive rider is.
+// A resolution, when there is one, wins. Otherwise a single rider with the
+// same name is taken, using the number to pick between riders who share a
+// name. Failing that the best fuzzy match is taken if it clears the
+// threshold, is clearly ahead of the rest and raced a number the archive
+// rider did; riders with nothing close are new, and anything else is left
+// for review. So are new riders whose
+// name is close to another new rider's, which is usually a misspelling
+// better fixed on the sheet.
+func matchRiders(archive []*archiveRider, existing []*database.Rider, resolutions map[string]string, threshold float64) error {
+	byId := map[int]*database.Rider{}
+	for _, rider := range existing {
+		byId[rider.Id] = rider
+	}
+
+	for _, a := range archive {
+		a.Candidates = nil
+		for _, rider := range existing {
+			score := match.Similarity(a.Name, rider.FirstName+" "+rider.LastName)
+			if containsInt(a.Numbers, rider.Number) {
+				score = min(1, score+numberBonus)
+			}
+			if score >= threshold {
+				a.Candidates = append(a.Candidates, candidate{Rider: rider, Score: score})
+			}
+		}
+		sort.SliceStable(a.Candidates, func(i, j int) bool { return a.Candidates[i].Score > a.Candidates[j].Score })
+
+		if resolution, ok := resolutions[a.Key]; ok {
+			if resolution == decisionNew {
+				a.Decision, a.RiderId = decisionNew, 0
+				continue
+			}
+			id, err := strconv.Atoi(resolution)
+			if err != nil || byId[id] == nil {
+				return fmt.Errorf("the resolution for %s names rider %q, which doesn't exist", a.Name, resolution)
+			}
+			a.Decision, a.RiderId = decisionResolved, id
+			continue
+		}
+
+		var exact []*database.Rider
+		for _, rider := range existing {
+			if match.Normalize(rider.FirstName+" "+rider.LastName) == a.Key {
+				exact = append(exact, rider)
+			}
+		}
+		if len(exact) > 1 {
+			var numbered []*database.Rider
+			for _, rider := range exact {
+				if containsInt(a.Numbers, rider.Number) {
+					numbered = append(numbered, rider)
+				}
+			}
+			exact = numbered
+		}
+
+		switch {
+		case len(exact) == 1:
+			a.Decision, a.RiderId = decisionExact, exact[0].Id
+		case len(a.Candidates) == 0:
+			a.Decision, a.RiderId = decisionNew, 0
+		case containsInt(a.Numbers, a.Candidates[0].Rider.Number) && (len(a.Candidates) == 1 || a.Candidates[0].Score-a.Candidates[1].Score >= margin):
+			a.Decision, a.RiderId = decisionFuzzy, a.Candidates[0].Rider.Id
+		default:
+			a.Decision, a.RiderId = decisionAmbiguous, 0
+		}
+	}
+
+	var created []*archiveRider
+	for _, a := range archive {
+		if a.Decision != decisionNew {
+			continue
+		}
+		if _, resolved := resolutions[a.Key]; !resolved {
+			for _, other := range created {
+				if score := match.Similarity(a.Name, other.Name); score >= threshold {
+					a.Candidates = append(a.Candidates, candidate{Archive: other, Score: score})
+					a.Decision = decisionAmbiguous
+				}
+			}
+		}
+		created = append(created, a)
+	}
+
+	return nil
+}
+
+// readResolutions reads a reviewed report: the riderId column settles each
+// rider it is filled in for, with a rider's Id or "new".
+func readResolutions(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	riderColumn, idColumn := -1, -1
+	for i, name := range header {
+		switch strings.TrimSpace(name) {
+		case "rider":
+			riderColumn = i
+		case "riderId":
+			idColumn = i
+		}
+	}
+	if riderColumn < 0 || idColumn < 0 {
+		return nil, errors.New("resolutions need a rider and a riderId column")
+	}
+
+	resolutions := map[string]string{}
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if max(riderColumn, idColumn) >= len(record) {
+			continue
+		}
+
+		id := strings.ToLower(strings.TrimSpace(record[idColumn]))
+		if id != "" {
+			resolutions[match.Normalize(record[riderColumn])] = id
+		}
+	}
+
+	return resolutions, nil
+}
+
+// writeReport lists how every archive rider was matched, ambiguous riders
+// first. Filling in riderId for those and passing the report back with
+// -resolve settles them.
+func writeReport(w io.Writer, archive []*archiveRider) error {
+	sorted := append([]*archiveRider{}, archive...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Decision == decisionAmbiguous && sorted[j].Decision != decisionAmbiguous
+	})
+
+	out := csv.NewWriter(w)
+	if err := out.Write([]string{"rider", "numbers", "rows", "decision", "riderId", "candidates"}); err != nil {
+		return err
+	}
+
+	for _, a := range sorted {
+		numbers := make([]string, len(a.Numbers))
+		for i, number := range a.Numbers {
+			numbers[i] = strconv.Itoa(number)
+		}
+
+		riderId := ""
+		switch {
+		case a.RiderId != 0:
+			riderId = strconv.Itoa(a.RiderId)
+		case a.Decision == decisionNew:
+			riderId = decisionNew
+		}
+
+		var candidates []string
+		for _, c := range a.Candidates[:min(reportCandidates, len(a.Candidates))] {
+			if c.Archive != nil {
+				candidates = append(candidates, fmt.Sprintf("new rider %s on %s (%.2f)", c.Archive.Name, c.Archive.Rows[0].where(), c.Score))
+				continue
+			}
+			candidates = append(candidates, fmt.Sprintf("%d #%d %s %s (%.2f)", c.Rider.Id, c.Rider.Number, c.Rider.FirstName, c.Rider.LastName, c.Score))
+		}
+
+		record := []string{a.Name, strings.Join(numbers, " "), strconv.Itoa(len(a.Rows)), a.Decision, riderId, strings.Join(candidates, "; ")}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+func containsInt(values []int, value int) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
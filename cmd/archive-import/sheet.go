@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+)
+
+// sheetColumns are the columns an archive sheet must have. Bike and date
+// are optional.
+var sheetColumns = []string{"season", "round", "track", "class", "moto", "position", "rider", "number"}
+
+// sheetRow is one rider's finish in a moto, as written on the sheet.
+// Non-finishers have no position until the moto is classified.
+type sheetRow struct {
+	Sheet    string
+	Line     int
+	Season   int
+	Round    int
+	Track    string
+	Class    string
+	Moto     int
+	Position int
+	Status   string
+	Rider    string
+	Number   int
+	Bike     string
+	Date     string
+}
+
+type roundKey struct {
+	Season int
+	Round  int
+}
+
+type motoKey struct {
+	roundKey
+	Class string
+	Moto  int
+}
+
+// readSheets reads every archive sheet into one list of rows, checking each
+// row on its own and that rows agree on where each round was raced. Every
+// problem is reported together so they can all be fixed in one go.
+func readSheets(paths []string) ([]*sheetRow, error) {
+	var rows []*sheetRow
+	var problems []string
+
+	for _, path := range paths {
+		sheet, bad, err := readSheet(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		rows = append(rows, sheet...)
+		problems = append(problems, bad...)
+	}
+
+	problems = append(problems, checkRounds(rows)...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("the archive has problems:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	return rows, nil
+}
+
+// where is the sheet and line a row came from, for pointing at problems.
+func (row *sheetRow) where() string {
+	return fmt.Sprintf("%s:%d", row.Sheet, row.Line)
+}
+
+// readSheet reads the rows of one sheet, along with the problems with any
+// rows it couldn't read. The error is for a sheet that can't be read at all.
+func readSheet(path string) ([]*sheetRow, []string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New("the sheet is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range sheetColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, nil, fmt.Errorf("the sheet has no %s column", name)
+		}
+	}
+
+	var rows []*sheetRow
+	var problems []string
+
+	for line := 2; ; line++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		row, err := parseRow(line, field)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s:%d: %v", path, line, err))
+			continue
+		}
+		row.Sheet = path
+		rows = append(rows, row)
+	}
+
+	return rows, problems, nil
+}
+
+func parseRow(line int, field func(string) string) (*sheetRow, error) {
+	row := &sheetRow{
+		Line:   line,
+		Track:  field("track"),
+		Class:  field("class"),
+		Rider:  strings.Join(strings.Fields(field("rider")), " "),
+		Bike:   field("bike"),
+		Date:   field("date"),
+		Status: database.ResultFinished,
+	}
+
+	numbers := []struct {
+		name  string
+		value *int
+	}{
+		{"season", &row.Season},
+		{"round", &row.Round},
+		{"moto", &row.Moto},
+		{"number", &row.Number},
+	}
+	for _, n := range numbers {
+		value, err := strconv.Atoi(field(n.name))
+		if err != nil || value < 1 {
+			return nil, fmt.Errorf("%s %q is not a number above 0", n.name, field(n.name))
+		}
+		*n.value = value
+	}
+
+	if row.Number > database.MaxRiderNumber {
+		return nil, fmt.Errorf("number %d is above %d", row.Number, database.MaxRiderNumber)
+	}
+	if row.Track == "" || row.Class == "" || row.Rider == "" {
+		return nil, errors.New("track, class and rider can't be blank")
+	}
+	if row.Date != "" {
+		if _, err := time.Parse("2006-01-02", row.Date); err != nil {
+			return nil, fmt.Errorf("date %q is not in YYYY-MM-DD form", row.Date)
+		}
+	}
+
+	position := field("position")
+	switch strings.ToLower(position) {
+	case database.ResultDNF, database.ResultDNS, database.ResultDQ:
+		row.Status = strings.ToLower(position)
+	default:
+		value, err := strconv.Atoi(position)
+		if err != nil || value < 1 {
+			return nil, fmt.Errorf("position %q is not a finishing position, DNF, DNS or DQ", position)
+		}
+		row.Position = value
+	}
+
+	return row, nil
+}
+
+// checkRounds makes sure every row of a round names the same track and
+// date, and no two riders share a finishing position in a moto.
+func checkRounds(rows []*sheetRow) []string {
+	var problems []string
+
+	rounds := map[roundKey]*sheetRow{}
+	positions := map[motoKey]map[int]*sheetRow{}
+
+	for _, row := range rows {
+		key := roundKey{row.Season, row.Round}
+		if first, ok := rounds[key]; !ok {
+			rounds[key] = row
+		} else if !strings.EqualFold(first.Track, row.Track) {
+			problems = append(problems, fmt.Sprintf("%s: %d round %d is at %s, but %s has it at %s", row.where(), row.Season, row.Round, row.Track, first.where(), first.Track))
+		} else if first.Date == "" {
+			rounds[key] = row
+		} else if row.Date != "" && row.Date != first.Date {
+			problems = append(problems, fmt.Sprintf("%s: %d round %d is dated %s, but %s dates it %s", row.where(), row.Season, row.Round, row.Date, first.where(), first.Date))
+		}
+
+		if row.Status != database.ResultFinished {
+			continue
+		}
+		moto := motoKey{key, row.Class, row.Moto}
+		if positions[moto] == nil {
+			positions[moto] = map[int]*sheetRow{}
+		}
+		if taken, ok := positions[moto][row.Position]; ok {
+			problems = append(problems, fmt.Sprintf("%s: position %d in %d round %d %s moto %d is already taken on %s", row.where(), row.Position, row.Season, row.Round, row.Class, row.Moto, taken.where()))
+			continue
+		}
+		positions[moto][row.Position] = row
+	}
+
+	return problems
+}
@@ -2,54 +2,298 @@ package main
 
 import (
 	"database/sql"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 
+	"github.com/bcantrell1/pro-motocross-api/cmd/migrate/migrations"
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
 	"github.com/golang-migrate/migrate"
-	"github.com/golang-migrate/migrate/database/sqlite3"
-	"github.com/golang-migrate/migrate/source/file"
 )
 
-func main() {
+const usage = `Usage: migrate [--dry-run] <command>
+
+Commands:
+  up                  apply all pending migrations
+  down                roll back all migrations
+  status              show the current and pending migrations
+  goto N              migrate up or down to version N
+  force N             set the version to N without running migrations
+  create NAME         write a new pair of empty migration files
+  grant-admin EMAIL   make a user an administrator
+  revoke-admin EMAIL  take a user's administrator rights away`
 
-	if len(os.Args) < 2 {
-		log.Fatal("Provide a migrations direction: 'up' or 'down'")
+func main() {
+	args, dryRun := parseArgs(os.Args[1:])
+	if len(args) < 1 {
+		log.Fatal(usage)
 	}
 
-	direction := os.Args[1]
+	command := args[0]
 
-	db, err := sql.Open("sqlite3", "./data.db")
-	if err != nil {
-		log.Fatal(err)
+	if command == "create" {
+		if len(args) < 2 {
+			log.Fatal("Provide a name for the migration: 'create add_teams_table'")
+		}
+		if err := create(args[1], dryRun); err != nil {
+			log.Fatal(err)
+		}
+		return
 	}
 
-	defer db.Close()
-
-	instance, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+	db, err := sql.Open("sqlite3", "./data.db")
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fSrc, err := (&file.File{}).Open("cmd/migrate/migrations")
+	m, err := migrations.New(db)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	m, err := migrate.NewWithInstance("file", fSrc, "sqlite3", instance)
+	defer m.Close()
+
+	current, dirty, err := version(m)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	switch direction {
+	switch command {
 	case "up":
+		latest, err := migrations.Latest()
+		if err != nil {
+			log.Fatal(err)
+		}
+		if dryRun {
+			printPlan(current, int(latest))
+			return
+		}
 		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
 			log.Fatal(err)
 		}
 	case "down":
+		if dryRun {
+			printPlan(current, -1)
+			return
+		}
 		if err := m.Down(); err != nil && err != migrate.ErrNoChange {
 			log.Fatal(err)
 		}
+	case "status":
+		if err := printStatus(current, dirty); err != nil {
+			log.Fatal(err)
+		}
+	case "goto":
+		target := versionArg(args)
+		if target < 1 {
+			log.Fatal("Version must be a positive number, use 'down' to roll back everything.")
+		}
+		if dryRun {
+			printPlan(current, target)
+			return
+		}
+		if err := m.Migrate(uint(target)); err != nil && err != migrate.ErrNoChange {
+			log.Fatal(err)
+		}
+	case "force":
+		target := versionArg(args)
+		if target < -1 {
+			log.Fatal("Version must be -1 or greater.")
+		}
+		if dryRun {
+			fmt.Printf("Would force the version from %d to %d\n", current, target)
+			return
+		}
+		if err := m.Force(target); err != nil {
+			log.Fatal(err)
+		}
+	case "grant-admin", "revoke-admin":
+		if err := setAdmin(db, args, command == "grant-admin", dryRun); err != nil {
+			log.Fatal(err)
+		}
 	default:
-		log.Fatal("Only 'up' and 'down' are valid directions.")
+		log.Fatal(usage)
+	}
+}
+
+// setAdmin grants or revokes a user's administrator rights. The users table
+// must be migrated far enough to have them.
+func setAdmin(db *sql.DB, args []string, admin, dryRun bool) error {
+	if len(args) < 2 {
+		return fmt.Errorf("Provide the user's email: '%s rider@example.com'", args[0])
+	}
+	email := args[1]
+
+	verb := "grant"
+	if !admin {
+		verb = "revoke"
+	}
+
+	if dryRun {
+		fmt.Printf("Would %s administrator rights for %s\n", verb, email)
+		return nil
+	}
+
+	users := database.NewModels(db).Users
+	found, err := users.SetAdmin(email, admin)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("no user with the email %s", email)
+	}
+
+	if admin {
+		fmt.Println(email, "is now an administrator")
+	} else {
+		fmt.Println(email, "is no longer an administrator")
+	}
+	return nil
+}
+
+// parseArgs pulls the --dry-run flag out of the arguments so it can be given
+// before or after the command.
+func parseArgs(args []string) ([]string, bool) {
+	var rest []string
+	dryRun := false
+
+	for _, arg := range args {
+		if arg == "--dry-run" || arg == "-dry-run" {
+			dryRun = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	return rest, dryRun
+}
+
+func versionArg(args []string) int {
+	if len(args) < 2 {
+		log.Fatalf("Provide a version: '%s 5'", args[0])
+	}
+
+	target, err := strconv.Atoi(args[1])
+	if err != nil {
+		log.Fatalf("Invalid version %q.", args[1])
+	}
+
+	return target
+}
+
+// version returns the applied version, or -1 when nothing has been applied.
+func version(m *migrate.Migrate) (int, bool, error) {
+	v, dirty, err := m.Version()
+	if err == migrate.ErrNilVersion {
+		return -1, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return int(v), dirty, nil
+}
+
+func printStatus(current int, dirty bool) error {
+	list, err := migrations.List()
+	if err != nil {
+		return err
 	}
+
+	latest, err := migrations.Latest()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Current version: %d\n", current)
+	fmt.Printf("Latest version:  %d\n", latest)
+	fmt.Printf("Dirty:           %t\n\n", dirty)
+
+	for _, migration := range list {
+		mark := " "
+		if int(migration.Version) <= current {
+			mark = "x"
+		}
+		fmt.Printf("  [%s] %06d %s\n", mark, migration.Version, migration.Name)
+	}
+
+	if current > int(latest) {
+		fmt.Println("\nThe database is newer than the migrations in this binary.")
+	}
+
+	return nil
+}
+
+// printPlan lists the migrations that would run to get from current to
+// target without touching the database.
+func printPlan(current, target int) {
+	list, err := migrations.List()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var steps []string
+	if target >= current {
+		for _, migration := range list {
+			v := int(migration.Version)
+			if v > current && v <= target {
+				steps = append(steps, fmt.Sprintf("%06d_%s.up.sql", v, migration.Name))
+			}
+		}
+	} else {
+		for i := len(list) - 1; i >= 0; i-- {
+			v := int(list[i].Version)
+			if v <= current && v > target {
+				steps = append(steps, fmt.Sprintf("%06d_%s.down.sql", v, list[i].Name))
+			}
+		}
+	}
+
+	if len(steps) == 0 {
+		fmt.Println("No change.")
+		return
+	}
+
+	for _, step := range steps {
+		fmt.Println("Would apply", step)
+	}
+}
+
+var nameSanitizer = regexp.MustCompile(`[^a-z0-9]+`)
+
+func create(name string, dryRun bool) error {
+	name = strings.Trim(nameSanitizer.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return fmt.Errorf("invalid migration name")
+	}
+
+	// Number from the files on disk rather than those built into this
+	// binary, which may not have the latest migrations.
+	latest, err := migrations.LatestIn(migrations.Dir)
+	if err != nil {
+		return err
+	}
+
+	for _, direction := range []string{"up", "down"} {
+		path := filepath.Join(migrations.Dir, fmt.Sprintf("%06d_%s.%s.sql", latest+1, name, direction))
+
+		if dryRun {
+			fmt.Println("Would create", path)
+			continue
+		}
+
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err != nil {
+			return err
+		}
+		file.Close()
+
+		fmt.Println("Created", path)
+	}
+
+	return nil
 }
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS tracks;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS tracks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE,
+	location TEXT NOT NULL,
+	surface TEXT
+);
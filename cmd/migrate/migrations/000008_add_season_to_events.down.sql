@@ -0,0 +1,3 @@
+ALTER TABLE events DROP COLUMN track_id;
+ALTER TABLE events DROP COLUMN round;
+ALTER TABLE events DROP COLUMN season;
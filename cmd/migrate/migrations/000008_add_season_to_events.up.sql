@@ -0,0 +1,4 @@
+ALTER TABLE events ADD COLUMN season INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE events ADD COLUMN round INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE events ADD COLUMN track_id INTEGER REFERENCES tracks (id) ON DELETE SET NULL;
+UPDATE events SET season = CAST(strftime('%Y', date) AS INTEGER);
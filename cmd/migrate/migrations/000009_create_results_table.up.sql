@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	rider_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	moto INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	points INTEGER NOT NULL DEFAULT 0,
+	status TEXT NOT NULL DEFAULT 'finished',
+	team TEXT,
+	bike_brand TEXT,
+	UNIQUE (event_id, rider_id, class, moto),
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE
+);
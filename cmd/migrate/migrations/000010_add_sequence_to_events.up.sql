@@ -0,0 +1 @@
+ALTER TABLE events ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_laps_moto;
+DROP TABLE IF EXISTS laps;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS laps (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	rider_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	moto INTEGER NOT NULL,
+	lap INTEGER NOT NULL,
+	lap_time_ms INTEGER NOT NULL,
+	sectors_ms TEXT NOT NULL DEFAULT '[]',
+	position INTEGER NOT NULL DEFAULT 0,
+	UNIQUE (event_id, rider_id, class, moto, lap),
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_laps_moto ON laps (event_id, moto, class);
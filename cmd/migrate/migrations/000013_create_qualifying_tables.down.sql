@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS qualifying_results;
+DROP TABLE IF EXISTS qualifying_sessions;
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS qualifying_sessions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	number INTEGER NOT NULL,
+	name TEXT NOT NULL,
+	UNIQUE (event_id, class, number),
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS qualifying_results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id INTEGER NOT NULL,
+	rider_id INTEGER NOT NULL,
+	best_lap_ms INTEGER NOT NULL,
+	UNIQUE (session_id, rider_id),
+	FOREIGN KEY (session_id) REFERENCES qualifying_sessions (id) ON DELETE CASCADE,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS laps_led;
+DROP TABLE IF EXISTS holeshots;
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS holeshots (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	moto INTEGER NOT NULL,
+	rider_id INTEGER NOT NULL,
+	source TEXT NOT NULL DEFAULT 'manual',
+	UNIQUE (event_id, class, moto),
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS laps_led (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	moto INTEGER NOT NULL,
+	rider_id INTEGER NOT NULL,
+	laps INTEGER NOT NULL,
+	source TEXT NOT NULL DEFAULT 'manual',
+	UNIQUE (event_id, class, moto, rider_id),
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE
+);
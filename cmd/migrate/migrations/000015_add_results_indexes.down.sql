@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_events_season;
+DROP INDEX IF EXISTS idx_results_event_class_moto;
+DROP INDEX IF EXISTS idx_results_rider;
@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_results_rider ON results (rider_id);
+CREATE INDEX IF NOT EXISTS idx_results_event_class_moto ON results (event_id, class, moto);
+CREATE INDEX IF NOT EXISTS idx_events_season ON events (season);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS result_revisions;
+DROP TABLE IF EXISTS penalties;
+DROP TABLE IF EXISTS protests;
+ALTER TABLE results DROP COLUMN finish_status;
+ALTER TABLE results DROP COLUMN finish_position;
@@ -0,0 +1,57 @@
+ALTER TABLE results ADD COLUMN finish_position INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE results ADD COLUMN finish_status TEXT NOT NULL DEFAULT 'finished';
+UPDATE results SET finish_position = position, finish_status = status;
+
+CREATE TABLE IF NOT EXISTS protests (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	moto INTEGER NOT NULL,
+	rider_id INTEGER,
+	filed_by INTEGER NOT NULL,
+	reason TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'filed',
+	decision TEXT NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE SET NULL,
+	FOREIGN KEY (filed_by) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS penalties (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	moto INTEGER NOT NULL,
+	rider_id INTEGER NOT NULL,
+	type TEXT NOT NULL,
+	value INTEGER NOT NULL DEFAULT 0,
+	reason TEXT NOT NULL,
+	issued_by INTEGER NOT NULL,
+	protest_id INTEGER,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	rescinded_at DATETIME,
+	rescinded_by INTEGER,
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE,
+	FOREIGN KEY (issued_by) REFERENCES users (id),
+	FOREIGN KEY (protest_id) REFERENCES protests (id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_penalties_moto ON penalties (event_id, class, moto);
+
+CREATE TABLE IF NOT EXISTS result_revisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	moto INTEGER NOT NULL,
+	revision INTEGER NOT NULL,
+	state TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	results TEXT NOT NULL,
+	created_by INTEGER,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (event_id, class, moto, revision),
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE
+);
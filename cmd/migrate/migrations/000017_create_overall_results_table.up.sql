@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS overall_results (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	event_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	rider_id INTEGER NOT NULL,
+	position INTEGER NOT NULL,
+	motos INTEGER NOT NULL,
+	combined INTEGER NOT NULL,
+	points INTEGER NOT NULL,
+	UNIQUE (event_id, class, rider_id),
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE
+);
+
+INSERT INTO overall_results (event_id, class, rider_id, position, motos, combined, points)
+SELECT event_id, class, rider_id,
+	ROW_NUMBER() OVER (PARTITION BY event_id, class ORDER BY motos DESC, combined, last_moto, rider_id),
+	motos, combined, points
+FROM (
+	SELECT res.event_id, res.class, res.rider_id, COUNT(*) AS motos, SUM(res.position) AS combined, SUM(res.points) AS points,
+		COALESCE((
+			SELECT last.position FROM results last
+			WHERE last.event_id = res.event_id AND last.class = res.class AND last.rider_id = res.rider_id
+				AND last.moto = (SELECT MAX(m.moto) FROM results m WHERE m.event_id = res.event_id AND m.class = res.class)
+		), 999) AS last_moto
+	FROM results res
+	GROUP BY res.event_id, res.class, res.rider_id
+);
@@ -0,0 +1,2 @@
+ALTER TABLE events DROP COLUMN original_date;
+ALTER TABLE events DROP COLUMN status;
@@ -0,0 +1,3 @@
+ALTER TABLE events ADD COLUMN status TEXT NOT NULL DEFAULT 'scheduled';
+ALTER TABLE events ADD COLUMN original_date DATETIME;
+UPDATE events SET status = 'official' WHERE EXISTS (SELECT 1 FROM results WHERE results.event_id = events.id);
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS event_capacities;
+DROP INDEX IF EXISTS idx_attendees_event_class;
+ALTER TABLE attendees DROP COLUMN withdrawn_at;
+ALTER TABLE attendees DROP COLUMN confirmed_at;
+ALTER TABLE attendees DROP COLUMN waitlisted_at;
+ALTER TABLE attendees DROP COLUMN entered_at;
+ALTER TABLE attendees DROP COLUMN created_at;
+ALTER TABLE attendees DROP COLUMN status;
+ALTER TABLE attendees DROP COLUMN class;
@@ -0,0 +1,21 @@
+ALTER TABLE attendees ADD COLUMN class TEXT NOT NULL DEFAULT '';
+ALTER TABLE attendees ADD COLUMN status TEXT NOT NULL DEFAULT 'entered';
+ALTER TABLE attendees ADD COLUMN created_at DATETIME;
+ALTER TABLE attendees ADD COLUMN entered_at DATETIME;
+ALTER TABLE attendees ADD COLUMN waitlisted_at DATETIME;
+ALTER TABLE attendees ADD COLUMN confirmed_at DATETIME;
+ALTER TABLE attendees ADD COLUMN withdrawn_at DATETIME;
+UPDATE attendees SET
+	class = COALESCE((SELECT r.class FROM riders r WHERE r.id = attendees.rider_id), ''),
+	created_at = CURRENT_TIMESTAMP,
+	entered_at = CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_attendees_event_class ON attendees (event_id, class, status);
+
+CREATE TABLE IF NOT EXISTS event_capacities (
+	event_id INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	capacity INTEGER NOT NULL,
+	PRIMARY KEY (event_id, class),
+	FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE
+);
@@ -0,0 +1,6 @@
+DELETE FROM attendees WHERE status IN ('pending', 'rejected');
+ALTER TABLE attendees DROP COLUMN reason;
+ALTER TABLE attendees DROP COLUMN rejected_at;
+ALTER TABLE attendees DROP COLUMN decided_at;
+ALTER TABLE attendees DROP COLUMN decided_by;
+ALTER TABLE attendees DROP COLUMN requested_by;
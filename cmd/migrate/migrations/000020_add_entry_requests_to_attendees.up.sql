@@ -0,0 +1,5 @@
+ALTER TABLE attendees ADD COLUMN requested_by INTEGER REFERENCES users (id);
+ALTER TABLE attendees ADD COLUMN decided_by INTEGER REFERENCES users (id);
+ALTER TABLE attendees ADD COLUMN decided_at DATETIME;
+ALTER TABLE attendees ADD COLUMN rejected_at DATETIME;
+ALTER TABLE attendees ADD COLUMN reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS rider_numbers (
+	season INTEGER NOT NULL,
+	class TEXT NOT NULL,
+	number INTEGER NOT NULL,
+	rider_id INTEGER NOT NULL,
+	assigned_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (season, class, number),
+	UNIQUE (season, rider_id),
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE
+);
+
+INSERT OR IGNORE INTO rider_numbers (season, class, number, rider_id)
+SELECT DISTINCT e.season, res.class, r.number, r.id
+FROM results res
+JOIN events e ON e.id = res.event_id
+JOIN riders r ON r.id = res.rider_id
+WHERE r.number BETWEEN 1 AND 999
+ORDER BY e.season, res.class, r.id;
+
+INSERT OR IGNORE INTO rider_numbers (season, class, number, rider_id)
+SELECT COALESCE((SELECT MAX(season) FROM events), CAST(strftime('%Y', 'now') AS INTEGER)), r.class, r.number, r.id
+FROM riders r
+WHERE r.class != '' AND r.number BETWEEN 1 AND 999
+ORDER BY r.id;
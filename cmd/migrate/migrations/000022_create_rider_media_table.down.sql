@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_rider_media_rider;
+DROP TABLE IF EXISTS rider_media;
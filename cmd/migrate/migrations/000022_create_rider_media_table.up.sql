@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS rider_media (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	rider_id INTEGER NOT NULL,
+	kind TEXT NOT NULL,
+	blob_key TEXT NOT NULL UNIQUE,
+	content_type TEXT NOT NULL,
+	size_bytes INTEGER NOT NULL,
+	width INTEGER NOT NULL,
+	height INTEGER NOT NULL,
+	caption TEXT NOT NULL DEFAULT '',
+	uploaded_by INTEGER NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (rider_id) REFERENCES riders (id) ON DELETE CASCADE,
+	FOREIGN KEY (uploaded_by) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rider_media_rider ON rider_media (rider_id, kind);
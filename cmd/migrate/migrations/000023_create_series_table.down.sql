@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_events_series_season;
+CREATE INDEX IF NOT EXISTS idx_events_season ON events (season);
+ALTER TABLE events DROP COLUMN series_id;
+DROP TABLE IF EXISTS series;
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS series (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	owner_id INTEGER,
+	slug TEXT NOT NULL UNIQUE,
+	name TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	classes TEXT NOT NULL DEFAULT '[]',
+	points TEXT NOT NULL DEFAULT '[]',
+	motos_per_event INTEGER NOT NULL DEFAULT 2,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (owner_id) REFERENCES users (id)
+);
+
+INSERT INTO series (id, slug, name, description, classes, points, motos_per_event) VALUES
+	(1, 'pro-motocross', 'Pro Motocross', 'The AMA Pro Motocross Championship, raced outdoors over two motos per class.',
+		'["450","250"]', '[25,22,20,18,16,15,14,13,12,11,10,9,8,7,6,5,4,3,2,1]', 2),
+	(2, 'supercross', 'Supercross', 'The AMA Supercross Championship, decided by one main event per class.',
+		'["450","250"]', '[25,22,20,18,16,15,14,13,12,11,10,9,8,7,6,5,4,3,2,1,1,1]', 1),
+	(3, 'smx', 'SuperMotocross Playoffs', 'The SuperMotocross World Championship playoffs, raced over two motos per class.',
+		'["450","250"]', '[25,22,20,18,16,15,14,13,12,11,10,9,8,7,6,5,4,3,2,1,1,1]', 2);
+
+ALTER TABLE events ADD COLUMN series_id INTEGER NOT NULL DEFAULT 1;
+
+DROP INDEX IF EXISTS idx_events_season;
+CREATE INDEX IF NOT EXISTS idx_events_series_season ON events (series_id, season);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS scoring_rules (
+	series_id INTEGER NOT NULL,
+	season INTEGER NOT NULL DEFAULT 0,
+	rules TEXT NOT NULL,
+	updated_by INTEGER,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (series_id, season),
+	FOREIGN KEY (series_id) REFERENCES series (id) ON DELETE CASCADE,
+	FOREIGN KEY (updated_by) REFERENCES users (id)
+);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS rider_redirects (
+	from_id INTEGER PRIMARY KEY,
+	to_id INTEGER NOT NULL,
+	merged_by INTEGER,
+	merged_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY (to_id) REFERENCES riders (id) ON DELETE CASCADE,
+	FOREIGN KEY (merged_by) REFERENCES users (id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_rider_redirects_to ON rider_redirects (to_id);
@@ -0,0 +1,120 @@
+// Package migrations embeds the SQL migrations into the binaries that need
+// them, so neither the migrate command nor the API depends on the working
+// directory to find the schema.
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/golang-migrate/migrate"
+	"github.com/golang-migrate/migrate/database/sqlite3"
+	"github.com/golang-migrate/migrate/source"
+	bindata "github.com/golang-migrate/migrate/source/go_bindata"
+)
+
+// Dir is where the migration files live relative to the repository root.
+// It is only used when creating new migrations on disk.
+const Dir = "cmd/migrate/migrations"
+
+//go:embed *.sql
+var files embed.FS
+
+type Migration struct {
+	Version uint
+	Name    string
+}
+
+// List returns every embedded migration ordered by version.
+func List() ([]Migration, error) {
+	names, err := fileNames(files)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(names), nil
+}
+
+// LatestIn returns the highest migration version among the files in dir,
+// or 0 when there are none.
+func LatestIn(dir string) (uint, error) {
+	names, err := fileNames(os.DirFS(dir))
+	if err != nil {
+		return 0, err
+	}
+
+	list := parse(names)
+	if len(list) == 0 {
+		return 0, nil
+	}
+
+	return list[len(list)-1].Version, nil
+}
+
+// parse picks the migrations out of file names, ordered by version.
+func parse(names []string) []Migration {
+	seen := map[uint]bool{}
+	var list []Migration
+	for _, name := range names {
+		m, err := source.DefaultParse(name)
+		if err != nil || seen[m.Version] {
+			continue
+		}
+		seen[m.Version] = true
+		list = append(list, Migration{Version: m.Version, Name: m.Identifier})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Version < list[j].Version
+	})
+
+	return list
+}
+
+// Latest returns the highest migration version embedded in the binary.
+func Latest() (uint, error) {
+	list, err := List()
+	if err != nil || len(list) == 0 {
+		return 0, err
+	}
+
+	return list[len(list)-1].Version, nil
+}
+
+// New returns a migrate instance reading from the embedded files and writing
+// to the given database. Closing the instance also closes db.
+func New(db *sql.DB) (*migrate.Migrate, error) {
+	instance, err := sqlite3.WithInstance(db, &sqlite3.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	names, err := fileNames(files)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := bindata.WithInstance(bindata.Resource(names, files.ReadFile))
+	if err != nil {
+		return nil, err
+	}
+
+	return migrate.NewWithInstance("go-bindata", src, "sqlite3", instance)
+}
+
+func fileNames(fsys fs.FS) ([]string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	return names, nil
+}
@@ -0,0 +1,187 @@
+// Command replay feeds a recorded crossing file into the live timing
+// endpoint, either as fast as possible or paced like the original moto.
+//
+// Recordings are CSV with a header of timestamp,riderNumber,loopId. A row on
+// the "gate" loop marks the gate drop and is sent with the next batch.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/env"
+	"github.com/bcantrell1/pro-motocross-api/internal/timing"
+)
+
+const gateLoop = "gate"
+
+type crossingsRequest struct {
+	Class     string            `json:"class"`
+	GateDrop  *time.Time        `json:"gateDrop,omitempty"`
+	Crossings []timing.Crossing `json:"crossings"`
+}
+
+type record struct {
+	crossing timing.Crossing
+	gate     bool
+}
+
+func main() {
+	baseURL := flag.String("url", env.GetEnvString("BASE_URL", "http://localhost:8080"), "API base URL")
+	token := flag.String("token", os.Getenv("API_TOKEN"), "bearer token of the event owner")
+	eventId := flag.Int("event", 0, "event id")
+	moto := flag.Int("moto", 1, "moto number")
+	class := flag.String("class", "450", "class")
+	speed := flag.Float64("speed", 0, "playback speed, 1 is real time and 0 sends without waiting")
+	batch := flag.Int("batch", 20, "most crossings sent per request")
+	flag.Parse()
+
+	if flag.NArg() != 1 || *eventId == 0 || *token == "" {
+		log.Fatal("Usage: replay -event ID -token TOKEN [-moto N] [-class 450] [-speed 1] recording.csv")
+	}
+
+	records, err := readRecording(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/events/%d/motos/%d/crossings", strings.TrimRight(*baseURL, "/"), *eventId, *moto)
+
+	request := crossingsRequest{Class: *class}
+	var last time.Time
+
+	send := func() {
+		if len(request.Crossings) == 0 && request.GateDrop == nil {
+			return
+		}
+		board, err := post(url, *token, request)
+		if err != nil {
+			log.Fatal(err)
+		}
+		printBoard(board)
+		request = crossingsRequest{Class: *class}
+	}
+
+	for _, r := range records {
+		ts := r.crossing.Timestamp
+		if *speed > 0 && !last.IsZero() && ts.After(last) {
+			send()
+			time.Sleep(time.Duration(float64(ts.Sub(last)) / *speed))
+		}
+		last = ts
+
+		if r.gate {
+			gateDrop := ts
+			request.GateDrop = &gateDrop
+			continue
+		}
+
+		request.Crossings = append(request.Crossings, r.crossing)
+		if len(request.Crossings) >= *batch {
+			send()
+		}
+	}
+
+	send()
+}
+
+func readRecording(path string) ([]record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("%s: reading header: %w", path, err)
+	}
+
+	var records []record
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		ts, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+
+		number, err := strconv.Atoi(row[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid rider number %q", path, line, row[1])
+		}
+
+		records = append(records, record{
+			crossing: timing.Crossing{RiderNumber: number, LoopId: row[2], Timestamp: ts},
+			gate:     row[2] == gateLoop,
+		})
+	}
+
+	return records, nil
+}
+
+func post(url, token string, request crossingsRequest) (*timing.Board, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var board timing.Board
+	if err := json.NewDecoder(resp.Body).Decode(&board); err != nil {
+		return nil, err
+	}
+
+	return &board, nil
+}
+
+func printBoard(board *timing.Board) {
+	var parts []string
+	for _, standing := range board.Standings {
+		if len(parts) == 5 {
+			break
+		}
+		parts = append(parts, fmt.Sprintf("P%d #%d L%d", standing.Position, standing.RiderNumber, standing.Laps))
+	}
+	log.Println(strings.Join(parts, "  "))
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+	"gopkg.in/yaml.v3"
+)
+
+// fixtureVersion is the fixture format this command understands. Bump it when
+// the layout changes so old files fail loudly instead of loading half a season.
+const fixtureVersion = 1
+
+//go:embed fixtures/*.yaml
+var builtin embed.FS
+
+type fixture struct {
+	Version int              `json:"version"`
+	Season  int              `json:"season"`
+	Teams   []database.Team  `json:"teams"`
+	Tracks  []database.Track `json:"tracks"`
+	Riders  []database.Rider `json:"riders"`
+	Events  []fixtureEvent   `json:"events"`
+	Results []fixtureMoto    `json:"results"`
+}
+
+type fixtureEvent struct {
+	Round       int    `json:"round"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Date        string `json:"date"`
+	Track       string `json:"track"`
+}
+
+// fixtureMoto lists a moto's classification as rider numbers in finishing
+// order, followed by the riders that did not finish.
+type fixtureMoto struct {
+	Round int    `json:"round"`
+	Class string `json:"class"`
+	Moto  int    `json:"moto"`
+	Order []int  `json:"order"`
+	DNF   []int  `json:"dnf"`
+}
+
+// loadFixture reads a fixture from disk, or from the built-in fixtures when
+// the name has no path and matches one, e.g. "2025".
+func loadFixture(name string) (*fixture, error) {
+	data, err := os.ReadFile(name)
+	if os.IsNotExist(err) && filepath.Base(name) == name {
+		data, err = builtin.ReadFile("fixtures/" + strings.TrimSuffix(name, filepath.Ext(name)) + ".yaml")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is converted to JSON first so both formats share the json tags
+	// already on the database models.
+	if ext := filepath.Ext(name); ext != ".json" {
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	var f fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if f.Version != fixtureVersion {
+		return nil, fmt.Errorf("%s: unsupported fixture version %d, expected %d", name, f.Version, fixtureVersion)
+	}
+
+	if f.Season == 0 {
+		return nil, fmt.Errorf("%s: season is required", name)
+	}
+
+	return &f, nil
+}
@@ -0,0 +1,413 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/database"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// seedEmail owns the seeded riders and events. Its password is not a bcrypt
+// hash, so nobody can log in as it.
+const seedEmail = "seed@pro-motocross.local"
+
+func main() {
+	dbPath := flag.String("db", "./data.db", "path to the SQLite database")
+	reset := flag.Bool("reset", false, "delete the riders, events and results loaded by earlier runs first")
+	flag.Parse()
+
+	names := flag.Args()
+	if len(names) == 0 {
+		names = []string{"2025"}
+	}
+
+	var fixtures []*fixture
+	for _, name := range names {
+		f, err := loadFixture(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fixtures = append(fixtures, f)
+	}
+
+	db, err := sql.Open("sqlite3", *dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	defer db.Close()
+
+	if *reset {
+		if err := resetData(db, fixtures); err != nil {
+			log.Fatal(err)
+		}
+		log.Println("Removed existing seed data")
+	}
+
+	s := &seeder{models: database.NewModels(db), counts: map[string]*count{}}
+
+	if err := s.ensureOwner(); err != nil {
+		log.Fatal(err)
+	}
+
+	for i, f := range fixtures {
+		if err := s.load(f); err != nil {
+			log.Fatalf("%s: %v", names[i], err)
+		}
+	}
+
+	for _, table := range []string{"teams", "tracks", "riders", "events", "results"} {
+		if c, ok := s.counts[table]; ok {
+			fmt.Printf("%-8s %d created, %d updated\n", table, c.created, c.updated)
+		}
+	}
+}
+
+// seedEvents and seedRiders select what the seed user owns.
+const (
+	seedEvents = "SELECT id FROM events WHERE owner_id = $1"
+	seedRiders = "SELECT id FROM riders WHERE owner_id = $1"
+)
+
+// resetQueries delete everything recorded against the seed user's events
+// and riders, then the events and riders themselves. Foreign keys aren't
+// enforced, so every table referencing them is cleared by hand.
+var resetQueries = []string{
+	"DELETE FROM qualifying_results WHERE session_id IN (SELECT id FROM qualifying_sessions WHERE event_id IN (" + seedEvents + ")) OR rider_id IN (" + seedRiders + ")",
+	"DELETE FROM qualifying_sessions WHERE event_id IN (" + seedEvents + ")",
+	"DELETE FROM results WHERE event_id IN (" + seedEvents + ") OR rider_id IN (" + seedRiders + ")",
+	"DELETE FROM overall_results WHERE event_id IN (" + seedEvents + ") OR rider_id IN (" + seedRiders + ")",
+	"DELETE FROM laps WHERE event_id IN (" + seedEvents + ") OR rider_id IN (" + seedRiders + ")",
+	"DELETE FROM holeshots WHERE event_id IN (" + seedEvents + ") OR rider_id IN (" + seedRiders + ")",
+	"DELETE FROM laps_led WHERE event_id IN (" + seedEvents + ") OR rider_id IN (" + seedRiders + ")",
+	"DELETE FROM penalties WHERE event_id IN (" + seedEvents + ") OR rider_id IN (" + seedRiders + ")",
+	"DELETE FROM protests WHERE event_id IN (" + seedEvents + ")",
+	"UPDATE protests SET rider_id = NULL WHERE rider_id IN (" + seedRiders + ")",
+	"DELETE FROM result_revisions WHERE event_id IN (" + seedEvents + ")",
+	"DELETE FROM attendees WHERE event_id IN (" + seedEvents + ") OR rider_id IN (" + seedRiders + ")",
+	"DELETE FROM event_capacities WHERE event_id IN (" + seedEvents + ")",
+	"DELETE FROM rider_numbers WHERE rider_id IN (" + seedRiders + ")",
+	"DELETE FROM rider_media WHERE rider_id IN (" + seedRiders + ")",
+	"DELETE FROM rider_redirects WHERE to_id IN (" + seedRiders + ")",
+	"DELETE FROM scoring_rules WHERE updated_by = $1",
+	"DELETE FROM events WHERE owner_id = $1",
+	"DELETE FROM riders WHERE owner_id = $1",
+}
+
+// resetData removes the seed user's events and riders with everything
+// recorded against them, and the fixtures' teams and tracks that nothing
+// else uses. Other users' data is left alone.
+func resetData(db *sql.DB, fixtures []*fixture) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var ownerId int
+	err = tx.QueryRow("SELECT id FROM users WHERE email = $1", seedEmail).Scan(&ownerId)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, query := range resetQueries {
+		if _, err := tx.Exec(query, ownerId); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range fixtures {
+		for _, track := range f.Tracks {
+			if _, err := tx.Exec("DELETE FROM tracks WHERE name = $1 AND id NOT IN (SELECT track_id FROM events WHERE track_id IS NOT NULL)", track.Name); err != nil {
+				return err
+			}
+		}
+		for _, team := range f.Teams {
+			if _, err := tx.Exec("DELETE FROM teams WHERE name = $1 AND name NOT IN (SELECT team FROM riders WHERE team IS NOT NULL)", team.Name); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+type count struct {
+	created int
+	updated int
+}
+
+type seeder struct {
+	models  database.Models
+	ownerId int
+	counts  map[string]*count
+}
+
+func (s *seeder) track(table string, created bool) {
+	c, ok := s.counts[table]
+	if !ok {
+		c = &count{}
+		s.counts[table] = c
+	}
+	if created {
+		c.created++
+	} else {
+		c.updated++
+	}
+}
+
+func (s *seeder) ensureOwner() error {
+	user, err := s.models.Users.GetByEmail(seedEmail)
+	if err != nil {
+		return err
+	}
+
+	if user == nil {
+		user = &database.User{Email: seedEmail, Name: "Seed Data", Password: "!"}
+		if err := s.models.Users.Insert(user); err != nil {
+			return err
+		}
+	}
+
+	s.ownerId = user.Id
+	return nil
+}
+
+func (s *seeder) load(f *fixture) error {
+	for i := range f.Teams {
+		if err := s.upsertTeam(&f.Teams[i]); err != nil {
+			return err
+		}
+	}
+
+	tracks := map[string]*database.Track{}
+	for i := range f.Tracks {
+		if err := s.upsertTrack(&f.Tracks[i]); err != nil {
+			return err
+		}
+		tracks[f.Tracks[i].Name] = &f.Tracks[i]
+	}
+
+	riders := map[string]*database.Rider{}
+	for i := range f.Riders {
+		if err := s.upsertRider(&f.Riders[i]); err != nil {
+			return err
+		}
+		if err := s.models.Numbers.Assign(f.Season, f.Riders[i].Class, f.Riders[i].Number, f.Riders[i].Id); err != nil {
+			return err
+		}
+		riders[riderKey(f.Riders[i].Class, f.Riders[i].Number)] = &f.Riders[i]
+	}
+
+	events := map[int]*database.Event{}
+	for _, fe := range f.Events {
+		event, err := s.upsertEvent(f.Season, fe, tracks)
+		if err != nil {
+			return err
+		}
+		events[fe.Round] = event
+	}
+
+	touched := map[int]map[string]bool{}
+	for _, moto := range f.Results {
+		event, ok := events[moto.Round]
+		if !ok {
+			return fmt.Errorf("results reference unknown round %d", moto.Round)
+		}
+		if err := s.upsertMoto(event, moto, riders); err != nil {
+			return err
+		}
+		if touched[event.Id] == nil {
+			touched[event.Id] = map[string]bool{}
+		}
+		touched[event.Id][moto.Class] = true
+	}
+
+	// Rounds with results have been run, so their results are official.
+	for _, event := range events {
+		for class := range touched[event.Id] {
+			if err := s.models.Overall.Recompute(event.Id, class); err != nil {
+				return err
+			}
+		}
+
+		if len(touched[event.Id]) == 0 || event.Status == database.EventOfficial {
+			continue
+		}
+		event.Status = database.EventOfficial
+		if err := s.models.Events.UpdateStatus(event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func riderKey(class string, number int) string {
+	return fmt.Sprintf("%s#%d", class, number)
+}
+
+func (s *seeder) upsertTeam(team *database.Team) error {
+	existing, err := s.models.Teams.GetByName(team.Name)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		s.track("teams", true)
+		return s.models.Teams.Insert(team)
+	}
+
+	team.Id = existing.Id
+	s.track("teams", false)
+	return s.models.Teams.Update(team)
+}
+
+func (s *seeder) upsertTrack(track *database.Track) error {
+	existing, err := s.models.Tracks.GetByName(track.Name)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		s.track("tracks", true)
+		return s.models.Tracks.Insert(track)
+	}
+
+	track.Id = existing.Id
+	s.track("tracks", false)
+	return s.models.Tracks.Update(track)
+}
+
+func (s *seeder) upsertRider(rider *database.Rider) error {
+	if rider.Status == "" {
+		rider.Status = "active"
+	}
+
+	existing, err := s.models.Riders.GetByNumberAndName(rider.Number, rider.FirstName, rider.LastName)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		rider.OwnerId = s.ownerId
+		s.track("riders", true)
+		return s.models.Riders.Insert(rider)
+	}
+
+	rider.Id = existing.Id
+	rider.OwnerId = existing.OwnerId
+	rider.CareerPoints = existing.CareerPoints
+	s.track("riders", false)
+	return s.models.Riders.Update(rider)
+}
+
+func (s *seeder) upsertEvent(season int, fe fixtureEvent, tracks map[string]*database.Track) (*database.Event, error) {
+	track, ok := tracks[fe.Track]
+	if !ok {
+		return nil, fmt.Errorf("round %d references unknown track %q", fe.Round, fe.Track)
+	}
+
+	event := &database.Event{
+		Name:        fe.Name,
+		Description: fe.Description,
+		Date:        fe.Date,
+		Location:    track.Location,
+		Season:      season,
+		Round:       fe.Round,
+		TrackId:     &track.Id,
+		SeriesId:    database.DefaultSeriesId,
+	}
+
+	if event.Description == "" {
+		event.Description = fmt.Sprintf("Round %d of the %d Pro Motocross Championship at %s.", fe.Round, season, track.Name)
+	}
+
+	existing, err := s.models.Events.GetBySeasonAndRound(database.DefaultSeriesId, season, fe.Round)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing == nil {
+		event.OwnerId = s.ownerId
+		s.track("events", true)
+		return event, s.models.Events.Insert(event)
+	}
+
+	event.Id = existing.Id
+	event.OwnerId = existing.OwnerId
+	event.Status = existing.Status
+	s.track("events", false)
+	return event, s.models.Events.Update(event)
+}
+
+func (s *seeder) upsertMoto(event *database.Event, moto fixtureMoto, riders map[string]*database.Rider) error {
+	classified := append(append([]int{}, moto.Order...), moto.DNF...)
+
+	for i, number := range classified {
+		rider, ok := riders[riderKey(moto.Class, number)]
+		if !ok {
+			return fmt.Errorf("round %d %s moto %d references unknown rider #%d", moto.Round, moto.Class, moto.Moto, number)
+		}
+
+		result := &database.Result{
+			EventId:   event.Id,
+			RiderId:   rider.Id,
+			Class:     moto.Class,
+			Moto:      moto.Moto,
+			Position:  i + 1,
+			Status:    database.ResultFinished,
+			Team:      rider.Team,
+			BikeBrand: rider.BikeBrand,
+		}
+
+		if i >= len(moto.Order) {
+			result.Status = database.ResultDNF
+		} else {
+			result.Points = database.PointsForPosition(result.Position)
+		}
+
+		if err := s.ensureAttendee(event.Id, rider.Id); err != nil {
+			return err
+		}
+
+		existing, err := s.models.Results.GetByEventRiderMoto(event.Id, rider.Id, moto.Class, moto.Moto)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			s.track("results", true)
+			if err := s.models.Results.Insert(result); err != nil {
+				return err
+			}
+			continue
+		}
+
+		result.Id = existing.Id
+		s.track("results", false)
+		if err := s.models.Results.Update(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *seeder) ensureAttendee(eventId, riderId int) error {
+	existing, err := s.models.Attendees.GetByEventAndAttendee(eventId, riderId)
+	if err != nil || existing != nil {
+		return err
+	}
+
+	_, err = s.models.Attendees.Insert(&database.Attendee{EventId: eventId, RiderId: riderId})
+	return err
+}
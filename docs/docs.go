@@ -17,7 +17,7 @@ const docTemplate = `{
     "paths": {
         "/api/v1/attendees/{id}/events": {
             "get": {
-                "description": "Get a list of events that a rider is attending",
+                "description": "Get a list of events that a rider is attending. A rider merged into another gets the events of the rider they were merged into.",
                 "tags": [
                     "attendees"
                 ],
@@ -56,6 +56,120 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/auth/keys": {
+            "get": {
+                "description": "Lists the current user's keys. Only the first characters of each key are shown.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "List API keys ** Auth Required **",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.APIKey"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve keys",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a key that authenticates as the current user through the X-API-Key header, or the apiKey query parameter on WebSocket upgrades. The key is only returned by this call.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Create an API key ** Auth Required **",
+                "parameters": [
+                    {
+                        "description": "Key name",
+                        "name": "apiKey",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.createAPIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.createAPIKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create the key",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/auth/keys/{id}": {
+            "delete": {
+                "description": "Revokes a key. Open WebSocket connections that authenticated with it stay open until they disconnect.",
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Revoke an API key ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Key ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid key ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Key not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to revoke the key",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/auth/login": {
             "post": {
                 "description": "Authenticate a user and return a JWT token",
@@ -160,9 +274,90 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/calendar.ics": {
+            "get": {
+                "description": "Returns the schedule as an RFC 5545 calendar. Each event keeps the same UID across updates and its SEQUENCE goes up whenever the date changes.",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "Race schedule as iCalendar",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only events in this series, by ID or slug",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only events in this season",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only events with entries in this class, e.g. 450",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar feed"
+                    },
+                    "400": {
+                        "description": "Invalid season",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to build the calendar",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/entries/pending": {
+            "get": {
+                "description": "Returns the pending entry requests for events you own, waiting on you to approve or reject them, and those you made for your riders, waiting on other event owners.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendees"
+                ],
+                "summary": "Get pending entry requests ** Auth Required **",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.entryRequests"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve entry requests",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/events": {
             "get": {
-                "description": "Get a list of all events",
+                "description": "Get a list of all events, optionally in one series",
                 "produces": [
                     "application/json"
                 ],
@@ -170,6 +365,14 @@ const docTemplate = `{
                     "events"
                 ],
                 "summary": "Get all events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only events in this series, by ID or slug",
+                        "name": "series",
+                        "in": "query"
+                    }
+                ],
                 "responses": {
                     "200": {
                         "description": "OK",
@@ -180,6 +383,12 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
                     "500": {
                         "description": "Server failed to get all events",
                         "schema": {
@@ -189,7 +398,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Create a new event with the provided details",
+                "description": "Create a new event with the provided details. Events go in Pro Motocross unless a seriesId is given.",
                 "consumes": [
                     "application/json"
                 ],
@@ -219,7 +428,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request body",
+                        "description": "Invalid request body or series",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -265,7 +474,7 @@ const docTemplate = `{
                 }
             },
             "put": {
-                "description": "Update an existing event with the provided details",
+                "description": "Update an existing event with the provided details. The event stays in its series unless a seriesId is given.",
                 "consumes": [
                     "application/json"
                 ],
@@ -302,7 +511,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid event ID or request body",
+                        "description": "Invalid event ID, request body or series",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -375,7 +584,7 @@ const docTemplate = `{
         },
         "/api/v1/events/{id}/attendees": {
             "get": {
-                "description": "Get a list of riders attending an event",
+                "description": "Get a list of riders attending an event, with class set to the class each is entered in",
                 "tags": [
                     "attendees"
                 ],
@@ -416,7 +625,7 @@ const docTemplate = `{
         },
         "/api/v1/events/{id}/attendees/{riderId}": {
             "post": {
-                "description": "Add a rider as an attendee to an event",
+                "description": "Enters a rider in an event class, the rider's own class unless another is given. Entries are only taken while the event is open. Once the class is at capacity further riders are waitlisted, and are entered in turn as spots free up. A rider who withdrew or was rejected can enter again, at the back of the line. The owner of a rider can request entry to events they don't own; the request is pending until the event owner approves or rejects it.",
                 "tags": [
                     "attendees"
                 ],
@@ -435,6 +644,12 @@ const docTemplate = `{
                         "name": "riderId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class to enter, defaults to the rider's class",
+                        "name": "class",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -445,13 +660,13 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid event or rider ID",
+                        "description": "Invalid event or rider ID, or a class the series doesn't run",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized to add attendee",
+                        "description": "Owns neither the event nor the rider",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -463,7 +678,7 @@ const docTemplate = `{
                         }
                     },
                     "409": {
-                        "description": "Rider already signed up for this event",
+                        "description": "Rider already signed up or event not open for entries",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -477,7 +692,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Remove a rider as an attendee from an event",
+                "description": "Withdraws a rider's entry or entry request from an event. The entry is kept, marked withdrawn. If the rider held a spot, the next waitlisted rider in their class is entered in it. Both the event owner and the rider's owner can withdraw an entry.",
                 "tags": [
                     "attendees"
                 ],
@@ -509,7 +724,13 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "Unauthorized as you don't own the event",
+                        "description": "Owns neither the event nor the rider",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found or rider not entered",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -523,72 +744,65 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/riders": {
-            "get": {
-                "description": "Get a list of all riders",
+        "/api/v1/events/{id}/attendees/{riderId}/confirm": {
+            "post": {
+                "description": "Confirms an entered rider's spot, for instance once their entry fee is paid. Only entered riders can be confirmed.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "riders"
+                    "attendees"
+                ],
+                "summary": "Confirm an entry ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "riderId",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all riders",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/database.Rider"
-                            }
+                            "$ref": "#/definitions/database.Attendee"
                         }
                     },
-                    "500": {
-                        "description": "Server failed to get all riders",
+                    "400": {
+                        "description": "Invalid event or rider ID",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
-                    }
-                }
-            },
-            "post": {
-                "description": "Create a new rider with the provided details",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "riders"
-                ],
-                "summary": "Create a new rider ** Auth Required **",
-                "parameters": [
-                    {
-                        "description": "Rider data",
-                        "name": "rider",
-                        "in": "body",
-                        "required": true,
+                    },
+                    "403": {
+                        "description": "Not the event owner",
                         "schema": {
-                            "$ref": "#/definitions/database.Rider"
+                            "$ref": "#/definitions/gin.H"
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "404": {
+                        "description": "Event not found or rider not entered",
                         "schema": {
-                            "$ref": "#/definitions/database.Rider"
+                            "$ref": "#/definitions/gin.H"
                         }
                     },
-                    "400": {
-                        "description": "Invalid request body",
+                    "409": {
+                        "description": "Entry is not entered",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to create the rider",
+                        "description": "Failed to confirm the entry",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
@@ -596,20 +810,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/riders/{id}": {
+        "/api/v1/events/{id}/awards": {
             "get": {
-                "description": "Get details of a rider by their ID",
+                "description": "Returns every moto's holeshot winner and laps led. Source is \"manual\" for records entered by hand and \"laps\" for ones derived from lap times.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "riders"
+                    "stats"
                 ],
-                "summary": "Get a rider by ID",
+                "summary": "Holeshots and laps led for an event",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Rider ID",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -619,31 +833,33 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/database.Rider"
+                            "$ref": "#/definitions/main.eventAwards"
                         }
                     },
                     "400": {
-                        "description": "Invalid rider ID",
+                        "description": "Invalid event ID",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "404": {
-                        "description": "No rider found at that ID",
+                        "description": "Event not found",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Server failed to get the requested rider",
+                        "description": "Failed to retrieve awards",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/events/{id}/capacity": {
             "put": {
-                "description": "Update an existing rider with the provided details",
+                "description": "Sets how many riders can hold a spot in a class at an event, 40 unless set. Raising the capacity enters waitlisted riders in turn. Lowering it below the riders already entered keeps their spots, and waitlists new entries until enough withdraw.",
                 "consumes": [
                     "application/json"
                 ],
@@ -651,24 +867,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "riders"
+                    "attendees"
                 ],
-                "summary": "Update a rider ** Auth Required **",
+                "summary": "Set a class's entry capacity ** Auth Required **",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Rider ID",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Updated rider data",
-                        "name": "rider",
+                        "description": "Class capacity",
+                        "name": "capacity",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/database.Rider"
+                            "$ref": "#/definitions/main.capacityRequest"
                         }
                     }
                 ],
@@ -676,225 +892,5837 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/database.Rider"
+                            "$ref": "#/definitions/main.classEntries"
                         }
                     },
                     "400": {
-                        "description": "Invalid rider ID or request body",
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "404": {
-                        "description": "Rider not found",
+                        "description": "Event not found",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     },
                     "500": {
-                        "description": "Failed to update rider",
+                        "description": "Failed to set the capacity",
                         "schema": {
                             "$ref": "#/definitions/gin.H"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a rider by their ID",
+            }
+        },
+        "/api/v1/events/{id}/entries": {
+            "get": {
+                "description": "Returns each class's entry list: the riders holding a spot in the order they got one, then the waitlist in turn, then withdrawals. Entered counts entered and confirmed riders against the class capacity.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "riders"
+                    "attendees"
                 ],
-                "summary": "Delete a rider ** Auth Required **",
+                "summary": "Get event entry lists",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "Rider ID",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.classEntries"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve entries",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/entries/{entryId}/approve": {
+            "post": {
+                "description": "Approves a rider owner's pending entry request while the event is open for entries. The rider is entered, or waitlisted if their class is at capacity.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendees"
+                ],
+                "summary": "Approve an entry request ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Attendee"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event or entry ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event or entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Entry is not pending or event not open for entries",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to approve the entry",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/entries/{entryId}/reject": {
+            "post": {
+                "description": "Rejects a rider owner's pending entry request, with an optional reason. The rider's owner can request entry again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attendees"
+                ],
+                "summary": "Reject an entry request ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "entryId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Why the entry was rejected",
+                        "name": "reason",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/main.rejectRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Attendee"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event or entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Entry is not pending",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to reject the entry",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/import/results": {
+            "post": {
+                "description": "Import moto results for an event from CSV (text/csv) or NDJSON (application/x-ndjson). Riders are matched on number and name, results on rider, class and moto. Points are paid on the event's series table, and rows in a class the series doesn't run are rejected. Results are only taken while the event is in progress or provisional. Malformed lines and unknown columns or fields are rejected, and any rejected row rolls back the whole import. Committed changes are broadcast to live subscribers as \"leaderboard\" deltas.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Bulk import moto results ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and report without saving",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv or ndjson, overrides the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.importReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID or unreadable file",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "One or more rows were rejected",
+                        "schema": {
+                            "$ref": "#/definitions/main.importReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to import results",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/live": {
+            "get": {
+                "description": "Streams \"standings\" events for an event, one class of it or one class's moto, as crossings are ingested, and \"leaderboard\" deltas when timing or recorded results change the order. New clients first get the latest standings; clients reconnecting with Last-Event-ID get every update they missed that is still buffered.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Live running order (Server-Sent Events)",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class",
+                        "name": "class",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only this moto of the class, which is then required",
+                        "name": "moto",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume after this event id",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream"
+                    },
+                    "400": {
+                        "description": "Invalid event ID or moto, or a moto without a class",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve the event",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/motos/{moto}/crossings": {
+            "post": {
+                "description": "Accepts a batch of loop crossings for a moto, recomputes lap times and the running order, and broadcasts the result to live subscribers along with a \"leaderboard\" delta of the riders who moved. Crossings on the \"finish\" loop count laps. Retried batches are ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "live"
+                ],
+                "summary": "Ingest transponder crossings ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moto number",
+                        "name": "moto",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Crossings",
+                        "name": "crossings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.crossingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/timing.Board"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve the event",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/motos/{moto}/holeshot": {
+            "put": {
+                "description": "Records who won the holeshot in a moto. Entries made here are never overwritten by lap data.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Set the holeshot winner ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moto number",
+                        "name": "moto",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Holeshot winner",
+                        "name": "holeshot",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.holeshotRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Holeshot"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "Rider not entered in the event",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save the holeshot",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/motos/{moto}/laps": {
+            "get": {
+                "description": "Returns every recorded lap of a moto with the fastest lap, each rider's average, consistency (standard deviation) and ideal lap, and chart series for the gap to the leader and position on each lap. The class can be left out when only one class has laps in the moto.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "laps"
+                ],
+                "summary": "Lap times and analysis for a moto",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moto number",
+                        "name": "moto",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class, e.g. 450",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.motoLaps"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID, moto or missing class",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve laps",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Import lap times for a moto from CSV (text/csv) or NDJSON (application/x-ndjson) with number, class, lap, lapTimeMs, sectorsMs and position. In CSV, sector splits are separated by semicolons. Riders are matched on class and number among the event's entries, laps on rider, class and lap, so re-sending a lap updates it. Malformed lines and unknown columns or fields are rejected, and any rejected row rolls back the whole import. Holeshots and laps led not entered by hand are derived from the saved laps.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "laps"
+                ],
+                "summary": "Bulk import lap times ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moto number",
+                        "name": "moto",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class for rows that don't give one",
+                        "name": "class",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Validate and report without saving",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv or ndjson, overrides the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.importReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID, moto or unreadable file",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "One or more rows were rejected",
+                        "schema": {
+                            "$ref": "#/definitions/main.importReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to import laps",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/motos/{moto}/laps-led": {
+            "put": {
+                "description": "Replaces the laps-led records of a moto. Entries made here are never overwritten by lap data.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Set laps led ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moto number",
+                        "name": "moto",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Laps led per rider",
+                        "name": "lapsLed",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.lapsLedRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.LapsLed"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "Rider not entered in the event",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save laps led",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/motos/{moto}/official": {
+            "post": {
+                "description": "Records the current results of a class's moto as official. Penalties issued or rescinded afterwards produce amended revisions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "penalties"
+                ],
+                "summary": "Declare moto results official ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moto number",
+                        "name": "moto",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Class",
+                        "name": "official",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.officialRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Revision"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Already official, or event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "No results",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to declare results official",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/motos/{moto}/penalties": {
+            "post": {
+                "description": "Attaches a penalty to a rider's moto result and reclassifies the moto. Types are positions (drop value places), time (add value milliseconds to the rider's moto time, which needs lap times), points (deduct value points, which can take a moto below zero) and dq. Penalties coming from a protest must reference an upheld protest about the same moto. Every reclassification is kept as a result revision, and changes are broadcast as \"leaderboard\" deltas.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "penalties"
+                ],
+                "summary": "Issue a penalty ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moto number",
+                        "name": "moto",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Penalty",
+                        "name": "penalty",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.penaltyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.motoAmendment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "No result to penalise or protest not upheld",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to issue the penalty",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/motos/{moto}/revisions": {
+            "get": {
+                "description": "Returns every revision of a class's moto results, oldest first. The first revision is the order they finished in; later ones follow penalties and when results were declared official. Revisions after the official one are amendments.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "penalties"
+                ],
+                "summary": "Get moto result history",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Moto number",
+                        "name": "moto",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class",
+                        "name": "class",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Revision"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid moto or class",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve revisions",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/overall": {
+            "get": {
+                "description": "Returns each class's overall classification, worked out from the moto results whenever they change. Riders with more motos come first, then the lowest combined moto finish, with ties going to the better finish in the last moto. Finishes lists each moto position, 0 where the rider has no result.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Get event overall results",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.OverallResult"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve overall results",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/penalties": {
+            "get": {
+                "description": "Returns every penalty issued at an event in the order issued, rescinded ones included.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "penalties"
+                ],
+                "summary": "Get event penalties",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Penalty"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve penalties",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/penalties/{penaltyId}": {
+            "delete": {
+                "description": "Withdraws a penalty and reclassifies its moto without it. The penalty stays on record as rescinded.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "penalties"
+                ],
+                "summary": "Rescind a penalty ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Penalty ID",
+                        "name": "penaltyId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.motoAmendment"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid penalty ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Penalty not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Penalty already rescinded, or event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to rescind the penalty",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/protests": {
+            "get": {
+                "description": "Returns the protests filed at an event, oldest first, optionally only those with one status.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "penalties"
+                ],
+                "summary": "Get event protests",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "filed, under_review, upheld or denied",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Protest"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve protests",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Files a protest against a class's moto results, or against one rider's result when riderId is given. Protests start out filed and are decided by the event owner. Protests can only be filed while the event is in progress or provisional.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "penalties"
+                ],
+                "summary": "File a protest ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Protest",
+                        "name": "protest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.protestRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Protest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "No result to protest",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to file the protest",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/protests/{protestId}": {
+            "put": {
+                "description": "Moves a filed protest under review, or upholds or denies it with the reasons given in decision. Upheld and denied protests are closed. Upholding a protest does not change results by itself; issue a penalty referencing the protest to do that. Protests can only be decided while the event is in progress or provisional.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "penalties"
+                ],
+                "summary": "Review or decide a protest ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Protest ID",
+                        "name": "protestId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "decision",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.protestDecision"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Protest"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Protest not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional, or protest can't move to that status",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update the protest",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/qualifying": {
+            "get": {
+                "description": "Returns each class's qualifying sessions, the combined ranking by best lap across sessions, which riders made the top-40 cut, and the gate-pick order for both motos.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "qualifying"
+                ],
+                "summary": "Qualifying and gate pick",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class, e.g. 450",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.classQualifying"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid event ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve qualifying",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Adds a numbered qualifying session for a class the series runs, e.g. session 1 and 2 of the 450 class. Cancelled and official events take no new sessions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "qualifying"
+                ],
+                "summary": "Add a qualifying session ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Session",
+                        "name": "session",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.QualifyingSession"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.QualifyingSession"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event cancelled or official, or session already exists",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create the session",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/qualifying/{sessionId}/times": {
+            "post": {
+                "description": "Records each rider's best lap in a session. Sending a rider again replaces their time. Riders must be entered in the event.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "qualifying"
+                ],
+                "summary": "Record qualifying times ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Session ID",
+                        "name": "sessionId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Best laps",
+                        "name": "times",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.qualifyingTimesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.qualifyingSessionTimes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event or session not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event not in progress or provisional",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "Rider not entered in the event",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to record times",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/reschedule": {
+            "post": {
+                "description": "Moves a scheduled, open or postponed event to a new date, keeping the date it was first planned for in originalDate. Postponed events go back to scheduled.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Reschedule an event ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New date",
+                        "name": "reschedule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.rescheduleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event can't be rescheduled",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to reschedule the event",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/events/{id}/status": {
+            "put": {
+                "description": "Moves an event to a new status. Events go scheduled, open (for entries), in_progress, provisional and official, and open events can close again; scheduled, open and in_progress events can be postponed or cancelled, postponed ones rescheduled, reopened or cancelled, and provisional ones reopened while results are corrected. Official and cancelled events are final.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "events"
+                ],
+                "summary": "Change an event's status ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Event ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.eventStatusRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Event"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the event owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Event not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Event can't move to that status",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update the event status",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/export/{dataset}": {
+            "get": {
+                "description": "Streams a dataset straight from the database with a stable column order. CSV starts with a header row, NDJSON objects keep the same key order, and the X-Export-Schema header lists every column with its type (int64 or string).",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Export riders, events, attendees or results",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "riders, events, attendees or results",
+                        "name": "dataset",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only rows for this series, by ID or slug",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only rows for this season",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv (default) or ndjson",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The exported rows"
+                    },
+                    "400": {
+                        "description": "Invalid season or format",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown dataset or series",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to export",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/export/{dataset}/schema": {
+            "get": {
+                "description": "Returns the ordered columns and their types for an export dataset",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Get the schema of an export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "riders, events, attendees or results",
+                        "name": "dataset",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.ExportColumn"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Unknown dataset",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/import/riders": {
+            "post": {
+                "description": "Import riders from CSV (text/csv) or NDJSON (application/x-ndjson). Riders are matched on number and name, so re-imports update instead of duplicating. Numbers are registered in the latest season like with single riders, and rows whose number is taken or reserved are rejected. Malformed lines and unknown columns or fields are rejected, and any rejected row rolls back the whole import.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "import"
+                ],
+                "summary": "Bulk import riders ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Validate and report without saving",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "csv or ndjson, overrides the Content-Type",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.importReport"
+                        }
+                    },
+                    "400": {
+                        "description": "Unreadable file",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "One or more rows were rejected",
+                        "schema": {
+                            "$ref": "#/definitions/main.importReport"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to import riders",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/media/{key}": {
+            "get": {
+                "description": "Serves an uploaded image or thumbnail by the key in its URL. Keys are never reused, so responses can be cached indefinitely.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/gif"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Get a media file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Media key",
+                        "name": "key",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Media not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to read the media",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/numbers": {
+            "get": {
+                "description": "Returns the numbers taken in a season's class, those reserved and those still available. #1 is reserved for the defending champion, the rider on top of the class's rider standings the season before. A rider keeps a claim on the number they last ran in the class, until they register another or someone else is assigned it.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Get the number registry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Season, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class",
+                        "name": "class",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.numberBoard"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season or missing class",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve numbers",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders": {
+            "get": {
+                "description": "Get a list of all riders, optionally only those who have entered or raced in one series",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Get all riders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Only riders in this series, by ID or slug",
+                        "name": "series",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Rider"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Server failed to get all riders",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new rider with the provided details. A rider with a class has their number registered in it for the season, which fails if the number is taken or reserved for someone else; see the number registry.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Create a new rider ** Auth Required **",
+                "parameters": [
+                    {
+                        "description": "Rider data",
+                        "name": "rider",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Rider"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season to register the number for, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Rider"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Number taken or reserved",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create the rider",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders/compare": {
+            "get": {
+                "description": "Compares two riders from stored results: who finished ahead in the motos both started and by how many places on average, the points each scored per round with the running difference over each series' season, and their record at tracks both have raced. Cancelled events don't count. Pairs in the response are in the order of ids. Riders merged into another are compared as the rider they were merged into.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Head-to-head rider comparison",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Two rider IDs, e.g. 1,2",
+                        "name": "ids",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.riderComparison"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rider IDs",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compare riders",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders/duplicates": {
+            "get": {
+                "description": "Scores every pair of riders with similar names on how likely they are to be the same person. The name counts for 60% of the score, sharing a race number 15%, date of birth 15% and nationality 10%; a detail either rider left blank counts half. Pairs are listed best first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Find duplicate riders ** Admin Required **",
+                "parameters": [
+                    {
+                        "type": "number",
+                        "default": 0.7,
+                        "description": "Lowest score to list, from 0 to 1",
+                        "name": "threshold",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.riderDuplicate"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid threshold",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve riders",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders/{id}": {
+            "get": {
+                "description": "Get details of a rider by their ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Get a rider by ID",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Rider"
+                        }
+                    },
+                    "301": {
+                        "description": "Rider was merged into another; Location gives their new address"
+                    },
+                    "400": {
+                        "description": "Invalid rider ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "No rider found at that ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Server failed to get the requested rider",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing rider with the provided details. The rider's number is registered in their class for the season, replacing the one registered before, which fails if the number is taken or reserved for someone else.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Update a rider ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Updated rider data",
+                        "name": "rider",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Rider"
+                        }
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season to register the number for, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Rider"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rider ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Number taken or reserved",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update rider",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a rider by their ID, along with their media",
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Delete a rider ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid rider ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "Not the rider's owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete the rider",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders/{id}/calendar.ics": {
+            "get": {
+                "description": "Returns the events a rider is entered in as an RFC 5545 calendar",
+                "produces": [
+                    "text/calendar"
+                ],
+                "tags": [
+                    "calendar"
+                ],
+                "summary": "A rider's schedule as iCalendar",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "iCalendar feed"
+                    },
+                    "301": {
+                        "description": "Rider was merged into another; Location gives their new address"
+                    },
+                    "400": {
+                        "description": "Invalid rider ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to build the calendar",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders/{id}/media": {
+            "get": {
+                "description": "Returns a rider's headshots and bike photos, newest first, with URLs of the original and its thumbnails.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Get rider media",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "headshot or bike",
+                        "name": "kind",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Media"
+                            }
+                        }
+                    },
+                    "301": {
+                        "description": "Rider was merged into another; Location gives their new address"
+                    },
+                    "400": {
+                        "description": "Invalid rider ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve media",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Uploads a headshot of a rider or a photo of their bike livery as a multipart form. The type is sniffed from the file itself; JPEG, PNG and GIF images are accepted, up to 10 MB unless configured otherwise. Small and medium JPEG thumbnails are made alongside the original.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Upload rider media ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "headshot or bike",
+                        "name": "kind",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Caption",
+                        "name": "caption",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Media"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rider ID or form",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the rider's owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "413": {
+                        "description": "Image too large",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "415": {
+                        "description": "Not a JPEG, PNG or GIF image",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "Unreadable image",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to store the image",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders/{id}/media/{mediaId}": {
+            "delete": {
+                "description": "Deletes an image of a rider along with its thumbnails.",
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Delete rider media ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Media ID",
+                        "name": "mediaId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid rider or media ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the rider's owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider or media not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete the media",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders/{id}/merge": {
+            "post": {
+                "description": "Moves everything recorded against the duplicate (entries, results, overall results, laps, laps led, holeshots, qualifying times, penalties, protests, registered numbers and media) to this rider in one transaction, fills in details this rider is missing from the duplicate, and deletes the duplicate. Where both riders hold the same registration or event entry, this rider's is kept. The duplicate's Id redirects here afterwards. Riders who raced in the same moto can't be merged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Merge a duplicate rider ** Admin Required **",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID of the rider to keep",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Duplicate to merge",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.riderMergeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.riderMerge"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid rider ID or request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an administrator",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "The riders raced each other",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to merge the riders",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/riders/{id}/stats": {
+            "get": {
+                "description": "Computes a rider's career and per-season record in each class of each series from stored results: overall wins, podiums, top-5s and top-10s, moto wins, average finish, DNF rate, points and championships. Championships are the classes the rider topped in the rider standings of seasons whose last event has passed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "riders"
+                ],
+                "summary": "Rider career statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Rider ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.riderStats"
+                        }
+                    },
+                    "301": {
+                        "description": "Rider was merged into another; Location gives their new address"
+                    },
+                    "400": {
+                        "description": "Invalid rider ID",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Rider not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compute statistics",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/series": {
+            "get": {
+                "description": "Lists the championships events are raced in, with the classes each runs and the points it pays per moto.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Get all series",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/database.Series"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve series",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a series owned by the caller. Classes limit which classes its events run, and can be left empty to allow any. Points is what each moto pays by finishing position, and motosPerEvent how many points-paying motos a class runs per round; both default to the Pro Motocross rules.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Create a series ** Auth Required **",
+                "parameters": [
+                    {
+                        "description": "Series",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Series"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/database.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already in use",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create the series",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/series/{id}": {
+            "get": {
+                "description": "Returns a series by its ID or slug.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Get a series",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Series"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve the series",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replaces a series' name, slug, classes and scoring. Only the owner can change a series; the series the API ships with have no owner and can't be changed. Points already awarded are not recalculated.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Update a series ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Series",
+                        "name": "series",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/database.Series"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.Series"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the series owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "409": {
+                        "description": "Slug already in use",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update the series",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/series/{id}/scoring": {
+            "get": {
+                "description": "Returns the points rules a series scores a season by. Source says where they come from: saved for the season, saved for every season of the series, or, when nothing is saved, the series' points table on every moto.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Series scoring rules",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.seriesScoring"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve the scoring rules",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Saves the points rules for a season of a series, or for every season without its own when no season is given. Basis is moto (the default) to score every moto or overall to score the overall finish at each round. Points is paid by finishing position, multipliers scale a round's finish points, dropWorst rounds are left out of the total and bonuses pay per holeshot, per lap led or for pole (the top qualifier). Only the series owner can change its rules.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "series"
+                ],
+                "summary": "Set series scoring rules ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, or every season when left out",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Scoring rules",
+                        "name": "rules",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/scoring.Rules"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/database.ScoringRules"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season or rules",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the series owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to save the scoring rules",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the rules saved for a season of a series, or those for every season when no season is given, so the season falls back to the series rules or its points table.",
+                "tags": [
+                    "series"
+                ],
+                "summary": "Delete series scoring rules ** Auth Required **",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, or every season when left out",
+                        "name": "season",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid season",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "403": {
+                        "description": "Not the series owner",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete the scoring rules",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/standings/manufacturers": {
+            "get": {
+                "description": "Scores each moto, or each round's overall finish when the series' scoring rules say so, for the best-placed bike of every brand, using the bike each rider raced that day. Finishes pay the rules' points table and each round is scaled by its multiplier, as in the rider standings; rider bonuses, penalty deductions and dropped rounds don't count. Points are totalled per class with a per-round breakdown, and ties are broken by wins. Cancelled events don't count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standings"
+                ],
+                "summary": "Manufacturer championship standings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug, defaults to Pro Motocross",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.championshipStandings"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compute standings",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/standings/riders": {
+            "get": {
+                "description": "Scores a season of a series under its scoring rules and ranks the riders per class, with what each scored at every round. Ties on points go to more wins, then the better score at the latest round. Missed rounds score 0; cancelled events are not rounds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standings"
+                ],
+                "summary": "Rider championship standings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug, defaults to Pro Motocross",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.riderStandings"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compute standings",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/standings/teams": {
+            "get": {
+                "description": "Scores each moto, or each round's overall finish when the series' scoring rules say so, for every rider on a team, using the team each rider raced for that day. Finishes pay the rules' points table and each round is scaled by its multiplier, as in the rider standings; rider bonuses, penalty deductions and dropped rounds don't count. Points are totalled per class with a per-round breakdown, and ties are broken by wins. Cancelled events don't count.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standings"
+                ],
+                "summary": "Team championship standings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug, defaults to Pro Motocross",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.championshipStandings"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compute standings",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/standings/title-race": {
+            "get": {
+                "description": "Ranks a class under the series' scoring rules, as the rider standings do, and works out, from the motos still to run, the points each rider can still reach, who is mathematically eliminated and the leader's magic number to clinch. Motos still to run are counted at the series' motos per round of every event not cancelled, each paying a win scaled by its round's multiplier, a holeshot bonus and, per round, a pole bonus. Laps led bonuses are not counted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standings"
+                ],
+                "summary": "Championship title race",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug, defaults to Pro Motocross",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Class",
+                        "name": "class",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.titleRace"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season or class",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compute the title race",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Applies made-up finishing orders for motos still to run on top of the current standings and returns the title race before and after. Riders finish each moto in the order of riderIds and the season is scored again under the series' scoring rules. The series defaults to Pro Motocross. Nothing is saved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "standings"
+                ],
+                "summary": "Championship what-if scenarios",
+                "parameters": [
+                    {
+                        "description": "Hypothetical moto results",
+                        "name": "scenario",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.whatIfRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.whatIf"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid scenario",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "422": {
+                        "description": "Moto already run or unknown rider",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to compute the title race",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stats/holeshots": {
+            "get": {
+                "description": "Counts holeshots per rider and per manufacturer for a season. A manufacturer is credited with the bike the rider raced that moto on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Season holeshot totals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug, defaults to Pro Motocross",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.awardStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve stats",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stats/laps-led": {
+            "get": {
+                "description": "Adds up laps led per rider and per manufacturer for a season. A manufacturer is credited with the bike the rider raced that moto on.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Season laps-led totals",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID or slug, defaults to Pro Motocross",
+                        "name": "series",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Season, defaults to the latest",
+                        "name": "season",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this class",
+                        "name": "class",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.awardStats"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid season",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "404": {
+                        "description": "Series not found",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to retrieve stats",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ws": {
+            "get": {
+                "description": "Upgrades to a WebSocket. Authenticate with a bearer token (Authorization header or token query parameter) or an API key (X-API-Key header or apiKey query parameter). Send {\"action\":\"subscribe\",\"topics\":[\"event:12:class:450:moto:2\",\"rider:7\"]} to follow topics; topics are event:ID, event:ID:class:CLASS, event:ID:class:CLASS:moto:N and rider:ID. Updates arrive as {\"type\":\"message\",\"id\":...,\"topic\":...,\"event\":\"standings\"|\"leaderboard\",\"data\":...}. The server pings every 30 seconds. Clients that fall too far behind are closed with code 1013 and can reconnect with lastId to resume.",
+                "tags": [
+                    "live"
+                ],
+                "summary": "Live leaderboards over WebSocket",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "JWT, for clients that can't set headers",
+                        "name": "token",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key, for clients that can't set headers",
+                        "name": "apiKey",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated topics to subscribe to on connect",
+                        "name": "topics",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Resume the initial topics after this message id",
+                        "name": "lastId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols"
+                    },
+                    "400": {
+                        "description": "Invalid topic",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    },
+                    "401": {
+                        "description": "Missing or invalid credentials",
+                        "schema": {
+                            "$ref": "#/definitions/gin.H"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "database.APIKey": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Attendee": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "confirmedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "decidedAt": {
+                    "type": "string"
+                },
+                "decidedBy": {
+                    "type": "integer"
+                },
+                "enteredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rejectedAt": {
+                    "type": "string"
+                },
+                "requestedBy": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "waitlistedAt": {
+                    "type": "string"
+                },
+                "withdrawnAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.AwardTotal": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "manufacturer": {
+                    "type": "string"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Championship": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "season": {
+                    "type": "integer"
+                },
+                "series": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Entry": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "confirmedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "decidedAt": {
+                    "type": "string"
+                },
+                "decidedBy": {
+                    "type": "integer"
+                },
+                "enteredAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rejectedAt": {
+                    "type": "string"
+                },
+                "requestedBy": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "waitlistedAt": {
+                    "type": "string"
+                },
+                "withdrawnAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.EntryRequest": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "confirmedAt": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "decidedAt": {
+                    "type": "string"
+                },
+                "decidedBy": {
+                    "type": "integer"
+                },
+                "enteredAt": {
+                    "type": "string"
+                },
+                "eventDate": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "eventName": {
+                    "type": "string"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rejectedAt": {
+                    "type": "string"
+                },
+                "requestedBy": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "waitlistedAt": {
+                    "type": "string"
+                },
+                "withdrawnAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Event": {
+            "type": "object",
+            "required": [
+                "date",
+                "description",
+                "location",
+                "name",
+                "ownerId"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string",
+                    "minLength": 10
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "location": {
+                    "type": "string",
+                    "minLength": 3
+                },
+                "name": {
+                    "type": "string",
+                    "minLength": 3
+                },
+                "originalDate": {
+                    "type": "string"
+                },
+                "ownerId": {
+                    "type": "integer"
+                },
+                "round": {
+                    "type": "integer"
+                },
+                "season": {
+                    "type": "integer"
+                },
+                "sequence": {
+                    "type": "integer"
+                },
+                "seriesId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "trackId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.ExportColumn": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Holeshot": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "moto": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Lap": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lap": {
+                    "type": "integer"
+                },
+                "lapTimeMs": {
+                    "type": "integer"
+                },
+                "moto": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                },
+                "sectorsMs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "database.LapsLed": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "laps": {
+                    "type": "integer"
+                },
+                "moto": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Media": {
+            "type": "object",
+            "properties": {
+                "caption": {
+                    "type": "string"
+                },
+                "contentType": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "height": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "thumbnails": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "uploadedBy": {
+                    "type": "integer"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.OverallResult": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "combined": {
+                    "type": "integer"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "finishes": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "motos": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Penalty": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "issuedBy": {
+                    "type": "integer"
+                },
+                "moto": {
+                    "type": "integer"
+                },
+                "protestId": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "rescindedAt": {
+                    "type": "string"
+                },
+                "rescindedBy": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Protest": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "decision": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "filedBy": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "moto": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.QualifyingSession": {
+            "type": "object",
+            "required": [
+                "class",
+                "number"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer",
+                    "minimum": 1
+                }
+            }
+        },
+        "database.QualifyingTime": {
+            "type": "object",
+            "properties": {
+                "bestLapMs": {
+                    "type": "integer"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                },
+                "sessionId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Result": {
+            "type": "object",
+            "required": [
+                "class",
+                "moto",
+                "position",
+                "riderId"
+            ],
+            "properties": {
+                "bikeBrand": {
+                    "type": "string"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "finishPosition": {
+                    "type": "integer"
+                },
+                "finishStatus": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "moto": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Revision": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "createdBy": {
+                    "type": "integer"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "moto": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Result"
+                    }
+                },
+                "revision": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.Rider": {
+            "type": "object",
+            "required": [
+                "firstName",
+                "lastName",
+                "number",
+                "ownerId"
+            ],
+            "properties": {
+                "bikeBrand": {
+                    "type": "string"
+                },
+                "careerPoints": {
+                    "type": "integer"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "dateOfBirth": {
+                    "type": "string"
+                },
+                "firstName": {
+                    "type": "string",
+                    "minLength": 3
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "lastName": {
+                    "type": "string",
+                    "minLength": 3
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "ownerId": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "team": {
+                    "type": "string"
+                }
+            }
+        },
+        "database.RiderNumber": {
+            "type": "object",
+            "properties": {
+                "assignedAt": {
+                    "type": "string"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "season": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.RiderStatLine": {
+            "type": "object",
+            "properties": {
+                "averageFinish": {
+                    "type": "number"
+                },
+                "averageMotoFinish": {
+                    "type": "number"
+                },
+                "championships": {
+                    "type": "integer"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "dnfRate": {
+                    "type": "number"
+                },
+                "dnfs": {
+                    "type": "integer"
+                },
+                "events": {
+                    "type": "integer"
+                },
+                "motoWins": {
+                    "type": "integer"
+                },
+                "motos": {
+                    "type": "integer"
+                },
+                "podiums": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "season": {
+                    "type": "integer"
+                },
+                "series": {
+                    "type": "string"
+                },
+                "top10s": {
+                    "type": "integer"
+                },
+                "top5s": {
+                    "type": "integer"
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.ScoringRules": {
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "$ref": "#/definitions/scoring.Rules"
+                },
+                "season": {
+                    "type": "integer"
+                },
+                "seriesId": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "updatedBy": {
+                    "type": "integer"
+                }
+            }
+        },
+        "database.Series": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "classes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "motosPerEvent": {
+                    "type": "integer",
+                    "maximum": 10,
+                    "minimum": 1
+                },
+                "name": {
+                    "type": "string",
+                    "minLength": 3
+                },
+                "ownerId": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 40,
+                    "minLength": 2
+                }
+            }
+        },
+        "database.User": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isAdmin": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "gin.H": {
+            "type": "object",
+            "additionalProperties": {}
+        },
+        "main.awardStats": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "manufacturers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.AwardTotal"
+                    }
+                },
+                "riders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.AwardTotal"
+                    }
+                },
+                "season": {
+                    "type": "integer"
+                },
+                "series": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.capacityRequest": {
+            "type": "object",
+            "required": [
+                "capacity",
+                "class"
+            ],
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "class": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.championshipStandings": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "season": {
+                    "type": "integer"
+                },
+                "series": {
+                    "type": "string"
+                },
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.standingRow"
+                    }
+                }
+            }
+        },
+        "main.chartPoint": {
+            "type": "object",
+            "properties": {
+                "lap": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.classEntries": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer"
+                },
+                "class": {
+                    "type": "string"
+                },
+                "entered": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Entry"
+                    }
+                },
+                "waitlisted": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.classQualifying": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "combined": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.qualifyingRanking"
+                    }
+                },
+                "cut": {
+                    "type": "integer"
+                },
+                "gatePickMoto1": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.gatePick"
+                    }
+                },
+                "gatePickMoto2": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.gatePick"
+                    }
+                },
+                "sessions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.qualifyingSessionTimes"
+                    }
+                }
+            }
+        },
+        "main.createAPIKeyRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "minLength": 2
+                }
+            }
+        },
+        "main.createAPIKeyResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "key": {
+                    "type": "string"
+                },
+                "lastUsedAt": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "prefix": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.crossingsRequest": {
+            "type": "object",
+            "required": [
+                "class",
+                "crossings"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "crossings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/timing.Crossing"
+                    }
+                },
+                "gateDrop": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.duplicateSignals": {
+            "type": "object",
+            "properties": {
+                "dateOfBirth": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "number"
+                },
+                "nationality": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.entryRequests": {
+            "type": "object",
+            "properties": {
+                "incoming": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.EntryRequest"
+                    }
+                },
+                "outgoing": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.EntryRequest"
+                    }
+                }
+            }
+        },
+        "main.eventAwards": {
+            "type": "object",
+            "properties": {
+                "holeshots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Holeshot"
+                    }
+                },
+                "lapsLed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.LapsLed"
+                    }
+                }
+            }
+        },
+        "main.eventStatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "scheduled",
+                        "open",
+                        "in_progress",
+                        "provisional",
+                        "official",
+                        "postponed",
+                        "cancelled"
+                    ]
+                }
+            }
+        },
+        "main.fastestLap": {
+            "type": "object",
+            "properties": {
+                "lap": {
+                    "type": "integer"
+                },
+                "lapTimeMs": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.gatePick": {
+            "type": "object",
+            "properties": {
+                "pick": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.headToHead": {
+            "type": "object",
+            "properties": {
+                "ahead": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "averageGap": {
+                    "type": "number"
+                },
+                "motos": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.holeshotRequest": {
+            "type": "object",
+            "required": [
+                "class",
+                "riderId"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "riderId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.hypotheticalMoto": {
+            "type": "object",
+            "required": [
+                "eventId",
+                "moto",
+                "riderIds"
+            ],
+            "properties": {
+                "eventId": {
+                    "type": "integer"
+                },
+                "moto": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "riderIds": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "main.importReport": {
+            "type": "object",
+            "properties": {
+                "committed": {
+                    "type": "boolean"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "rejected": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.importRowReport"
+                    }
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.importRowReport": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.lapsLedInput": {
+            "type": "object",
+            "required": [
+                "laps",
+                "riderId"
+            ],
+            "properties": {
+                "laps": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "riderId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.lapsLedRequest": {
+            "type": "object",
+            "required": [
+                "class",
+                "lapsLed"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "lapsLed": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.lapsLedInput"
+                    }
+                }
+            }
+        },
+        "main.loginRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "password"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                }
+            }
+        },
+        "main.loginResponse": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.motoAmendment": {
+            "type": "object",
+            "properties": {
+                "penalty": {
+                    "$ref": "#/definitions/database.Penalty"
+                },
+                "revision": {
+                    "$ref": "#/definitions/database.Revision"
+                }
+            }
+        },
+        "main.motoLaps": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "fastestLap": {
+                    "$ref": "#/definitions/main.fastestLap"
+                },
+                "gapToLeader": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.riderSeries"
+                    }
+                },
+                "laps": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Lap"
+                    }
+                },
+                "moto": {
+                    "type": "integer"
+                },
+                "positions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.riderSeries"
+                    }
+                },
+                "riders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.riderLapStats"
+                    }
+                }
+            }
+        },
+        "main.numberBoard": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "class": {
+                    "type": "string"
+                },
+                "reserved": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.reservedNumber"
+                    }
+                },
+                "season": {
+                    "type": "integer"
+                },
+                "taken": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.RiderNumber"
+                    }
+                }
+            }
+        },
+        "main.officialRequest": {
+            "type": "object",
+            "required": [
+                "class"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.penaltyRequest": {
+            "type": "object",
+            "required": [
+                "class",
+                "reason",
+                "riderId",
+                "type"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "protestId": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string",
+                    "minLength": 3
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "positions",
+                        "time",
+                        "points",
+                        "dq"
+                    ]
+                },
+                "value": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "main.protestDecision": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "decision": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "under_review",
+                        "upheld",
+                        "denied"
+                    ]
+                }
+            }
+        },
+        "main.protestRequest": {
+            "type": "object",
+            "required": [
+                "class",
+                "moto",
+                "reason"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "moto": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "reason": {
+                    "type": "string",
+                    "minLength": 10
+                },
+                "riderId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.qualifyingRanking": {
+            "type": "object",
+            "properties": {
+                "bestLapMs": {
+                    "type": "integer"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "gapMs": {
+                    "type": "integer"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "qualified": {
+                    "type": "boolean"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                },
+                "session": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.qualifyingSessionTimes": {
+            "type": "object",
+            "required": [
+                "class",
+                "number"
+            ],
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "times": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.QualifyingTime"
+                    }
+                }
+            }
+        },
+        "main.qualifyingTimeInput": {
+            "type": "object",
+            "required": [
+                "bestLapMs",
+                "riderId"
+            ],
+            "properties": {
+                "bestLapMs": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "riderId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.qualifyingTimesRequest": {
+            "type": "object",
+            "required": [
+                "times"
+            ],
+            "properties": {
+                "times": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.qualifyingTimeInput"
+                    }
+                }
+            }
+        },
+        "main.registerRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "name",
+                "password",
+                "secret"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "minLength": 2
+                },
+                "password": {
+                    "type": "string",
+                    "minLength": 8
+                },
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.rejectRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.remainingRound": {
+            "type": "object",
+            "properties": {
+                "eventId": {
+                    "type": "integer"
+                },
+                "eventName": {
+                    "type": "string"
+                },
+                "motos": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "round": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.rescheduleRequest": {
+            "type": "object",
+            "required": [
+                "date"
+            ],
+            "properties": {
+                "date": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.reservedNumber": {
+            "type": "object",
+            "properties": {
+                "firstName": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "riderId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.riderComparison": {
+            "type": "object",
+            "properties": {
+                "headToHead": {
+                    "$ref": "#/definitions/main.headToHead"
+                },
+                "riders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Rider"
+                    }
+                },
+                "rounds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.roundDelta"
+                    }
+                },
+                "tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.trackRecord"
+                    }
+                }
+            }
+        },
+        "main.riderDuplicate": {
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "$ref": "#/definitions/database.Rider"
+                },
+                "rider": {
+                    "$ref": "#/definitions/database.Rider"
+                },
+                "score": {
+                    "type": "number"
+                },
+                "signals": {
+                    "$ref": "#/definitions/main.duplicateSignals"
+                }
+            }
+        },
+        "main.riderLapStats": {
+            "type": "object",
+            "properties": {
+                "averageMs": {
+                    "type": "integer"
+                },
+                "bestLap": {
+                    "type": "integer"
+                },
+                "bestLapMs": {
+                    "type": "integer"
+                },
+                "bestSectorsMs": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "idealLapMs": {
+                    "type": "integer"
+                },
+                "laps": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                },
+                "stdDevMs": {
+                    "type": "integer"
+                },
+                "totalMs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.riderMerge": {
+            "type": "object",
+            "properties": {
+                "mergedId": {
+                    "type": "integer"
+                },
+                "moved": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "rider": {
+                    "$ref": "#/definitions/database.Rider"
+                }
+            }
+        },
+        "main.riderMergeRequest": {
+            "type": "object",
+            "required": [
+                "duplicateId"
+            ],
+            "properties": {
+                "duplicateId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.riderSeries": {
+            "type": "object",
+            "properties": {
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.chartPoint"
+                    }
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.riderStanding": {
+            "type": "object",
+            "properties": {
+                "dropped": {
+                    "type": "integer"
+                },
+                "firstName": {
+                    "type": "string"
+                },
+                "lastName": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "rounds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scoring.RoundScore"
+                    }
+                },
+                "wins": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.riderStandings": {
+            "type": "object",
+            "properties": {
+                "class": {
+                    "type": "string"
+                },
+                "season": {
+                    "type": "integer"
+                },
+                "series": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.riderStanding"
+                    }
+                }
+            }
+        },
+        "main.riderStats": {
+            "type": "object",
+            "properties": {
+                "career": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.RiderStatLine"
                     }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Invalid rider ID",
-                        "schema": {
-                            "$ref": "#/definitions/gin.H"
-                        }
-                    },
-                    "500": {
-                        "description": "Failed to delete the rider",
-                        "schema": {
-                            "$ref": "#/definitions/gin.H"
-                        }
+                },
+                "championships": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.Championship"
+                    }
+                },
+                "riderId": {
+                    "type": "integer"
+                },
+                "seasons": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/database.RiderStatLine"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "database.Attendee": {
+        },
+        "main.roundDelta": {
             "type": "object",
             "properties": {
+                "cumulative": {
+                    "type": "integer"
+                },
+                "delta": {
+                    "type": "integer"
+                },
                 "eventId": {
                     "type": "integer"
                 },
-                "id": {
+                "eventName": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "round": {
                     "type": "integer"
                 },
-                "riderId": {
+                "season": {
+                    "type": "integer"
+                },
+                "seriesId": {
                     "type": "integer"
                 }
             }
         },
-        "database.Event": {
+        "main.roundPoints": {
             "type": "object",
-            "required": [
-                "date",
-                "description",
-                "location",
-                "name",
-                "ownerId"
-            ],
             "properties": {
-                "date": {
+                "eventId": {
+                    "type": "integer"
+                },
+                "eventName": {
                     "type": "string"
                 },
-                "description": {
-                    "type": "string",
-                    "minLength": 10
+                "points": {
+                    "type": "integer"
                 },
-                "id": {
+                "round": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.seriesScoring": {
+            "type": "object",
+            "properties": {
+                "rules": {
+                    "$ref": "#/definitions/scoring.Rules"
+                },
+                "season": {
                     "type": "integer"
                 },
-                "location": {
-                    "type": "string",
-                    "minLength": 3
+                "series": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.standingRow": {
+            "type": "object",
+            "properties": {
+                "motoWins": {
+                    "type": "integer"
                 },
                 "name": {
-                    "type": "string",
-                    "minLength": 3
+                    "type": "string"
                 },
-                "ownerId": {
+                "points": {
+                    "type": "integer"
+                },
+                "position": {
                     "type": "integer"
+                },
+                "rounds": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.roundPoints"
+                    }
                 }
             }
         },
-        "database.Rider": {
+        "main.titleContender": {
             "type": "object",
-            "required": [
-                "firstName",
-                "lastName",
-                "number",
-                "ownerId"
-            ],
             "properties": {
-                "bikeBrand": {
+                "behind": {
+                    "type": "integer"
+                },
+                "eliminated": {
+                    "type": "boolean"
+                },
+                "firstName": {
                     "type": "string"
                 },
-                "careerPoints": {
+                "lastName": {
+                    "type": "string"
+                },
+                "maxPoints": {
+                    "type": "integer"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "position": {
                     "type": "integer"
                 },
+                "riderId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.titleRace": {
+            "type": "object",
+            "properties": {
                 "class": {
                     "type": "string"
                 },
-                "dateOfBirth": {
-                    "type": "string"
+                "clinched": {
+                    "type": "boolean"
                 },
-                "firstName": {
-                    "type": "string",
-                    "minLength": 3
+                "leaderId": {
+                    "type": "integer"
                 },
-                "id": {
+                "magicNumber": {
                     "type": "integer"
                 },
-                "lastName": {
-                    "type": "string",
-                    "minLength": 3
+                "pointsAvailable": {
+                    "type": "integer"
                 },
-                "nationality": {
-                    "type": "string"
+                "remaining": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.remainingRound"
+                    }
                 },
-                "number": {
+                "remainingMotos": {
                     "type": "integer"
                 },
-                "ownerId": {
+                "season": {
                     "type": "integer"
                 },
-                "status": {
+                "series": {
                     "type": "string"
                 },
-                "team": {
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.titleContender"
+                    }
+                }
+            }
+        },
+        "main.trackRecord": {
+            "type": "object",
+            "properties": {
+                "averageMotoFinish": {
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "bestFinish": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "motos": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "track": {
                     "type": "string"
                 }
             }
         },
-        "database.User": {
+        "main.whatIf": {
             "type": "object",
             "properties": {
-                "email": {
+                "current": {
+                    "$ref": "#/definitions/main.titleRace"
+                },
+                "scenario": {
+                    "$ref": "#/definitions/main.titleRace"
+                }
+            }
+        },
+        "main.whatIfRequest": {
+            "type": "object",
+            "required": [
+                "class",
+                "motos"
+            ],
+            "properties": {
+                "class": {
                     "type": "string"
                 },
-                "id": {
+                "motos": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/main.hypotheticalMoto"
+                    }
+                },
+                "season": {
                     "type": "integer"
                 },
-                "name": {
+                "series": {
                     "type": "string"
                 }
             }
         },
-        "gin.H": {
+        "scoring.Bonus": {
             "type": "object",
-            "additionalProperties": {}
+            "properties": {
+                "kind": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                }
+            }
         },
-        "main.loginRequest": {
+        "scoring.Multiplier": {
             "type": "object",
-            "required": [
-                "email",
-                "password"
-            ],
             "properties": {
-                "email": {
+                "factor": {
+                    "type": "number"
+                },
+                "round": {
+                    "type": "integer"
+                }
+            }
+        },
+        "scoring.RoundScore": {
+            "type": "object",
+            "properties": {
+                "bonus": {
+                    "type": "integer"
+                },
+                "deducted": {
+                    "type": "integer"
+                },
+                "dropped": {
+                    "type": "boolean"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "round": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "scoring.Rules": {
+            "type": "object",
+            "properties": {
+                "basis": {
                     "type": "string"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 8
+                "bonuses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scoring.Bonus"
+                    }
+                },
+                "dropWorst": {
+                    "type": "integer"
+                },
+                "multipliers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/scoring.Multiplier"
+                    }
+                },
+                "points": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
                 }
             }
         },
-        "main.loginResponse": {
+        "timing.Board": {
             "type": "object",
             "properties": {
-                "token": {
+                "class": {
+                    "type": "string"
+                },
+                "eventId": {
+                    "type": "integer"
+                },
+                "gateDrop": {
                     "type": "string"
+                },
+                "moto": {
+                    "type": "integer"
+                },
+                "standings": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/timing.Standing"
+                    }
                 }
             }
         },
-        "main.registerRequest": {
+        "timing.Crossing": {
             "type": "object",
             "required": [
-                "email",
-                "name",
-                "password",
-                "secret"
+                "loopId",
+                "riderNumber",
+                "timestamp"
             ],
             "properties": {
-                "email": {
+                "loopId": {
                     "type": "string"
                 },
-                "name": {
-                    "type": "string",
-                    "minLength": 2
+                "riderNumber": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 8
+                "timestamp": {
+                    "type": "string"
+                }
+            }
+        },
+        "timing.Standing": {
+            "type": "object",
+            "properties": {
+                "bestLapMs": {
+                    "type": "integer"
                 },
-                "secret": {
+                "gapMs": {
+                    "type": "integer"
+                },
+                "laps": {
+                    "type": "integer"
+                },
+                "lapsDown": {
+                    "type": "integer"
+                },
+                "lastCrossing": {
+                    "type": "string"
+                },
+                "lastLapMs": {
+                    "type": "integer"
+                },
+                "lastLoop": {
                     "type": "string"
+                },
+                "position": {
+                    "type": "integer"
+                },
+                "riderNumber": {
+                    "type": "integer"
+                },
+                "totalMs": {
+                    "type": "integer"
                 }
             }
         }
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"time"
+)
+
+type APIKeyModel struct {
+	DB DBTX
+}
+
+// APIKey lets scripts and trackside devices authenticate without a login.
+// Only a hash of the key is stored; the key itself is shown once, when it
+// is created.
+type APIKey struct {
+	Id         int        `json:"id"`
+	UserId     int        `json:"userId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
+
+// HashAPIKey is what gets stored and looked up. Keys are long and random,
+// so a plain SHA-256 is enough and keeps lookups to a single index probe.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func (m *APIKeyModel) Insert(apiKey *APIKey, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "INSERT INTO api_keys (user_id, name, prefix, key_hash) VALUES ($1, $2, $3, $4) RETURNING id, created_at"
+
+	return m.DB.QueryRowContext(ctx, query, apiKey.UserId, apiKey.Name, apiKey.Prefix, HashAPIKey(key)).Scan(&apiKey.Id, &apiKey.CreatedAt)
+}
+
+func (m *APIKeyModel) GetByUser(userId int) ([]*APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT id, user_id, name, prefix, created_at, last_used_at FROM api_keys WHERE user_id = $1 ORDER BY id"
+
+	rows, err := m.DB.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	keys := []*APIKey{}
+	for rows.Next() {
+		var apiKey APIKey
+		if err := rows.Scan(&apiKey.Id, &apiKey.UserId, &apiKey.Name, &apiKey.Prefix, &apiKey.CreatedAt, &apiKey.LastUsedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, &apiKey)
+	}
+
+	return keys, rows.Err()
+}
+
+// GetUserByKey returns the owner of a key and records that the key was used,
+// or nil when no such key exists.
+func (m *APIKeyModel) GetUserByKey(key string) (*User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	hash := HashAPIKey(key)
+
+	query := `
+		SELECT u.id, u.email, u.name, u.password, u.is_admin
+		FROM users u
+		JOIN api_keys k ON k.user_id = u.id
+		WHERE k.key_hash = $1
+	`
+
+	var user User
+	err := m.DB.QueryRowContext(ctx, query, hash).Scan(&user.Id, &user.Email, &user.Name, &user.Password, &user.IsAdmin)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	_, err = m.DB.ExecContext(ctx, "UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE key_hash = $1", hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (m *APIKeyModel) Delete(id, userId int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, "DELETE FROM api_keys WHERE id = $1 AND user_id = $2", id, userId)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -6,22 +6,102 @@ import (
 	"time"
 )
 
+// Entry statuses. Entered and confirmed riders hold a gate spot in their
+// class; waitlisted riders are promoted in turn when a spot frees up.
+// Pending entries are requests from a rider's owner, waiting on the event
+// owner to approve or reject them.
+const (
+	EntryPending    = "pending"
+	EntryEntered    = "entered"
+	EntryConfirmed  = "confirmed"
+	EntryWithdrawn  = "withdrawn"
+	EntryWaitlisted = "waitlisted"
+	EntryRejected   = "rejected"
+)
+
+// DefaultClassCapacity is a full gate, used for classes without a capacity
+// set on the event.
+const DefaultClassCapacity = 40
+
 type AttendeeModel struct {
-	DB *sql.DB
+	DB DBTX
 }
 
+// Attendee is a rider's entry in an event class. Each timestamp records
+// when the entry last moved to that status.
 type Attendee struct {
-	Id      int `json:"id"`
-	RiderId int `json:"riderId"`
-	EventId int `json:"eventId"`
+	Id           int        `json:"id"`
+	RiderId      int        `json:"riderId"`
+	EventId      int        `json:"eventId"`
+	Class        string     `json:"class"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	EnteredAt    *time.Time `json:"enteredAt"`
+	WaitlistedAt *time.Time `json:"waitlistedAt"`
+	ConfirmedAt  *time.Time `json:"confirmedAt"`
+	WithdrawnAt  *time.Time `json:"withdrawnAt"`
+	RejectedAt   *time.Time `json:"rejectedAt"`
+	RequestedBy  *int       `json:"requestedBy"`
+	DecidedBy    *int       `json:"decidedBy"`
+	DecidedAt    *time.Time `json:"decidedAt"`
+	Reason       string     `json:"reason"`
+}
+
+// Entry is an attendee along with who the rider is.
+type Entry struct {
+	Attendee
+	RiderNumber int    `json:"riderNumber"`
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
 }
 
+// Active reports whether the entry holds a gate spot.
+func (a *Attendee) Active() bool {
+	return a.Status == EntryEntered || a.Status == EntryConfirmed
+}
+
+// Closed reports whether the entry was withdrawn or rejected, leaving the
+// rider free to enter again.
+func (a *Attendee) Closed() bool {
+	return a.Status == EntryWithdrawn || a.Status == EntryRejected
+}
+
+// EntryRequest is a pending entry along with the event it is for.
+type EntryRequest struct {
+	Entry
+	EventName string `json:"eventName"`
+	EventDate string `json:"eventDate"`
+}
+
+const attendeeColumns = "a.id, a.rider_id, a.event_id, a.class, a.status, a.created_at, a.entered_at, a.waitlisted_at, a.confirmed_at, a.withdrawn_at, " +
+	"a.rejected_at, a.requested_by, a.decided_by, a.decided_at, a.reason"
+
+func scanAttendee(row scanner, attendee *Attendee, extra ...any) error {
+	dest := []any{&attendee.Id, &attendee.RiderId, &attendee.EventId, &attendee.Class, &attendee.Status,
+		&attendee.CreatedAt, &attendee.EnteredAt, &attendee.WaitlistedAt, &attendee.ConfirmedAt, &attendee.WithdrawnAt,
+		&attendee.RejectedAt, &attendee.RequestedBy, &attendee.DecidedBy, &attendee.DecidedAt, &attendee.Reason}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// Insert adds an entry, as entered unless a status is given.
 func (m *AttendeeModel) Insert(attendee *Attendee) (*Attendee, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "INSERT INTO attendees (event_id, rider_id) VALUES ($1, $2) RETURNING id"
-	err := m.DB.QueryRowContext(ctx, query, attendee.EventId, attendee.RiderId).Scan(&attendee.Id)
+	if attendee.Status == "" {
+		attendee.Status = EntryEntered
+	}
+
+	query := `
+		INSERT INTO attendees (event_id, rider_id, class, status, requested_by, created_at, entered_at, waitlisted_at, confirmed_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP,
+			CASE WHEN $4 IN ('entered', 'confirmed') THEN CURRENT_TIMESTAMP END,
+			CASE WHEN $4 = 'waitlisted' THEN CURRENT_TIMESTAMP END,
+			CASE WHEN $4 = 'confirmed' THEN CURRENT_TIMESTAMP END)
+		RETURNING id, created_at, entered_at, waitlisted_at, confirmed_at
+	`
+	err := m.DB.QueryRowContext(ctx, query, attendee.EventId, attendee.RiderId, attendee.Class, attendee.Status, attendee.RequestedBy).
+		Scan(&attendee.Id, &attendee.CreatedAt, &attendee.EnteredAt, &attendee.WaitlistedAt, &attendee.ConfirmedAt)
 
 	if err != nil {
 		return nil, err
@@ -30,6 +110,229 @@ func (m *AttendeeModel) Insert(attendee *Attendee) (*Attendee, error) {
 	return attendee, nil
 }
 
+// SetStatus moves an entry to a new status and stamps when it happened.
+func (m *AttendeeModel) SetStatus(attendee *Attendee, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE attendees SET status = $1,
+			entered_at = CASE WHEN $1 = 'entered' THEN CURRENT_TIMESTAMP ELSE entered_at END,
+			waitlisted_at = CASE WHEN $1 = 'waitlisted' THEN CURRENT_TIMESTAMP ELSE waitlisted_at END,
+			confirmed_at = CASE WHEN $1 = 'confirmed' THEN CURRENT_TIMESTAMP ELSE confirmed_at END,
+			withdrawn_at = CASE WHEN $1 = 'withdrawn' THEN CURRENT_TIMESTAMP ELSE withdrawn_at END,
+			rejected_at = CASE WHEN $1 = 'rejected' THEN CURRENT_TIMESTAMP ELSE rejected_at END
+		WHERE id = $2
+		RETURNING status, entered_at, waitlisted_at, confirmed_at, withdrawn_at, rejected_at
+	`
+
+	return m.DB.QueryRowContext(ctx, query, status, attendee.Id).
+		Scan(&attendee.Status, &attendee.EnteredAt, &attendee.WaitlistedAt, &attendee.ConfirmedAt, &attendee.WithdrawnAt, &attendee.RejectedAt)
+}
+
+// Decide records the event owner's decision on an entry request and moves
+// it to the given status.
+func (m *AttendeeModel) Decide(attendee *Attendee, status string, decidedBy int, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "UPDATE attendees SET decided_by = $1, decided_at = CURRENT_TIMESTAMP, reason = $2 WHERE id = $3 RETURNING decided_by, decided_at, reason"
+
+	err := m.DB.QueryRowContext(ctx, query, decidedBy, reason, attendee.Id).Scan(&attendee.DecidedBy, &attendee.DecidedAt, &attendee.Reason)
+	if err != nil {
+		return err
+	}
+
+	return m.SetStatus(attendee, status)
+}
+
+func (m *AttendeeModel) Get(id int) (*Attendee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + attendeeColumns + " FROM attendees a WHERE a.id = $1"
+
+	var attendee Attendee
+	err := scanAttendee(m.DB.QueryRowContext(ctx, query, id), &attendee)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &attendee, nil
+}
+
+// GetPendingRequests returns the entry requests waiting on a user, for
+// events they own, and those waiting on others, for riders they own.
+func (m *AttendeeModel) GetPendingRequests(userId int) (incoming, outgoing []*EntryRequest, err error) {
+	incoming, err = m.getRequests("e.owner_id = $1", userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outgoing, err = m.getRequests("r.owner_id = $1", userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return incoming, outgoing, nil
+}
+
+func (m *AttendeeModel) getRequests(where string, args ...any) ([]*EntryRequest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + attendeeColumns + `, r.number, r.first_name, r.last_name, e.name, date(e.date)
+		FROM attendees a
+		JOIN riders r ON r.id = a.rider_id
+		JOIN events e ON e.id = a.event_id
+		WHERE a.status = 'pending' AND ` + where + `
+		ORDER BY e.date, a.created_at, a.id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	requests := []*EntryRequest{}
+	for rows.Next() {
+		var request EntryRequest
+		err := scanAttendee(rows, &request.Attendee, &request.RiderNumber, &request.FirstName, &request.LastName, &request.EventName, &request.EventDate)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, &request)
+	}
+
+	return requests, rows.Err()
+}
+
+// CountActive counts the entries holding a gate spot in an event class.
+func (m *AttendeeModel) CountActive(eventId int, class string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT COUNT(*) FROM attendees WHERE event_id = $1 AND class = $2 AND status IN ('entered', 'confirmed')"
+
+	var count int
+	err := m.DB.QueryRowContext(ctx, query, eventId, class).Scan(&count)
+	return count, err
+}
+
+// NextWaitlisted returns the entry first in line for a spot in an event
+// class, or nil when nobody is waiting.
+func (m *AttendeeModel) NextWaitlisted(eventId int, class string) (*Attendee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + attendeeColumns + " FROM attendees a WHERE a.event_id = $1 AND a.class = $2 AND a.status = 'waitlisted' ORDER BY a.waitlisted_at, a.id LIMIT 1"
+
+	var attendee Attendee
+	err := scanAttendee(m.DB.QueryRowContext(ctx, query, eventId, class), &attendee)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &attendee, nil
+}
+
+// GetEntries returns the entry list of an event, optionally for one class:
+// riders holding a spot in the order they got it, then the waitlist in
+// turn, then withdrawals. Entry requests are left out.
+func (m *AttendeeModel) GetEntries(eventId int, class string) ([]*Entry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + attendeeColumns + `, r.number, r.first_name, r.last_name
+		FROM attendees a
+		JOIN riders r ON r.id = a.rider_id
+		WHERE a.event_id = $1 AND ($2 = '' OR a.class = $2) AND a.status NOT IN ('pending', 'rejected')
+		ORDER BY a.class,
+			CASE a.status WHEN 'confirmed' THEN 0 WHEN 'entered' THEN 0 WHEN 'waitlisted' THEN 1 ELSE 2 END,
+			CASE a.status WHEN 'waitlisted' THEN a.waitlisted_at WHEN 'withdrawn' THEN a.withdrawn_at ELSE a.entered_at END,
+			a.id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId, class)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []*Entry{}
+	for rows.Next() {
+		var entry Entry
+		if err := scanAttendee(rows, &entry.Attendee, &entry.RiderNumber, &entry.FirstName, &entry.LastName); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// GetCapacities returns the capacities set on an event by class. Classes
+// without one take DefaultClassCapacity.
+func (m *AttendeeModel) GetCapacities(eventId int) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, "SELECT class, capacity FROM event_capacities WHERE event_id = $1", eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	capacities := map[string]int{}
+	for rows.Next() {
+		var class string
+		var capacity int
+		if err := rows.Scan(&class, &capacity); err != nil {
+			return nil, err
+		}
+		capacities[class] = capacity
+	}
+
+	return capacities, rows.Err()
+}
+
+func (m *AttendeeModel) GetCapacity(eventId int, class string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var capacity int
+	err := m.DB.QueryRowContext(ctx, "SELECT capacity FROM event_capacities WHERE event_id = $1 AND class = $2", eventId, class).Scan(&capacity)
+	if err == sql.ErrNoRows {
+		return DefaultClassCapacity, nil
+	}
+	return capacity, err
+}
+
+func (m *AttendeeModel) SetCapacity(eventId int, class string, capacity int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO event_capacities (event_id, class, capacity) VALUES ($1, $2, $3)
+		ON CONFLICT (event_id, class) DO UPDATE SET capacity = excluded.capacity
+	`
+
+	_, err := m.DB.ExecContext(ctx, query, eventId, class, capacity)
+	return err
+}
+
 func (m *AttendeeModel) Delete(riderId, eventId int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -47,10 +350,10 @@ func (m *AttendeeModel) GetEventsByAttendee(attendeeId int) ([]*Event, error) {
 	defer cancel()
 
 	query := `
-		SELECT e.id, e.owner_id, e.name, e.description, e.date, e.location
+		SELECT ` + eventColumns + `
 		FROM events e
 		JOIN attendees a ON e.id = a.event_id
-		WHERE a.rider_id = $1
+		WHERE a.rider_id = $1 AND a.status IN ('entered', 'confirmed', 'waitlisted')
 	`
 	rows, err := m.DB.QueryContext(ctx, query, attendeeId)
 	if err != nil {
@@ -62,7 +365,7 @@ func (m *AttendeeModel) GetEventsByAttendee(attendeeId int) ([]*Event, error) {
 	var events []*Event
 	for rows.Next() {
 		var event Event
-		err := rows.Scan(&event.Id, &event.OwnerId, &event.Name, &event.Description, &event.Date, &event.Location)
+		err := scanEvent(rows, &event)
 		if err != nil {
 			return nil, err
 		}
@@ -77,10 +380,10 @@ func (m *AttendeeModel) GetByEventAndAttendee(eventId, riderId int) (*Attendee,
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "SELECT * FROM attendees WHERE event_id = $1 AND rider_id = $2"
+	query := "SELECT " + attendeeColumns + " FROM attendees a WHERE a.event_id = $1 AND a.rider_id = $2"
 	var attendee Attendee
 
-	err := m.DB.QueryRowContext(ctx, query, eventId, riderId).Scan(&attendee.Id, &attendee.RiderId, &attendee.EventId)
+	err := scanAttendee(m.DB.QueryRowContext(ctx, query, eventId, riderId), &attendee)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -91,15 +394,17 @@ func (m *AttendeeModel) GetByEventAndAttendee(eventId, riderId int) (*Attendee,
 	return &attendee, nil
 }
 
+// GetAttendeesByEvent returns the riders holding a spot in an event, with
+// Class set to the class they are entered in.
 func (m AttendeeModel) GetAttendeesByEvent(eventId int) ([]Rider, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
 	query := `
-     SELECT r.id, r.first_name, r.last_name
+     SELECT r.id, r.first_name, r.last_name, r.number, a.class
      FROM riders r
      JOIN attendees a ON r.id = a.rider_id
-     WHERE a.event_id = $1
+     WHERE a.event_id = $1 AND a.status IN ('entered', 'confirmed')
  `
 	rows, err := m.DB.QueryContext(ctx, query, eventId)
 	if err != nil {
@@ -110,7 +415,7 @@ func (m AttendeeModel) GetAttendeesByEvent(eventId int) ([]Rider, error) {
 	var riders []Rider
 	for rows.Next() {
 		var rider Rider
-		err := rows.Scan(&rider.Id, &rider.FirstName, &rider.LastName)
+		err := rows.Scan(&rider.Id, &rider.FirstName, &rider.LastName, &rider.Number, &rider.Class)
 		if err != nil {
 			return nil, err
 		}
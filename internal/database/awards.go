@@ -0,0 +1,250 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Awards are either entered by hand or derived from lap data. Derived
+// records never replace ones entered by hand.
+const (
+	AwardManual = "manual"
+	AwardLaps   = "laps"
+)
+
+type AwardModel struct {
+	DB DBTX
+}
+
+type Holeshot struct {
+	Id      int    `json:"id"`
+	EventId int    `json:"eventId"`
+	Class   string `json:"class"`
+	Moto    int    `json:"moto"`
+	RiderId int    `json:"riderId"`
+	Source  string `json:"source"`
+}
+
+type LapsLed struct {
+	Id      int    `json:"id"`
+	EventId int    `json:"eventId"`
+	Class   string `json:"class"`
+	Moto    int    `json:"moto"`
+	RiderId int    `json:"riderId"`
+	Laps    int    `json:"laps"`
+	Source  string `json:"source"`
+}
+
+// AwardTotal is a season count of holeshots or laps led, either for a rider,
+// with their current manufacturer, or for a manufacturer when RiderId is 0.
+type AwardTotal struct {
+	RiderId      int    `json:"riderId,omitempty"`
+	RiderNumber  int    `json:"riderNumber,omitempty"`
+	FirstName    string `json:"firstName,omitempty"`
+	LastName     string `json:"lastName,omitempty"`
+	Manufacturer string `json:"manufacturer"`
+	Count        int    `json:"count"`
+}
+
+// SaveHoleshot records the holeshot winner of a moto, replacing any earlier
+// record for it.
+func (m *AwardModel) SaveHoleshot(holeshot *Holeshot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO holeshots (event_id, class, moto, rider_id, source) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id, class, moto) DO UPDATE SET rider_id = excluded.rider_id, source = excluded.source
+		RETURNING id
+	`
+
+	return m.DB.QueryRowContext(ctx, query, holeshot.EventId, holeshot.Class, holeshot.Moto, holeshot.RiderId, holeshot.Source).Scan(&holeshot.Id)
+}
+
+func (m *AwardModel) GetHoleshot(eventId int, class string, moto int) (*Holeshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT id, event_id, class, moto, rider_id, source FROM holeshots WHERE event_id = $1 AND class = $2 AND moto = $3"
+
+	var holeshot Holeshot
+	err := m.DB.QueryRowContext(ctx, query, eventId, class, moto).Scan(&holeshot.Id, &holeshot.EventId, &holeshot.Class, &holeshot.Moto, &holeshot.RiderId, &holeshot.Source)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &holeshot, nil
+}
+
+func (m *AwardModel) GetHoleshotsByEvent(eventId int) ([]*Holeshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT id, event_id, class, moto, rider_id, source FROM holeshots WHERE event_id = $1 ORDER BY class, moto"
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	holeshots := []*Holeshot{}
+	for rows.Next() {
+		var holeshot Holeshot
+		if err := rows.Scan(&holeshot.Id, &holeshot.EventId, &holeshot.Class, &holeshot.Moto, &holeshot.RiderId, &holeshot.Source); err != nil {
+			return nil, err
+		}
+		holeshots = append(holeshots, &holeshot)
+	}
+
+	return holeshots, rows.Err()
+}
+
+// ReplaceLapsLed swaps the laps-led records of a moto for the given ones.
+func (m *AwardModel) ReplaceLapsLed(eventId int, class string, moto int, records []*LapsLed) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM laps_led WHERE event_id = $1 AND class = $2 AND moto = $3", eventId, class, moto)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO laps_led (event_id, class, moto, rider_id, laps, source) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+
+	for _, record := range records {
+		record.EventId, record.Class, record.Moto = eventId, class, moto
+		err := m.DB.QueryRowContext(ctx, query, eventId, class, moto, record.RiderId, record.Laps, record.Source).Scan(&record.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// HasManualLapsLed reports whether a moto's laps led were entered by hand.
+func (m *AwardModel) HasManualLapsLed(eventId int, class string, moto int) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT EXISTS (SELECT 1 FROM laps_led WHERE event_id = $1 AND class = $2 AND moto = $3 AND source = $4)"
+
+	var exists bool
+	err := m.DB.QueryRowContext(ctx, query, eventId, class, moto, AwardManual).Scan(&exists)
+	return exists, err
+}
+
+func (m *AwardModel) GetLapsLedByEvent(eventId int) ([]*LapsLed, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT id, event_id, class, moto, rider_id, laps, source FROM laps_led WHERE event_id = $1 ORDER BY class, moto, laps DESC"
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	records := []*LapsLed{}
+	for rows.Next() {
+		var record LapsLed
+		if err := rows.Scan(&record.Id, &record.EventId, &record.Class, &record.Moto, &record.RiderId, &record.Laps, &record.Source); err != nil {
+			return nil, err
+		}
+		records = append(records, &record)
+	}
+
+	return records, rows.Err()
+}
+
+// The manufacturer of an award is the bike the rider raced that moto on,
+// as recorded with their result, falling back to their current bike.
+const awardManufacturer = `COALESCE(NULLIF((
+	SELECT res.bike_brand FROM results res
+	WHERE res.event_id = a.event_id AND res.rider_id = a.rider_id AND res.class = a.class AND res.moto = a.moto
+), ''), r.bike_brand, '')`
+
+// HoleshotTotals counts holeshots per rider in a series' season, optionally for one
+// class, most first.
+func (m *AwardModel) HoleshotTotals(seriesId, season int, class string) ([]*AwardTotal, error) {
+	return m.riderTotals("holeshots", "COUNT(*)", seriesId, season, class)
+}
+
+// LapsLedTotals adds up laps led per rider in a series' season, optionally for one
+// class, most first.
+func (m *AwardModel) LapsLedTotals(seriesId, season int, class string) ([]*AwardTotal, error) {
+	return m.riderTotals("laps_led", "SUM(a.laps)", seriesId, season, class)
+}
+
+func (m *AwardModel) HoleshotTotalsByManufacturer(seriesId, season int, class string) ([]*AwardTotal, error) {
+	return m.manufacturerTotals("holeshots", "COUNT(*)", seriesId, season, class)
+}
+
+func (m *AwardModel) LapsLedTotalsByManufacturer(seriesId, season int, class string) ([]*AwardTotal, error) {
+	return m.manufacturerTotals("laps_led", "SUM(a.laps)", seriesId, season, class)
+}
+
+// The table and aggregate are constants chosen above, never user input.
+func (m *AwardModel) riderTotals(table, aggregate string, seriesId, season int, class string) ([]*AwardTotal, error) {
+	query := `
+		SELECT r.id, r.number, r.first_name, r.last_name, COALESCE(r.bike_brand, ''), ` + aggregate + ` AS total
+		FROM ` + table + ` a
+		JOIN riders r ON r.id = a.rider_id
+		JOIN events e ON e.id = a.event_id
+		WHERE e.series_id = $1 AND e.season = $2 AND ($3 = '' OR a.class = $3)
+		GROUP BY r.id
+		ORDER BY total DESC, r.number
+	`
+
+	return m.totals(query, true, seriesId, season, class)
+}
+
+func (m *AwardModel) manufacturerTotals(table, aggregate string, seriesId, season int, class string) ([]*AwardTotal, error) {
+	query := `
+		SELECT ` + awardManufacturer + ` AS manufacturer, ` + aggregate + ` AS total
+		FROM ` + table + ` a
+		JOIN riders r ON r.id = a.rider_id
+		JOIN events e ON e.id = a.event_id
+		WHERE e.series_id = $1 AND e.season = $2 AND ($3 = '' OR a.class = $3)
+		GROUP BY manufacturer
+		ORDER BY total DESC, manufacturer
+	`
+
+	return m.totals(query, false, seriesId, season, class)
+}
+
+func (m *AwardModel) totals(query string, byRider bool, args ...any) ([]*AwardTotal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	totals := []*AwardTotal{}
+	for rows.Next() {
+		var total AwardTotal
+		if byRider {
+			err = rows.Scan(&total.RiderId, &total.RiderNumber, &total.FirstName, &total.LastName, &total.Manufacturer, &total.Count)
+		} else {
+			err = rows.Scan(&total.Manufacturer, &total.Count)
+		}
+		if err != nil {
+			return nil, err
+		}
+		totals = append(totals, &total)
+	}
+
+	return totals, rows.Err()
+}
@@ -3,38 +3,113 @@ package database
 import (
 	"context"
 	"database/sql"
+	"strconv"
 	"time"
 )
 
+// Event statuses. An event opens for entries, runs, has provisional and
+// then official results; it can be postponed or cancelled before it
+// finishes.
+const (
+	EventScheduled   = "scheduled"
+	EventOpen        = "open"
+	EventInProgress  = "in_progress"
+	EventProvisional = "provisional"
+	EventOfficial    = "official"
+	EventPostponed   = "postponed"
+	EventCancelled   = "cancelled"
+)
+
+// eventTransitions lists the statuses an event can move to from each
+// status. Official and cancelled events are final.
+var eventTransitions = map[string][]string{
+	EventScheduled:   {EventOpen, EventPostponed, EventCancelled},
+	EventOpen:        {EventScheduled, EventInProgress, EventPostponed, EventCancelled},
+	EventInProgress:  {EventProvisional, EventPostponed, EventCancelled},
+	EventProvisional: {EventInProgress, EventOfficial},
+	EventPostponed:   {EventScheduled, EventOpen, EventCancelled},
+}
+
+// EventCanMove reports whether an event can move from one status to another.
+func EventCanMove(from, to string) bool {
+	for _, status := range eventTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// EventTakesResults reports whether results, timing and other race data can
+// be recorded for an event in a status: only while it runs or its results
+// are provisional.
+func EventTakesResults(status string) bool {
+	return status == EventInProgress || status == EventProvisional
+}
+
 type EventModel struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 type Event struct {
-	Id          int    `json:"id"`
-	OwnerId     int    `json:"ownerId" binding:"required"`
-	Name        string `json:"name" binding:"required,min=3"`
-	Description string `json:"description" binding:"required,min=10"`
-	Date        string `json:"date" binding:"required,datetime=2006-01-02"`
-	Location    string `json:"location" binding:"required,min=3"`
+	Id           int     `json:"id"`
+	OwnerId      int     `json:"ownerId" binding:"required"`
+	Name         string  `json:"name" binding:"required,min=3"`
+	Description  string  `json:"description" binding:"required,min=10"`
+	Date         string  `json:"date" binding:"required,datetime=2006-01-02"`
+	Location     string  `json:"location" binding:"required,min=3"`
+	Season       int     `json:"season"`
+	Round        int     `json:"round"`
+	TrackId      *int    `json:"trackId"`
+	Sequence     int     `json:"sequence"`
+	Status       string  `json:"status"`
+	OriginalDate *string `json:"originalDate"`
+	SeriesId     int     `json:"seriesId"`
+}
+
+// eventColumns are the columns read by scanEvent, in scan order.
+const eventColumns = "e.id, e.owner_id, e.name, e.description, e.date, e.location, e.season, e.round, e.track_id, e.sequence, e.status, e.original_date, e.series_id"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(row scanner, event *Event) error {
+	return row.Scan(&event.Id, &event.OwnerId, &event.Name, &event.Description, &event.Date, &event.Location, &event.Season, &event.Round, &event.TrackId, &event.Sequence, &event.Status, &event.OriginalDate, &event.SeriesId)
+}
+
+// defaultSeason fills in the season from the event date when it is missing,
+// matching how existing events were backfilled, and puts events without a
+// series in Pro Motocross.
+func (event *Event) defaultSeason() {
+	if event.Season == 0 && len(event.Date) >= 4 {
+		event.Season, _ = strconv.Atoi(event.Date[:4])
+	}
+	if event.SeriesId == 0 {
+		event.SeriesId = DefaultSeriesId
+	}
 }
 
 func (m *EventModel) Insert(event *Event) error {
+	event.defaultSeason()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "INSERT INTO events (owner_id, name, description, date, location) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	query := "INSERT INTO events (owner_id, name, description, date, location, season, round, track_id, series_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, status"
 
-	return m.DB.QueryRowContext(ctx, query, event.OwnerId, event.Name, event.Description, event.Date, event.Location).Scan(&event.Id)
+	return m.DB.QueryRowContext(ctx, query, event.OwnerId, event.Name, event.Description, event.Date, event.Location, event.Season, event.Round, event.TrackId, event.SeriesId).Scan(&event.Id, &event.Status)
 }
 
-func (m *EventModel) GetAll() ([]*Event, error) {
+// GetAll returns every event in a series, or in all series when seriesId
+// is zero.
+func (m *EventModel) GetAll(seriesId int) ([]*Event, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "SELECT * FROM events"
+	query := "SELECT " + eventColumns + " FROM events e WHERE $1 = 0 OR e.series_id = $1"
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, seriesId)
 	if err != nil {
 		return nil, err
 	}
@@ -46,7 +121,7 @@ func (m *EventModel) GetAll() ([]*Event, error) {
 	for rows.Next() {
 		var event Event
 
-		err := rows.Scan(&event.Id, &event.OwnerId, &event.Name, &event.Description, &event.Date, &event.Location)
+		err := scanEvent(rows, &event)
 		if err != nil {
 			return nil, err
 		}
@@ -65,11 +140,30 @@ func (m *EventModel) Get(id int) (*Event, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "SELECT * FROM events WHERE id = $1"
+	query := "SELECT " + eventColumns + " FROM events e WHERE e.id = $1"
+
+	var event Event
+
+	err := scanEvent(m.DB.QueryRowContext(ctx, query, id), &event)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+func (m *EventModel) GetBySeasonAndRound(seriesId, season, round int) (*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + eventColumns + " FROM events e WHERE e.series_id = $1 AND e.season = $2 AND e.round = $3"
 
 	var event Event
 
-	err := m.DB.QueryRowContext(ctx, query, id).Scan(&event.Id, &event.OwnerId, &event.Name, &event.Description, &event.Date, &event.Location)
+	err := scanEvent(m.DB.QueryRowContext(ctx, query, seriesId, season, round), &event)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -80,13 +174,32 @@ func (m *EventModel) Get(id int) (*Event, error) {
 	return &event, nil
 }
 
+// LatestSeason returns the most recent season of a series with events, or
+// the current year when there are none.
+func (m *EventModel) LatestSeason(seriesId int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var season sql.NullInt64
+	if err := m.DB.QueryRowContext(ctx, "SELECT MAX(season) FROM events WHERE series_id = $1", seriesId).Scan(&season); err != nil {
+		return 0, err
+	}
+
+	if !season.Valid || season.Int64 == 0 {
+		return time.Now().Year(), nil
+	}
+	return int(season.Int64), nil
+}
+
 func (m *EventModel) Update(event *Event) error {
+	event.defaultSeason()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "UPDATE events SET name = $1, description = $2, date = $3, location = $4 WHERE id = $5"
+	query := "UPDATE events SET name = $1, description = $2, date = $3, location = $4, season = $5, round = $6, track_id = $7, series_id = $8, sequence = sequence + (date(date) IS NOT date($3)) WHERE id = $9"
 
-	_, err := m.DB.ExecContext(ctx, query, event.Name, event.Description, event.Date, event.Location, event.Id)
+	_, err := m.DB.ExecContext(ctx, query, event.Name, event.Description, event.Date, event.Location, event.Season, event.Round, event.TrackId, event.SeriesId, event.Id)
 	if err != nil {
 		return err
 	}
@@ -94,6 +207,32 @@ func (m *EventModel) Update(event *Event) error {
 	return nil
 }
 
+// UpdateStatus saves an event's status, bumping its calendar sequence so
+// subscribers pick up cancellations.
+func (m *EventModel) UpdateStatus(event *Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "UPDATE events SET status = $1, sequence = sequence + 1 WHERE id = $2 RETURNING sequence"
+
+	return m.DB.QueryRowContext(ctx, query, event.Status, event.Id).Scan(&event.Sequence)
+}
+
+// Reschedule moves an event to a new date, keeping the date it was first
+// planned for, and saves its status.
+func (m *EventModel) Reschedule(event *Event, date string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		UPDATE events SET original_date = COALESCE(original_date, date), date = $1, status = $2, sequence = sequence + 1
+		WHERE id = $3
+		RETURNING date, original_date, sequence
+	`
+
+	return m.DB.QueryRowContext(ctx, query, date, event.Status, event.Id).Scan(&event.Date, &event.OriginalDate, &event.Sequence)
+}
+
 func (m *EventModel) Delete(id int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -113,10 +252,10 @@ func (m EventModel) GetByAttendee(attendeeId int) ([]Event, error) {
 	defer cancel()
 
 	query := `
-		SELECT e.id, e.owner_id, e.name, e.description, e.date, e.location
+		SELECT ` + eventColumns + `
 		FROM events e
 		JOIN attendees a ON e.id = a.event_id
-		WHERE a.rider_id = $1
+		WHERE a.rider_id = $1 AND a.status IN ('entered', 'confirmed', 'waitlisted')
 	`
 	rows, err := m.DB.QueryContext(ctx, query, attendeeId)
 	if err != nil {
@@ -127,7 +266,7 @@ func (m EventModel) GetByAttendee(attendeeId int) ([]Event, error) {
 	var events []Event
 	for rows.Next() {
 		var event Event
-		err := rows.Scan(&event.Id, &event.OwnerId, &event.Name, &event.Description, &event.Date, &event.Location)
+		err := scanEvent(rows, &event)
 		if err != nil {
 			return nil, err
 		}
@@ -135,3 +274,71 @@ func (m EventModel) GetByAttendee(attendeeId int) ([]Event, error) {
 	}
 	return events, nil
 }
+
+// GetBySeason returns the events of a series' season in running order.
+func (m *EventModel) GetBySeason(seriesId, season int) ([]*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE e.series_id = $1 AND e.season = $2
+		ORDER BY e.round, e.date
+	`
+	rows, err := m.DB.QueryContext(ctx, query, seriesId, season)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*Event{}
+	for rows.Next() {
+		var event Event
+		if err := scanEvent(rows, &event); err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
+
+// GetByClass returns the events with at least one entry in the given class,
+// in a series or in all series when seriesId is zero. The class is the one
+// each rider entered the event in, not the rider's current class.
+func (m *EventModel) GetByClass(seriesId int, class string) ([]*Event, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + eventColumns + `
+		FROM events e
+		WHERE ($1 = 0 OR e.series_id = $1) AND EXISTS (
+			SELECT 1 FROM attendees a
+			WHERE a.event_id = e.id AND a.class = $2 AND a.status IN ('entered', 'confirmed', 'waitlisted')
+		)
+		ORDER BY e.date
+	`
+	rows, err := m.DB.QueryContext(ctx, query, seriesId, class)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*Event{}
+	for rows.Next() {
+		var event Event
+		err := scanEvent(rows, &event)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, &event)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type ExportModel struct {
+	DB DBTX
+}
+
+// ExportColumn describes one column of an export. Type is either int64 or
+// string so the files map directly onto a Parquet or Arrow schema.
+type ExportColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+type exportDataset struct {
+	columns []ExportColumn
+	query   string
+	filter  string
+}
+
+func int64Column(name string) ExportColumn  { return ExportColumn{Name: name, Type: "int64"} }
+func stringColumn(name string) ExportColumn { return ExportColumn{Name: name, Type: "string"} }
+
+// exportEventFilter narrows an export to the events of a series and season,
+// either of which can be zero to take them all.
+const exportEventFilter = "($1 = 0 OR e.series_id = $1) AND ($2 = 0 OR e.season = $2)"
+
+// exportDatasets lists the columns of every export in the order they are
+// written. The queries select exactly these columns in the same order.
+var exportDatasets = map[string]exportDataset{
+	"riders": {
+		columns: []ExportColumn{
+			int64Column("id"), int64Column("number"), stringColumn("firstName"), stringColumn("lastName"),
+			stringColumn("class"), stringColumn("team"), stringColumn("bikeBrand"), stringColumn("nationality"),
+			stringColumn("dateOfBirth"), int64Column("careerPoints"), stringColumn("status"),
+		},
+		query: `
+			SELECT r.id, r.number, r.first_name, r.last_name, r.class, r.team, r.bike_brand, r.nationality,
+				date(r.date_of_birth), r.career_points, r.status
+			FROM riders r`,
+		filter: ` WHERE r.id IN (
+				SELECT a.rider_id FROM attendees a JOIN events e ON e.id = a.event_id WHERE ` + exportEventFilter + `
+			)`,
+	},
+	"events": {
+		columns: []ExportColumn{
+			int64Column("id"), int64Column("season"), int64Column("round"), stringColumn("name"),
+			stringColumn("date"), stringColumn("location"), int64Column("trackId"), stringColumn("description"),
+			int64Column("seriesId"),
+		},
+		query: `
+			SELECT e.id, e.season, e.round, e.name, date(e.date), e.location, e.track_id, e.description, e.series_id
+			FROM events e`,
+		filter: ` WHERE ` + exportEventFilter,
+	},
+	"attendees": {
+		columns: []ExportColumn{
+			int64Column("id"), int64Column("eventId"), int64Column("season"), int64Column("round"),
+			int64Column("riderId"), int64Column("number"), stringColumn("firstName"), stringColumn("lastName"),
+			stringColumn("class"), stringColumn("status"),
+		},
+		query: `
+			SELECT a.id, a.event_id, e.season, e.round, a.rider_id, r.number, r.first_name, r.last_name, a.class, a.status
+			FROM attendees a
+			JOIN events e ON e.id = a.event_id
+			JOIN riders r ON r.id = a.rider_id`,
+		filter: ` WHERE ` + exportEventFilter,
+	},
+	"results": {
+		columns: []ExportColumn{
+			int64Column("id"), int64Column("eventId"), int64Column("season"), int64Column("round"),
+			stringColumn("eventName"), stringColumn("date"), int64Column("riderId"), int64Column("number"),
+			stringColumn("firstName"), stringColumn("lastName"), stringColumn("class"), int64Column("moto"),
+			int64Column("position"), int64Column("points"), stringColumn("status"), stringColumn("team"),
+			stringColumn("bikeBrand"),
+		},
+		query: `
+			SELECT res.id, res.event_id, e.season, e.round, e.name, date(e.date), res.rider_id, r.number,
+				r.first_name, r.last_name, res.class, res.moto, res.position, res.points, res.status,
+				res.team, res.bike_brand
+			FROM results res
+			JOIN events e ON e.id = res.event_id
+			JOIN riders r ON r.id = res.rider_id`,
+		filter: ` WHERE ` + exportEventFilter,
+	},
+}
+
+// exportOrder is appended to every export so repeated downloads line up.
+var exportOrder = map[string]string{
+	"riders":    " ORDER BY r.id",
+	"events":    " ORDER BY e.season, e.round, e.id",
+	"attendees": " ORDER BY e.season, e.round, a.id",
+	"results":   " ORDER BY e.season, e.round, res.class, res.moto, res.position",
+}
+
+// ExportColumns returns the schema of a dataset and whether it exists.
+func ExportColumns(dataset string) ([]ExportColumn, bool) {
+	d, ok := exportDatasets[dataset]
+	return d.columns, ok
+}
+
+// Stream runs the export query and hands each row to fn as it comes off the
+// cursor, so memory use does not grow with the table. NULLs are passed as
+// nil. A series or season of zero exports every series or season.
+func (m *ExportModel) Stream(ctx context.Context, dataset string, seriesId, season int, fn func(row []any) error) error {
+	d, ok := exportDatasets[dataset]
+	if !ok {
+		return fmt.Errorf("unknown export %q", dataset)
+	}
+
+	query := d.query
+	var args []any
+	if seriesId != 0 || season != 0 {
+		query += d.filter
+		args = append(args, seriesId, season)
+	}
+	query += exportOrder[dataset]
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	targets := make([]any, len(d.columns))
+	for i, column := range d.columns {
+		if column.Type == "int64" {
+			targets[i] = &sql.NullInt64{}
+		} else {
+			targets[i] = &sql.NullString{}
+		}
+	}
+
+	row := make([]any, len(d.columns))
+	for rows.Next() {
+		if err := rows.Scan(targets...); err != nil {
+			return err
+		}
+
+		for i, target := range targets {
+			row[i] = nil
+			switch v := target.(type) {
+			case *sql.NullInt64:
+				if v.Valid {
+					row[i] = v.Int64
+				}
+			case *sql.NullString:
+				if v.Valid {
+					row[i] = v.String
+				}
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type LapModel struct {
+	DB DBTX
+}
+
+// Lap is one completed lap of a moto. Position is where the rider was when
+// the lap ended, or 0 when the timing system didn't say.
+type Lap struct {
+	Id          int     `json:"id"`
+	EventId     int     `json:"eventId"`
+	RiderId     int     `json:"riderId"`
+	RiderNumber int     `json:"riderNumber"`
+	Class       string  `json:"class"`
+	Moto        int     `json:"moto"`
+	Lap         int     `json:"lap"`
+	LapTimeMs   int64   `json:"lapTimeMs"`
+	SectorsMs   []int64 `json:"sectorsMs"`
+	Position    int     `json:"position"`
+}
+
+func (m *LapModel) Insert(lap *Lap) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	sectors, err := marshalSectors(lap.SectorsMs)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO laps (event_id, rider_id, class, moto, lap, lap_time_ms, sectors_ms, position) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id"
+
+	return m.DB.QueryRowContext(ctx, query, lap.EventId, lap.RiderId, lap.Class, lap.Moto, lap.Lap, lap.LapTimeMs, sectors, lap.Position).Scan(&lap.Id)
+}
+
+func (m *LapModel) Update(lap *Lap) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	sectors, err := marshalSectors(lap.SectorsMs)
+	if err != nil {
+		return err
+	}
+
+	query := "UPDATE laps SET lap_time_ms = $1, sectors_ms = $2, position = $3 WHERE id = $4"
+
+	_, err = m.DB.ExecContext(ctx, query, lap.LapTimeMs, sectors, lap.Position, lap.Id)
+	return err
+}
+
+// GetByRiderLap returns a rider's lap of a moto, or nil if it hasn't been
+// recorded.
+func (m *LapModel) GetByRiderLap(eventId, riderId int, class string, moto, lap int) (*Lap, error) {
+	query := lapQuery + " WHERE l.event_id = $1 AND l.rider_id = $2 AND l.class = $3 AND l.moto = $4 AND l.lap = $5"
+
+	laps, err := m.getLaps(query, eventId, riderId, class, moto, lap)
+	if err != nil || len(laps) == 0 {
+		return nil, err
+	}
+	return laps[0], nil
+}
+
+// GetByMoto returns every lap of a moto in the order they were completed:
+// by lap, then by position.
+func (m *LapModel) GetByMoto(eventId int, class string, moto int) ([]*Lap, error) {
+	query := lapQuery + " WHERE l.event_id = $1 AND l.class = $2 AND l.moto = $3 ORDER BY l.lap, l.position, r.number"
+	return m.getLaps(query, eventId, class, moto)
+}
+
+// GetClassesByMoto returns the classes that have laps recorded in a moto.
+func (m *LapModel) GetClassesByMoto(eventId, moto int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, "SELECT DISTINCT class FROM laps WHERE event_id = $1 AND moto = $2 ORDER BY class", eventId, moto)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var classes []string
+	for rows.Next() {
+		var class string
+		if err := rows.Scan(&class); err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+
+	return classes, rows.Err()
+}
+
+const lapQuery = `
+	SELECT l.id, l.event_id, l.rider_id, r.number, l.class, l.moto, l.lap, l.lap_time_ms, l.sectors_ms, l.position
+	FROM laps l
+	JOIN riders r ON r.id = l.rider_id
+`
+
+func (m *LapModel) getLaps(query string, args ...any) ([]*Lap, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	laps := []*Lap{}
+	for rows.Next() {
+		var lap Lap
+		var sectors string
+
+		err := rows.Scan(&lap.Id, &lap.EventId, &lap.RiderId, &lap.RiderNumber, &lap.Class, &lap.Moto, &lap.Lap, &lap.LapTimeMs, &sectors, &lap.Position)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal([]byte(sectors), &lap.SectorsMs); err != nil {
+			return nil, err
+		}
+
+		laps = append(laps, &lap)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return laps, nil
+}
+
+func marshalSectors(sectors []int64) (string, error) {
+	if sectors == nil {
+		sectors = []int64{}
+	}
+	data, err := json.Marshal(sectors)
+	return string(data), err
+}
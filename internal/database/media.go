@@ -0,0 +1,116 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	MediaHeadshot = "headshot"
+	MediaBike     = "bike"
+)
+
+type MediaModel struct {
+	DB DBTX
+}
+
+// Media is an image of a rider, such as a headshot or a photo of their bike
+// livery. The image itself lives in blob storage under Key; URL and
+// Thumbnails are left for the caller to fill.
+type Media struct {
+	Id          int               `json:"id"`
+	RiderId     int               `json:"riderId"`
+	Kind        string            `json:"kind"`
+	Key         string            `json:"-"`
+	ContentType string            `json:"contentType"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Caption     string            `json:"caption"`
+	UploadedBy  int               `json:"uploadedBy"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	URL         string            `json:"url"`
+	Thumbnails  map[string]string `json:"thumbnails"`
+}
+
+const mediaColumns = "id, rider_id, kind, blob_key, content_type, size_bytes, width, height, caption, uploaded_by, created_at"
+
+func scanMedia(row scanner, media *Media) error {
+	return row.Scan(&media.Id, &media.RiderId, &media.Kind, &media.Key, &media.ContentType, &media.Size, &media.Width, &media.Height,
+		&media.Caption, &media.UploadedBy, &media.CreatedAt)
+}
+
+func (m *MediaModel) Insert(media *Media) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO rider_media (rider_id, kind, blob_key, content_type, size_bytes, width, height, caption, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	return m.DB.QueryRowContext(ctx, query, media.RiderId, media.Kind, media.Key, media.ContentType, media.Size, media.Width, media.Height,
+		media.Caption, media.UploadedBy).Scan(&media.Id, &media.CreatedAt)
+}
+
+func (m *MediaModel) Get(id int) (*Media, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + mediaColumns + " FROM rider_media WHERE id = $1"
+
+	var media Media
+	err := scanMedia(m.DB.QueryRowContext(ctx, query, id), &media)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &media, nil
+}
+
+// GetByRider returns a rider's media, optionally of one kind, newest first.
+func (m *MediaModel) GetByRider(riderId int, kind string) ([]*Media, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + mediaColumns + " FROM rider_media WHERE rider_id = $1 AND ($2 = '' OR kind = $2) ORDER BY created_at DESC, id DESC"
+
+	rows, err := m.DB.QueryContext(ctx, query, riderId, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	media := []*Media{}
+	for rows.Next() {
+		var item Media
+		if err := scanMedia(rows, &item); err != nil {
+			return nil, err
+		}
+		media = append(media, &item)
+	}
+
+	return media, rows.Err()
+}
+
+func (m *MediaModel) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM rider_media WHERE id = $1", id)
+	return err
+}
+
+func (m *MediaModel) DeleteByRider(riderId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM rider_media WHERE rider_id = $1", riderId)
+	return err
+}
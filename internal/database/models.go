@@ -1,19 +1,92 @@
 package database
 
-import "database/sql"
+import (
+	"context"
+	"database/sql"
+)
+
+// DBTX is satisfied by both *sql.DB and *sql.Tx, so every model can run
+// inside or outside a transaction.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
 
 type Models struct {
-	Users     UserModel
-	Riders    RiderModel
-	Events    EventModel
-	Attendees AttendeeModel
+	Users      UserModel
+	Riders     RiderModel
+	Events     EventModel
+	Attendees  AttendeeModel
+	Teams      TeamModel
+	Tracks     TrackModel
+	Results    ResultModel
+	Exports    ExportModel
+	APIKeys    APIKeyModel
+	Laps       LapModel
+	Qualifying QualifyingModel
+	Awards     AwardModel
+	RiderStats RiderStatsModel
+	Penalties  PenaltyModel
+	Protests   ProtestModel
+	Overall    OverallModel
+	Numbers    NumberModel
+	Media      MediaModel
+	Series     SeriesModel
+	Scoring    ScoringModel
+
+	db *sql.DB
 }
 
 func NewModels(db *sql.DB) Models {
+	models := newModels(db)
+	models.db = db
+	return models
+}
+
+func newModels(db DBTX) Models {
 	return Models{
-		Users:     UserModel{DB: db},
-		Riders:    RiderModel{DB: db},
-		Events:    EventModel{DB: db},
-		Attendees: AttendeeModel{DB: db},
+		Users:      UserModel{DB: db},
+		Riders:     RiderModel{DB: db},
+		Events:     EventModel{DB: db},
+		Attendees:  AttendeeModel{DB: db},
+		Teams:      TeamModel{DB: db},
+		Tracks:     TrackModel{DB: db},
+		Results:    ResultModel{DB: db},
+		Exports:    ExportModel{DB: db},
+		APIKeys:    APIKeyModel{DB: db},
+		Laps:       LapModel{DB: db},
+		Qualifying: QualifyingModel{DB: db},
+		Awards:     AwardModel{DB: db},
+		RiderStats: RiderStatsModel{DB: db},
+		Penalties:  PenaltyModel{DB: db},
+		Protests:   ProtestModel{DB: db},
+		Overall:    OverallModel{DB: db},
+		Numbers:    NumberModel{DB: db},
+		Media:      MediaModel{DB: db},
+		Series:     SeriesModel{DB: db},
+		Scoring:    ScoringModel{DB: db},
+	}
+}
+
+// Transaction runs fn with models bound to a single transaction, committing
+// when fn returns nil and rolling back otherwise. Calling it on models that
+// are already inside a transaction runs fn in that same transaction.
+func (m Models) Transaction(fn func(tx Models) error) error {
+	if m.db == nil {
+		return fn(m)
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
 	}
+
+	defer tx.Rollback()
+
+	if err := fn(newModels(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
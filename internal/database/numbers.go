@@ -0,0 +1,95 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// Rider numbers run from 1 to MaxRiderNumber. ChampionNumber is kept for
+// the defending champion of the class.
+const (
+	ChampionNumber = 1
+	MaxRiderNumber = 999
+)
+
+type NumberModel struct {
+	DB DBTX
+}
+
+// RiderNumber is a number registered to a rider for a season in a class.
+type RiderNumber struct {
+	Season     int       `json:"season"`
+	Class      string    `json:"class"`
+	Number     int       `json:"number"`
+	RiderId    int       `json:"riderId"`
+	FirstName  string    `json:"firstName"`
+	LastName   string    `json:"lastName"`
+	AssignedAt time.Time `json:"assignedAt"`
+}
+
+// GetBySeason returns the numbers registered in a season and class, in
+// number order.
+func (m *NumberModel) GetBySeason(season int, class string) ([]*RiderNumber, error) {
+	return m.getNumbers("n.season = $1 AND n.class = $2 ORDER BY n.number", season, class)
+}
+
+// GetHistory returns the numbers registered in a class before a season,
+// oldest season first.
+func (m *NumberModel) GetHistory(season int, class string) ([]*RiderNumber, error) {
+	return m.getNumbers("n.season < $1 AND n.class = $2 ORDER BY n.season, n.number", season, class)
+}
+
+func (m *NumberModel) getNumbers(where string, args ...any) ([]*RiderNumber, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT n.season, n.class, n.number, n.rider_id, r.first_name, r.last_name, n.assigned_at
+		FROM rider_numbers n
+		JOIN riders r ON r.id = n.rider_id
+		WHERE ` + where
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	numbers := []*RiderNumber{}
+	for rows.Next() {
+		var number RiderNumber
+		err := rows.Scan(&number.Season, &number.Class, &number.Number, &number.RiderId, &number.FirstName, &number.LastName, &number.AssignedAt)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, &number)
+	}
+
+	return numbers, rows.Err()
+}
+
+// Assign registers a number to a rider for a season, replacing whatever
+// they had registered that season.
+func (m *NumberModel) Assign(season int, class string, number, riderId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM rider_numbers WHERE season = $1 AND rider_id = $2", season, riderId)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO rider_numbers (season, class, number, rider_id) VALUES ($1, $2, $3, $4)"
+	_, err = m.DB.ExecContext(ctx, query, season, class, number, riderId)
+	return err
+}
+
+// DeleteByRider drops every number registered to a rider.
+func (m *NumberModel) DeleteByRider(riderId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM rider_numbers WHERE rider_id = $1", riderId)
+	return err
+}
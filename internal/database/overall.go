@@ -0,0 +1,156 @@
+package database
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+type OverallModel struct {
+	DB DBTX
+}
+
+// OverallResult is a rider's overall finish in a class at an event, decided
+// from their moto results. Finishes holds their moto positions by moto,
+// with 0 for motos they have no result in.
+type OverallResult struct {
+	Id          int    `json:"id"`
+	EventId     int    `json:"eventId"`
+	Class       string `json:"class"`
+	RiderId     int    `json:"riderId"`
+	RiderNumber int    `json:"riderNumber"`
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	Position    int    `json:"position"`
+	Motos       int    `json:"motos"`
+	Combined    int    `json:"combined"`
+	Points      int    `json:"points"`
+	Finishes    []int  `json:"finishes"`
+}
+
+// Replace swaps the overall classification of an event class for the given
+// one.
+func (m *OverallModel) Replace(eventId int, class string, overall []*OverallResult) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM overall_results WHERE event_id = $1 AND class = $2", eventId, class)
+	if err != nil {
+		return err
+	}
+
+	query := "INSERT INTO overall_results (event_id, class, rider_id, position, motos, combined, points) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id"
+
+	for _, result := range overall {
+		result.EventId, result.Class = eventId, class
+		err := m.DB.QueryRowContext(ctx, query, eventId, class, result.RiderId, result.Position, result.Motos, result.Combined, result.Points).Scan(&result.Id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Recompute works out an event class's overall classification again from
+// its moto results and stores it.
+func (m *OverallModel) Recompute(eventId int, class string) error {
+	results, err := (&ResultModel{DB: m.DB}).GetByEvent(eventId)
+	if err != nil {
+		return err
+	}
+
+	var classResults []*Result
+	for _, result := range results {
+		if result.Class == class {
+			classResults = append(classResults, result)
+		}
+	}
+
+	return m.Replace(eventId, class, ClassifyOverall(classResults))
+}
+
+// GetByEvent returns the overall classification of an event, optionally for
+// one class, in finishing order. Finishes are left for the caller to fill.
+func (m *OverallModel) GetByEvent(eventId int, class string) ([]*OverallResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT o.id, o.event_id, o.class, o.rider_id, r.number, r.first_name, r.last_name, o.position, o.motos, o.combined, o.points
+		FROM overall_results o
+		JOIN riders r ON r.id = o.rider_id
+		WHERE o.event_id = $1 AND ($2 = '' OR o.class = $2)
+		ORDER BY o.class, o.position
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId, class)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	overall := []*OverallResult{}
+	for rows.Next() {
+		var result OverallResult
+		err := rows.Scan(&result.Id, &result.EventId, &result.Class, &result.RiderId, &result.RiderNumber, &result.FirstName, &result.LastName,
+			&result.Position, &result.Motos, &result.Combined, &result.Points)
+		if err != nil {
+			return nil, err
+		}
+		overall = append(overall, &result)
+	}
+
+	return overall, rows.Err()
+}
+
+// ClassifyOverall ranks riders by motos raced, then lowest combined finish,
+// then their finish in the last moto. Riders without a last moto result
+// lose that tie-break.
+func ClassifyOverall(results []*Result) []*OverallResult {
+	lastMoto := 0
+	for _, result := range results {
+		lastMoto = max(lastMoto, result.Moto)
+	}
+
+	byRider := map[int]*OverallResult{}
+	last := map[int]int{}
+	overall := []*OverallResult{}
+
+	for _, result := range results {
+		rider, ok := byRider[result.RiderId]
+		if !ok {
+			rider = &OverallResult{EventId: result.EventId, Class: result.Class, RiderId: result.RiderId}
+			byRider[result.RiderId] = rider
+			overall = append(overall, rider)
+			last[result.RiderId] = 999
+		}
+		rider.Motos++
+		rider.Combined += result.Position
+		rider.Points += result.Points
+		if result.Moto == lastMoto {
+			last[result.RiderId] = result.Position
+		}
+	}
+
+	sort.Slice(overall, func(i, j int) bool {
+		a, b := overall[i], overall[j]
+		if a.Motos != b.Motos {
+			return a.Motos > b.Motos
+		}
+		if a.Combined != b.Combined {
+			return a.Combined < b.Combined
+		}
+		if last[a.RiderId] != last[b.RiderId] {
+			return last[a.RiderId] < last[b.RiderId]
+		}
+		return a.RiderId < b.RiderId
+	})
+
+	for i, rider := range overall {
+		rider.Position = i + 1
+	}
+
+	return overall
+}
@@ -0,0 +1,200 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Penalty types. Value is the places dropped, milliseconds added or points
+// deducted; disqualifications carry no value.
+const (
+	PenaltyPositions = "positions"
+	PenaltyTime      = "time"
+	PenaltyPoints    = "points"
+	PenaltyDQ        = "dq"
+)
+
+// Revision states. Results are provisional until declared official, and any
+// change after that is an amendment.
+const (
+	RevisionProvisional = "provisional"
+	RevisionOfficial    = "official"
+	RevisionAmended     = "amended"
+)
+
+type PenaltyModel struct {
+	DB DBTX
+}
+
+type Penalty struct {
+	Id          int        `json:"id"`
+	EventId     int        `json:"eventId"`
+	Class       string     `json:"class"`
+	Moto        int        `json:"moto"`
+	RiderId     int        `json:"riderId"`
+	Type        string     `json:"type"`
+	Value       int        `json:"value"`
+	Reason      string     `json:"reason"`
+	IssuedBy    int        `json:"issuedBy"`
+	ProtestId   *int       `json:"protestId"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	RescindedAt *time.Time `json:"rescindedAt"`
+	RescindedBy *int       `json:"rescindedBy"`
+}
+
+// Revision is a snapshot of a moto's classified results.
+type Revision struct {
+	Id        int       `json:"id"`
+	EventId   int       `json:"eventId"`
+	Class     string    `json:"class"`
+	Moto      int       `json:"moto"`
+	Revision  int       `json:"revision"`
+	State     string    `json:"state"`
+	Reason    string    `json:"reason"`
+	Results   []*Result `json:"results"`
+	CreatedBy *int      `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+const penaltyColumns = "id, event_id, class, moto, rider_id, type, value, reason, issued_by, protest_id, created_at, rescinded_at, rescinded_by"
+
+func scanPenalty(row scanner, penalty *Penalty) error {
+	return row.Scan(&penalty.Id, &penalty.EventId, &penalty.Class, &penalty.Moto, &penalty.RiderId, &penalty.Type, &penalty.Value, &penalty.Reason,
+		&penalty.IssuedBy, &penalty.ProtestId, &penalty.CreatedAt, &penalty.RescindedAt, &penalty.RescindedBy)
+}
+
+func (m *PenaltyModel) Insert(penalty *Penalty) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO penalties (event_id, class, moto, rider_id, type, value, reason, issued_by, protest_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	return m.DB.QueryRowContext(ctx, query, penalty.EventId, penalty.Class, penalty.Moto, penalty.RiderId, penalty.Type, penalty.Value,
+		penalty.Reason, penalty.IssuedBy, penalty.ProtestId).Scan(&penalty.Id, &penalty.CreatedAt)
+}
+
+func (m *PenaltyModel) Get(id int) (*Penalty, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var penalty Penalty
+	err := scanPenalty(m.DB.QueryRowContext(ctx, "SELECT "+penaltyColumns+" FROM penalties WHERE id = $1", id), &penalty)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &penalty, nil
+}
+
+// GetByEvent returns every penalty of an event, rescinded ones included, in
+// the order they were issued.
+func (m *PenaltyModel) GetByEvent(eventId int) ([]*Penalty, error) {
+	return m.getPenalties("SELECT "+penaltyColumns+" FROM penalties WHERE event_id = $1 ORDER BY id", eventId)
+}
+
+// GetActiveByMoto returns the penalties of a moto that still stand, in the
+// order they were issued.
+func (m *PenaltyModel) GetActiveByMoto(eventId int, class string, moto int) ([]*Penalty, error) {
+	query := "SELECT " + penaltyColumns + " FROM penalties WHERE event_id = $1 AND class = $2 AND moto = $3 AND rescinded_at IS NULL ORDER BY id"
+	return m.getPenalties(query, eventId, class, moto)
+}
+
+// Rescind withdraws a penalty. It stays on record with who withdrew it.
+func (m *PenaltyModel) Rescind(penalty *Penalty, userId int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "UPDATE penalties SET rescinded_at = CURRENT_TIMESTAMP, rescinded_by = $1 WHERE id = $2 RETURNING rescinded_at, rescinded_by"
+
+	return m.DB.QueryRowContext(ctx, query, userId, penalty.Id).Scan(&penalty.RescindedAt, &penalty.RescindedBy)
+}
+
+func (m *PenaltyModel) getPenalties(query string, args ...any) ([]*Penalty, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	penalties := []*Penalty{}
+	for rows.Next() {
+		var penalty Penalty
+		if err := scanPenalty(rows, &penalty); err != nil {
+			return nil, err
+		}
+		penalties = append(penalties, &penalty)
+	}
+
+	return penalties, rows.Err()
+}
+
+// InsertRevision records a snapshot of a moto's results as its next
+// revision.
+func (m *PenaltyModel) InsertRevision(revision *Revision) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	results, err := json.Marshal(revision.Results)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO result_revisions (event_id, class, moto, revision, state, reason, results, created_by)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(revision), 0) + 1 FROM result_revisions WHERE event_id = $1 AND class = $2 AND moto = $3), $4, $5, $6, $7)
+		RETURNING id, revision, created_at
+	`
+
+	return m.DB.QueryRowContext(ctx, query, revision.EventId, revision.Class, revision.Moto, revision.State, revision.Reason, string(results), revision.CreatedBy).
+		Scan(&revision.Id, &revision.Revision, &revision.CreatedAt)
+}
+
+// GetRevisions returns a moto's result history, oldest first.
+func (m *PenaltyModel) GetRevisions(eventId int, class string, moto int) ([]*Revision, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT id, event_id, class, moto, revision, state, reason, results, created_by, created_at
+		FROM result_revisions
+		WHERE event_id = $1 AND class = $2 AND moto = $3
+		ORDER BY revision
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId, class, moto)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	revisions := []*Revision{}
+	for rows.Next() {
+		var revision Revision
+		var results string
+		err := rows.Scan(&revision.Id, &revision.EventId, &revision.Class, &revision.Moto, &revision.Revision, &revision.State,
+			&revision.Reason, &results, &revision.CreatedBy, &revision.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(results), &revision.Results); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &revision)
+	}
+
+	return revisions, rows.Err()
+}
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	ProtestFiled       = "filed"
+	ProtestUnderReview = "under_review"
+	ProtestUpheld      = "upheld"
+	ProtestDenied      = "denied"
+)
+
+// protestTransitions lists the statuses a protest can move to from each
+// status. Upheld and denied protests are closed.
+var protestTransitions = map[string][]string{
+	ProtestFiled:       {ProtestUnderReview, ProtestUpheld, ProtestDenied},
+	ProtestUnderReview: {ProtestUpheld, ProtestDenied},
+}
+
+// ProtestCanMove reports whether a protest can move from one status to another.
+func ProtestCanMove(from, to string) bool {
+	for _, status := range protestTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+type ProtestModel struct {
+	DB DBTX
+}
+
+// Protest challenges a moto result, optionally that of one rider.
+type Protest struct {
+	Id        int       `json:"id"`
+	EventId   int       `json:"eventId"`
+	Class     string    `json:"class"`
+	Moto      int       `json:"moto"`
+	RiderId   *int      `json:"riderId"`
+	FiledBy   int       `json:"filedBy"`
+	Reason    string    `json:"reason"`
+	Status    string    `json:"status"`
+	Decision  string    `json:"decision"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+const protestColumns = "id, event_id, class, moto, rider_id, filed_by, reason, status, decision, created_at, updated_at"
+
+func scanProtest(row scanner, protest *Protest) error {
+	return row.Scan(&protest.Id, &protest.EventId, &protest.Class, &protest.Moto, &protest.RiderId, &protest.FiledBy, &protest.Reason,
+		&protest.Status, &protest.Decision, &protest.CreatedAt, &protest.UpdatedAt)
+}
+
+func (m *ProtestModel) Insert(protest *Protest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO protests (event_id, class, moto, rider_id, filed_by, reason, status)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at
+	`
+
+	protest.Status = ProtestFiled
+
+	return m.DB.QueryRowContext(ctx, query, protest.EventId, protest.Class, protest.Moto, protest.RiderId, protest.FiledBy, protest.Reason, protest.Status).
+		Scan(&protest.Id, &protest.CreatedAt, &protest.UpdatedAt)
+}
+
+func (m *ProtestModel) Get(id int) (*Protest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var protest Protest
+	err := scanProtest(m.DB.QueryRowContext(ctx, "SELECT "+protestColumns+" FROM protests WHERE id = $1", id), &protest)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &protest, nil
+}
+
+// GetByEvent returns the protests of an event, optionally only those with
+// the given status, oldest first.
+func (m *ProtestModel) GetByEvent(eventId int, status string) ([]*Protest, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + protestColumns + " FROM protests WHERE event_id = $1 AND ($2 = '' OR status = $2) ORDER BY id"
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId, status)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	protests := []*Protest{}
+	for rows.Next() {
+		var protest Protest
+		if err := scanProtest(rows, &protest); err != nil {
+			return nil, err
+		}
+		protests = append(protests, &protest)
+	}
+
+	return protests, rows.Err()
+}
+
+// UpdateStatus moves a protest to a new status with the stewards' decision.
+func (m *ProtestModel) UpdateStatus(protest *Protest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "UPDATE protests SET status = $1, decision = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $3 RETURNING updated_at"
+
+	return m.DB.QueryRowContext(ctx, query, protest.Status, protest.Decision, protest.Id).Scan(&protest.UpdatedAt)
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// QualifyingCut is how many riders per class make the motos.
+const QualifyingCut = 40
+
+type QualifyingModel struct {
+	DB DBTX
+}
+
+type QualifyingSession struct {
+	Id      int    `json:"id"`
+	EventId int    `json:"eventId"`
+	Class   string `json:"class" binding:"required"`
+	Number  int    `json:"number" binding:"required,min=1"`
+	Name    string `json:"name"`
+}
+
+// QualifyingTime is a rider's best lap in one session. The rider's number
+// and name are filled in when reading so rankings can be shown as is.
+type QualifyingTime struct {
+	Id          int    `json:"id"`
+	SessionId   int    `json:"sessionId"`
+	RiderId     int    `json:"riderId"`
+	RiderNumber int    `json:"riderNumber"`
+	FirstName   string `json:"firstName"`
+	LastName    string `json:"lastName"`
+	BestLapMs   int64  `json:"bestLapMs"`
+}
+
+func (m *QualifyingModel) InsertSession(session *QualifyingSession) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "INSERT INTO qualifying_sessions (event_id, class, number, name) VALUES ($1, $2, $3, $4) RETURNING id"
+
+	return m.DB.QueryRowContext(ctx, query, session.EventId, session.Class, session.Number, session.Name).Scan(&session.Id)
+}
+
+func (m *QualifyingModel) GetSession(id int) (*QualifyingSession, error) {
+	return m.getSession("SELECT id, event_id, class, number, name FROM qualifying_sessions WHERE id = $1", id)
+}
+
+func (m *QualifyingModel) GetSessionByNumber(eventId int, class string, number int) (*QualifyingSession, error) {
+	return m.getSession("SELECT id, event_id, class, number, name FROM qualifying_sessions WHERE event_id = $1 AND class = $2 AND number = $3", eventId, class, number)
+}
+
+func (m *QualifyingModel) getSession(query string, args ...any) (*QualifyingSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var session QualifyingSession
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&session.Id, &session.EventId, &session.Class, &session.Number, &session.Name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// GetSessionsByEvent returns an event's sessions ordered by class and
+// session number.
+func (m *QualifyingModel) GetSessionsByEvent(eventId int) ([]*QualifyingSession, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT id, event_id, class, number, name FROM qualifying_sessions WHERE event_id = $1 ORDER BY class, number"
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	sessions := []*QualifyingSession{}
+	for rows.Next() {
+		var session QualifyingSession
+		if err := rows.Scan(&session.Id, &session.EventId, &session.Class, &session.Number, &session.Name); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// SaveTime records a rider's best lap in a session, replacing an earlier
+// time for the same rider.
+func (m *QualifyingModel) SaveTime(qualifyingTime *QualifyingTime) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		INSERT INTO qualifying_results (session_id, rider_id, best_lap_ms) VALUES ($1, $2, $3)
+		ON CONFLICT (session_id, rider_id) DO UPDATE SET best_lap_ms = excluded.best_lap_ms
+		RETURNING id
+	`
+
+	return m.DB.QueryRowContext(ctx, query, qualifyingTime.SessionId, qualifyingTime.RiderId, qualifyingTime.BestLapMs).Scan(&qualifyingTime.Id)
+}
+
+// GetTimesByEvent returns every qualifying time of an event, fastest first
+// within each session.
+func (m *QualifyingModel) GetTimesByEvent(eventId int) ([]*QualifyingTime, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT q.id, q.session_id, q.rider_id, r.number, r.first_name, r.last_name, q.best_lap_ms
+		FROM qualifying_results q
+		JOIN qualifying_sessions s ON s.id = q.session_id
+		JOIN riders r ON r.id = q.rider_id
+		WHERE s.event_id = $1
+		ORDER BY s.class, s.number, q.best_lap_ms, r.number
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	times := []*QualifyingTime{}
+	for rows.Next() {
+		var t QualifyingTime
+		if err := rows.Scan(&t.Id, &t.SessionId, &t.RiderId, &t.RiderNumber, &t.FirstName, &t.LastName, &t.BestLapMs); err != nil {
+			return nil, err
+		}
+		times = append(times, &t)
+	}
+
+	return times, rows.Err()
+}
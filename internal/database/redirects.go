@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// riderTables are the tables that point at a rider, in the order a merge
+// moves them. Unique tables can hold only one row per rider for the same
+// thing, so the duplicate's row is dropped where the canonical rider
+// already has one.
+var riderTables = []struct {
+	name   string
+	unique bool
+}{
+	{"results", true},
+	{"overall_results", true},
+	{"laps", true},
+	{"laps_led", true},
+	{"holeshots", false},
+	{"qualifying_results", true},
+	{"penalties", false},
+	{"protests", false},
+	{"rider_numbers", true},
+	{"rider_media", false},
+}
+
+// RacedTogether returns a result of the rider in a moto the other rider
+// also raced, or nil when they never met on the track. Riders who did
+// can't be the same person.
+func (m *RiderModel) RacedTogether(riderId, otherId int) (*Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT ` + resultColumns + `
+		FROM results
+		WHERE rider_id = $1 AND EXISTS (
+			SELECT 1 FROM results other
+			WHERE other.rider_id = $2 AND other.event_id = results.event_id AND other.class = results.class AND other.moto = results.moto
+		)
+		ORDER BY event_id, class, moto
+		LIMIT 1
+	`
+
+	var result Result
+	err := scanResult(m.DB.QueryRowContext(ctx, query, riderId, otherId), &result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Merge folds a duplicate rider into the canonical one: everything that
+// points at the duplicate is moved over, details the canonical rider is
+// missing are filled in from the duplicate, and the duplicate is replaced
+// by a redirect so its Id still leads somewhere. It returns how many rows
+// of each table were moved. Run it in a transaction.
+func (m *RiderModel) Merge(canonicalId, duplicateId, mergedBy int) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	moved := map[string]int64{}
+
+	// Entries have no unique key, but a rider enters an event once.
+	query := "DELETE FROM attendees WHERE rider_id = $1 AND event_id IN (SELECT event_id FROM attendees WHERE rider_id = $2)"
+	if _, err := m.DB.ExecContext(ctx, query, duplicateId, canonicalId); err != nil {
+		return nil, err
+	}
+	res, err := m.DB.ExecContext(ctx, "UPDATE attendees SET rider_id = $1 WHERE rider_id = $2", canonicalId, duplicateId)
+	if err != nil {
+		return nil, err
+	}
+	if moved["attendees"], err = res.RowsAffected(); err != nil {
+		return nil, err
+	}
+
+	for _, table := range riderTables {
+		update := "UPDATE "
+		if table.unique {
+			update = "UPDATE OR IGNORE "
+		}
+		res, err := m.DB.ExecContext(ctx, update+table.name+" SET rider_id = $1 WHERE rider_id = $2", canonicalId, duplicateId)
+		if err != nil {
+			return nil, err
+		}
+		if moved[table.name], err = res.RowsAffected(); err != nil {
+			return nil, err
+		}
+		if table.unique {
+			if _, err := m.DB.ExecContext(ctx, "DELETE FROM "+table.name+" WHERE rider_id = $1", duplicateId); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	query = `
+		UPDATE riders SET
+			team = COALESCE(NULLIF(riders.team, ''), d.team),
+			bike_brand = COALESCE(NULLIF(riders.bike_brand, ''), d.bike_brand),
+			class = COALESCE(NULLIF(riders.class, ''), d.class),
+			nationality = COALESCE(NULLIF(riders.nationality, ''), d.nationality),
+			date_of_birth = COALESCE(NULLIF(riders.date_of_birth, ''), d.date_of_birth)
+		FROM (SELECT team, bike_brand, class, nationality, date_of_birth FROM riders WHERE id = $1) AS d
+		WHERE riders.id = $2
+	`
+	if _, err := m.DB.ExecContext(ctx, query, duplicateId, canonicalId); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.DB.ExecContext(ctx, "UPDATE rider_redirects SET to_id = $1 WHERE to_id = $2", canonicalId, duplicateId); err != nil {
+		return nil, err
+	}
+	query = "INSERT INTO rider_redirects (from_id, to_id, merged_by) VALUES ($1, $2, $3)"
+	if _, err := m.DB.ExecContext(ctx, query, duplicateId, canonicalId, mergedBy); err != nil {
+		return nil, err
+	}
+
+	if _, err := m.DB.ExecContext(ctx, "DELETE FROM riders WHERE id = $1", duplicateId); err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// Redirect returns the rider a merged rider's Id now leads to, or zero
+// when the Id was never merged away.
+func (m *RiderModel) Redirect(id int) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var toId int
+	err := m.DB.QueryRowContext(ctx, "SELECT to_id FROM rider_redirects WHERE from_id = $1", id).Scan(&toId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return toId, nil
+}
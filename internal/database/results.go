@@ -0,0 +1,275 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+const (
+	ResultFinished = "finished"
+	ResultDNF      = "dnf"
+	ResultDNS      = "dns"
+	ResultDQ       = "dq"
+)
+
+// MotoPoints is the Pro Motocross points paid per moto, indexed by finishing
+// position minus one. Positions outside the table score nothing.
+var MotoPoints = []int{25, 22, 20, 18, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+
+// MotosPerEvent is how many points-paying motos each class runs per round.
+const MotosPerEvent = 2
+
+func PointsForPosition(position int) int {
+	if position < 1 || position > len(MotoPoints) {
+		return 0
+	}
+	return MotoPoints[position-1]
+}
+
+type ResultModel struct {
+	DB DBTX
+}
+
+// Result is a rider's classified result in a moto. FinishPosition and
+// FinishStatus are how they crossed the line, before any penalties.
+type Result struct {
+	Id             int    `json:"id"`
+	EventId        int    `json:"eventId"`
+	RiderId        int    `json:"riderId" binding:"required"`
+	Class          string `json:"class" binding:"required"`
+	Moto           int    `json:"moto" binding:"required,min=1"`
+	Position       int    `json:"position" binding:"required,min=1"`
+	Points         int    `json:"points"`
+	Status         string `json:"status"`
+	Team           string `json:"team"`
+	BikeBrand      string `json:"bikeBrand"`
+	FinishPosition int    `json:"finishPosition"`
+	FinishStatus   string `json:"finishStatus"`
+}
+
+// resultColumns are the columns read by scanResult, in scan order.
+const resultColumns = "id, event_id, rider_id, class, moto, position, points, status, team, bike_brand, finish_position, finish_status"
+
+func scanResult(row scanner, result *Result) error {
+	return row.Scan(&result.Id, &result.EventId, &result.RiderId, &result.Class, &result.Moto, &result.Position, &result.Points, &result.Status, &result.Team, &result.BikeBrand, &result.FinishPosition, &result.FinishStatus)
+}
+
+// finish defaults how the rider finished to their classified result.
+func (result *Result) finish() (int, string) {
+	if result.FinishPosition == 0 {
+		result.FinishPosition = result.Position
+	}
+	if result.FinishStatus == "" {
+		result.FinishStatus = result.Status
+	}
+	return result.FinishPosition, result.FinishStatus
+}
+
+func (m *ResultModel) Insert(result *Result) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "INSERT INTO results (event_id, rider_id, class, moto, position, points, status, team, bike_brand, finish_position, finish_status) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id"
+
+	position, status := result.finish()
+
+	return m.DB.QueryRowContext(ctx, query, result.EventId, result.RiderId, result.Class, result.Moto, result.Position, result.Points, result.Status, result.Team, result.BikeBrand, position, status).Scan(&result.Id)
+}
+
+func (m *ResultModel) Get(id int) (*Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + resultColumns + " FROM results WHERE id = $1"
+
+	var result Result
+
+	err := scanResult(m.DB.QueryRowContext(ctx, query, id), &result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *ResultModel) GetByEventRiderMoto(eventId, riderId int, class string, moto int) (*Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + resultColumns + " FROM results WHERE event_id = $1 AND rider_id = $2 AND class = $3 AND moto = $4"
+
+	var result Result
+
+	err := scanResult(m.DB.QueryRowContext(ctx, query, eventId, riderId, class, moto), &result)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (m *ResultModel) GetByEvent(eventId int) ([]*Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + resultColumns + " FROM results WHERE event_id = $1 ORDER BY class, moto, position"
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	results := []*Result{}
+
+	for rows.Next() {
+		var result Result
+
+		err := scanResult(rows, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, &result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// GetByMoto returns the results of one class's moto in classified order.
+func (m *ResultModel) GetByMoto(eventId int, class string, moto int) ([]*Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT " + resultColumns + " FROM results WHERE event_id = $1 AND class = $2 AND moto = $3 ORDER BY position"
+
+	rows, err := m.DB.QueryContext(ctx, query, eventId, class, moto)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	results := []*Result{}
+	for rows.Next() {
+		var result Result
+		if err := scanResult(rows, &result); err != nil {
+			return nil, err
+		}
+		results = append(results, &result)
+	}
+
+	return results, rows.Err()
+}
+
+func (m *ResultModel) Update(result *Result) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "UPDATE results SET position = $1, points = $2, status = $3, team = $4, bike_brand = $5, finish_position = $6, finish_status = $7 WHERE id = $8"
+
+	position, status := result.finish()
+
+	_, err := m.DB.ExecContext(ctx, query, result.Position, result.Points, result.Status, result.Team, result.BikeBrand, position, status, result.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *ResultModel) Delete(id int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "DELETE FROM results WHERE id = $1"
+
+	_, err := m.DB.ExecContext(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RaceResult is a result along with the event it was raced at.
+type RaceResult struct {
+	Result
+	SeriesId  int    `json:"seriesId"`
+	Season    int    `json:"season"`
+	Round     int    `json:"round"`
+	EventName string `json:"eventName"`
+	Track     string `json:"track"`
+}
+
+// GetByRider returns every result of a rider in race order. Track is the
+// track's name, or the event location for events without a track.
+func (m *ResultModel) GetByRider(riderId int) ([]*RaceResult, error) {
+	return m.getRaceResults("res.rider_id = $1", riderId)
+}
+
+// GetCountingByRider returns a rider's results like GetByRider, leaving out
+// cancelled events, which don't count towards any standings.
+func (m *ResultModel) GetCountingByRider(riderId int) ([]*RaceResult, error) {
+	return m.getRaceResults("res.rider_id = $1 AND e.status <> 'cancelled'", riderId)
+}
+
+// GetBySeason returns every result of a series' season in race order,
+// leaving out cancelled events, which don't count towards any standings.
+func (m *ResultModel) GetBySeason(seriesId, season int) ([]*RaceResult, error) {
+	return m.getRaceResults("e.series_id = $1 AND e.season = $2 AND e.status <> 'cancelled'", seriesId, season)
+}
+
+func (m *ResultModel) getRaceResults(where string, args ...any) ([]*RaceResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT res.id, res.event_id, res.rider_id, res.class, res.moto, res.position, res.points, res.status,
+			res.team, res.bike_brand, res.finish_position, res.finish_status, e.series_id, e.season, e.round, e.name, COALESCE(t.name, e.location)
+		FROM results res
+		JOIN events e ON e.id = res.event_id
+		LEFT JOIN tracks t ON t.id = e.track_id
+		WHERE ` + where + `
+		ORDER BY e.season, e.round, e.date, res.class, res.moto, res.position
+	`
+
+	rows, err := m.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	results := []*RaceResult{}
+
+	for rows.Next() {
+		var result RaceResult
+
+		err := rows.Scan(&result.Id, &result.EventId, &result.RiderId, &result.Class, &result.Moto, &result.Position, &result.Points, &result.Status,
+			&result.Team, &result.BikeBrand, &result.FinishPosition, &result.FinishStatus, &result.SeriesId, &result.Season, &result.Round, &result.EventName, &result.Track)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, &result)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -7,7 +7,7 @@ import (
 )
 
 type RiderModel struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 type Rider struct {
@@ -34,13 +34,22 @@ func (m *RiderModel) Insert(rider *Rider) error {
 	return m.DB.QueryRowContext(ctx, query, rider.OwnerId, rider.FirstName, rider.LastName, rider.Number, rider.Team, rider.BikeBrand, rider.Class, rider.Nationality, rider.DateOfBirth, rider.CareerPoints, rider.Status).Scan(&rider.Id)
 }
 
-func (m *RiderModel) GetAll() ([]*Rider, error) {
+// GetAll returns the riders who have entered or raced in a series, or every
+// rider when seriesId is zero.
+func (m *RiderModel) GetAll(seriesId int) ([]*Rider, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
 
-	query := "SELECT * FROM riders"
+	query := `
+		SELECT * FROM riders
+		WHERE $1 = 0 OR id IN (
+			SELECT a.rider_id FROM attendees a JOIN events e ON e.id = a.event_id WHERE e.series_id = $1
+			UNION
+			SELECT res.rider_id FROM results res JOIN events e ON e.id = res.event_id WHERE e.series_id = $1
+		)
+	`
 
-	rows, err := m.DB.QueryContext(ctx, query)
+	rows, err := m.DB.QueryContext(ctx, query, seriesId)
 	if err != nil {
 		return nil, err
 	}
@@ -86,6 +95,25 @@ func (m *RiderModel) Get(id int) (*Rider, error) {
 	return &rider, nil
 }
 
+func (m *RiderModel) GetByNumberAndName(number int, firstName, lastName string) (*Rider, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT * FROM riders WHERE number = $1 AND first_name = $2 COLLATE NOCASE AND last_name = $3 COLLATE NOCASE"
+
+	var rider Rider
+
+	err := m.DB.QueryRowContext(ctx, query, number, firstName, lastName).Scan(&rider.Id, &rider.OwnerId, &rider.FirstName, &rider.LastName, &rider.Number, &rider.Team, &rider.BikeBrand, &rider.Class, &rider.Nationality, &rider.DateOfBirth, &rider.CareerPoints, &rider.Status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rider, nil
+}
+
 func (m *RiderModel) Update(rider *Rider) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// RiderStatLine is a rider's record in one class of a series, for a season
+// or, with Season 0, their whole career. Wins, podiums and top-Ns count
+// overall event finishes; moto wins and DNFs count individual motos.
+type RiderStatLine struct {
+	Series            string  `json:"series"`
+	Season            int     `json:"season,omitempty"`
+	Class             string  `json:"class"`
+	Events            int     `json:"events"`
+	Wins              int     `json:"wins"`
+	Podiums           int     `json:"podiums"`
+	Top5s             int     `json:"top5s"`
+	Top10s            int     `json:"top10s"`
+	Motos             int     `json:"motos"`
+	MotoWins          int     `json:"motoWins"`
+	DNFs              int     `json:"dnfs"`
+	DNFRate           float64 `json:"dnfRate"`
+	AverageFinish     float64 `json:"averageFinish"`
+	AverageMotoFinish float64 `json:"averageMotoFinish"`
+	Points            int     `json:"points"`
+	Championships     int     `json:"championships"`
+
+	positionSum   int
+	motoFinishSum int
+	motosFinished int
+}
+
+// Championship is a season title in a class of a series.
+type Championship struct {
+	Series string `json:"series"`
+	Season int    `json:"season"`
+	Class  string `json:"class"`
+}
+
+// RacedSeason is a season class of a series a rider has results in.
+type RacedSeason struct {
+	SeriesId int
+	Season   int
+	Class    string
+}
+
+type RiderStatsModel struct {
+	DB DBTX
+}
+
+// riderSeasonStatsQuery adds up the rider's overall finishes per series,
+// season and class, with their moto record alongside. Cancelled events
+// don't count, as they don't in the standings.
+const riderSeasonStatsQuery = `
+	WITH motos AS (
+		SELECT res.event_id, res.class,
+			COUNT(*) AS motos,
+			SUM(res.status = 'finished' AND res.position = 1) AS moto_wins,
+			SUM(res.status = 'dnf') AS dnfs,
+			COALESCE(SUM(CASE WHEN res.status = 'finished' THEN res.position END), 0) AS finish_sum,
+			SUM(res.status = 'finished') AS finished
+		FROM results res
+		JOIN events e ON e.id = res.event_id AND e.status <> 'cancelled'
+		WHERE res.rider_id = $1
+		GROUP BY res.event_id, res.class
+	)
+	SELECT s.slug, e.season, o.class, COUNT(*),
+		SUM(o.position = 1), SUM(o.position <= 3), SUM(o.position <= 5), SUM(o.position <= 10),
+		SUM(o.position), SUM(o.points), SUM(m.motos), SUM(m.moto_wins), SUM(m.dnfs), SUM(m.finish_sum), SUM(m.finished)
+	FROM overall_results o
+	JOIN events e ON e.id = o.event_id AND e.status <> 'cancelled'
+	JOIN series s ON s.id = e.series_id
+	JOIN motos m ON m.event_id = o.event_id AND m.class = o.class
+	WHERE o.rider_id = $1
+	GROUP BY e.series_id, e.season, o.class
+	ORDER BY e.season, e.series_id, o.class
+`
+
+// riderRacedSeasonsQuery lists the season classes of each series the rider
+// has results in.
+const riderRacedSeasonsQuery = `
+	SELECT DISTINCT e.series_id, e.season, res.class
+	FROM results res
+	JOIN events e ON e.id = res.event_id
+	WHERE res.rider_id = $1
+	ORDER BY e.season, e.series_id, res.class
+`
+
+// GetSeasons returns a rider's record per series, season and class, oldest
+// first.
+func (m *RiderStatsModel) GetSeasons(riderId int) ([]*RiderStatLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, riderSeasonStatsQuery, riderId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	lines := []*RiderStatLine{}
+	for rows.Next() {
+		var line RiderStatLine
+		err := rows.Scan(&line.Series, &line.Season, &line.Class, &line.Events, &line.Wins, &line.Podiums, &line.Top5s, &line.Top10s,
+			&line.positionSum, &line.Points, &line.Motos, &line.MotoWins, &line.DNFs, &line.motoFinishSum, &line.motosFinished)
+		if err != nil {
+			return nil, err
+		}
+		line.average()
+		lines = append(lines, &line)
+	}
+
+	return lines, rows.Err()
+}
+
+// GetRacedSeasons returns the season classes a rider has raced, oldest
+// first.
+func (m *RiderStatsModel) GetRacedSeasons(riderId int) ([]RacedSeason, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, riderRacedSeasonsQuery, riderId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	seasons := []RacedSeason{}
+	for rows.Next() {
+		var season RacedSeason
+		if err := rows.Scan(&season.SeriesId, &season.Season, &season.Class); err != nil {
+			return nil, err
+		}
+		seasons = append(seasons, season)
+	}
+
+	return seasons, rows.Err()
+}
+
+// Career adds season lines up per series and class, and counts each
+// championship against the season and career it was won in.
+func Career(seasons []*RiderStatLine, championships []Championship) []*RiderStatLine {
+	type seriesClass struct {
+		series string
+		class  string
+	}
+	byClass := map[seriesClass]*RiderStatLine{}
+	career := []*RiderStatLine{}
+
+	for _, season := range seasons {
+		key := seriesClass{season.Series, season.Class}
+		line, ok := byClass[key]
+		if !ok {
+			line = &RiderStatLine{Series: season.Series, Class: season.Class}
+			byClass[key] = line
+			career = append(career, line)
+		}
+
+		line.Events += season.Events
+		line.Wins += season.Wins
+		line.Podiums += season.Podiums
+		line.Top5s += season.Top5s
+		line.Top10s += season.Top10s
+		line.Motos += season.Motos
+		line.MotoWins += season.MotoWins
+		line.DNFs += season.DNFs
+		line.Points += season.Points
+		line.positionSum += season.positionSum
+		line.motoFinishSum += season.motoFinishSum
+		line.motosFinished += season.motosFinished
+	}
+
+	for _, championship := range championships {
+		for _, season := range seasons {
+			if season.Series == championship.Series && season.Season == championship.Season && season.Class == championship.Class {
+				season.Championships++
+			}
+		}
+		if line, ok := byClass[seriesClass{championship.Series, championship.Class}]; ok {
+			line.Championships++
+		}
+	}
+
+	for _, line := range career {
+		line.average()
+	}
+
+	return career
+}
+
+func (line *RiderStatLine) average() {
+	if line.Events > 0 {
+		line.AverageFinish = round2(float64(line.positionSum) / float64(line.Events))
+	}
+	if line.motosFinished > 0 {
+		line.AverageMotoFinish = round2(float64(line.motoFinishSum) / float64(line.motosFinished))
+	}
+	if line.Motos > 0 {
+		line.DNFRate = round2(float64(line.DNFs) / float64(line.Motos))
+	}
+}
+
+func round2(f float64) float64 {
+	return math.Round(f*100) / 100
+}
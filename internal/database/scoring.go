@@ -0,0 +1,83 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/bcantrell1/pro-motocross-api/internal/scoring"
+)
+
+type ScoringModel struct {
+	DB DBTX
+}
+
+// ScoringRules are the points rules a series scores a season by. Rules
+// saved for season 0 cover every season of the series without their own.
+type ScoringRules struct {
+	SeriesId  int           `json:"seriesId"`
+	Season    int           `json:"season"`
+	Rules     scoring.Rules `json:"rules"`
+	UpdatedBy *int          `json:"updatedBy"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// Get returns the rules for a series' season, falling back to the series'
+// season 0 rules, or nil when it has neither.
+func (m *ScoringModel) Get(seriesId, season int) (*ScoringRules, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := `
+		SELECT series_id, season, rules, updated_by, updated_at
+		FROM scoring_rules
+		WHERE series_id = $1 AND season IN ($2, 0)
+		ORDER BY season DESC
+		LIMIT 1
+	`
+
+	var rules ScoringRules
+	var encoded string
+	err := m.DB.QueryRowContext(ctx, query, seriesId, season).Scan(&rules.SeriesId, &rules.Season, &encoded, &rules.UpdatedBy, &rules.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := json.Unmarshal([]byte(encoded), &rules.Rules); err != nil {
+		return nil, err
+	}
+
+	return &rules, nil
+}
+
+// Save stores the rules for a series' season, replacing any already there.
+func (m *ScoringModel) Save(rules *ScoringRules) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	encoded, err := json.Marshal(rules.Rules)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO scoring_rules (series_id, season, rules, updated_by) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (series_id, season) DO UPDATE SET rules = excluded.rules, updated_by = excluded.updated_by, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+
+	return m.DB.QueryRowContext(ctx, query, rules.SeriesId, rules.Season, string(encoded), rules.UpdatedBy).Scan(&rules.UpdatedAt)
+}
+
+// Delete removes the rules saved for a series' season.
+func (m *ScoringModel) Delete(seriesId, season int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	_, err := m.DB.ExecContext(ctx, "DELETE FROM scoring_rules WHERE series_id = $1 AND season = $2", seriesId, season)
+	return err
+}
@@ -0,0 +1,164 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// DefaultSeriesId is Pro Motocross, created with the series table. Events
+// from before there were series belong to it.
+const DefaultSeriesId = 1
+
+type SeriesModel struct {
+	DB DBTX
+}
+
+// Series is a championship, such as Pro Motocross or Supercross, with its
+// own seasons, events and classes. Points is what each moto pays, indexed
+// by finishing position minus one. A series without classes takes any.
+type Series struct {
+	Id            int       `json:"id"`
+	OwnerId       *int      `json:"ownerId"`
+	Slug          string    `json:"slug" binding:"required,min=2,max=40"`
+	Name          string    `json:"name" binding:"required,min=3"`
+	Description   string    `json:"description"`
+	Classes       []string  `json:"classes"`
+	Points        []int     `json:"points" binding:"omitempty,dive,min=0"`
+	MotosPerEvent int       `json:"motosPerEvent" binding:"omitempty,min=1,max=10"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// PointsFor is what a finish in the given position pays in the series.
+// Positions outside the table score nothing.
+func (s *Series) PointsFor(position int) int {
+	if position < 1 || position > len(s.Points) {
+		return 0
+	}
+	return s.Points[position-1]
+}
+
+// HasClass reports whether the series races the class.
+func (s *Series) HasClass(class string) bool {
+	if len(s.Classes) == 0 {
+		return true
+	}
+	for _, c := range s.Classes {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// defaults fills in the Pro Motocross rules for anything left unset.
+func (s *Series) defaults() {
+	if s.Classes == nil {
+		s.Classes = []string{}
+	}
+	if len(s.Points) == 0 {
+		s.Points = append([]int{}, MotoPoints...)
+	}
+	if s.MotosPerEvent == 0 {
+		s.MotosPerEvent = MotosPerEvent
+	}
+}
+
+const seriesColumns = "s.id, s.owner_id, s.slug, s.name, s.description, s.classes, s.points, s.motos_per_event, s.created_at"
+
+func scanSeries(row scanner, series *Series) error {
+	var classes, points string
+	err := row.Scan(&series.Id, &series.OwnerId, &series.Slug, &series.Name, &series.Description, &classes, &points, &series.MotosPerEvent, &series.CreatedAt)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(classes), &series.Classes); err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(points), &series.Points)
+}
+
+func (m *SeriesModel) Insert(series *Series) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	series.defaults()
+	classes, _ := json.Marshal(series.Classes)
+	points, _ := json.Marshal(series.Points)
+
+	query := `
+		INSERT INTO series (owner_id, slug, name, description, classes, points, motos_per_event)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	return m.DB.QueryRowContext(ctx, query, series.OwnerId, series.Slug, series.Name, series.Description, string(classes), string(points), series.MotosPerEvent).
+		Scan(&series.Id, &series.CreatedAt)
+}
+
+func (m *SeriesModel) Update(series *Series) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	series.defaults()
+	classes, _ := json.Marshal(series.Classes)
+	points, _ := json.Marshal(series.Points)
+
+	query := "UPDATE series SET slug = $1, name = $2, description = $3, classes = $4, points = $5, motos_per_event = $6 WHERE id = $7"
+
+	_, err := m.DB.ExecContext(ctx, query, series.Slug, series.Name, series.Description, string(classes), string(points), series.MotosPerEvent, series.Id)
+	return err
+}
+
+func (m *SeriesModel) GetAll() ([]*Series, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := m.DB.QueryContext(ctx, "SELECT "+seriesColumns+" FROM series s ORDER BY s.id")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	all := []*Series{}
+	for rows.Next() {
+		var series Series
+		if err := scanSeries(rows, &series); err != nil {
+			return nil, err
+		}
+		all = append(all, &series)
+	}
+
+	return all, rows.Err()
+}
+
+func (m *SeriesModel) Get(id int) (*Series, error) {
+	return m.getOne("SELECT "+seriesColumns+" FROM series s WHERE s.id = $1", id)
+}
+
+func (m *SeriesModel) GetBySlug(slug string) (*Series, error) {
+	return m.getOne("SELECT "+seriesColumns+" FROM series s WHERE s.slug = $1", slug)
+}
+
+// GetByEvent returns the series an event belongs to.
+func (m *SeriesModel) GetByEvent(eventId int) (*Series, error) {
+	return m.getOne("SELECT "+seriesColumns+" FROM series s JOIN events e ON e.series_id = s.id WHERE e.id = $1", eventId)
+}
+
+func (m *SeriesModel) getOne(query string, args ...any) (*Series, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var series Series
+	err := scanSeries(m.DB.QueryRowContext(ctx, query, args...), &series)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &series, nil
+}
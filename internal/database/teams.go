@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type TeamModel struct {
+	DB DBTX
+}
+
+type Team struct {
+	Id           int    `json:"id"`
+	Name         string `json:"name" binding:"required,min=2"`
+	Manufacturer string `json:"manufacturer"`
+}
+
+func (m *TeamModel) Insert(team *Team) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "INSERT INTO teams (name, manufacturer) VALUES ($1, $2) RETURNING id"
+
+	return m.DB.QueryRowContext(ctx, query, team.Name, team.Manufacturer).Scan(&team.Id)
+}
+
+func (m *TeamModel) GetAll() ([]*Team, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT * FROM teams"
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	teams := []*Team{}
+
+	for rows.Next() {
+		var team Team
+
+		err := rows.Scan(&team.Id, &team.Name, &team.Manufacturer)
+		if err != nil {
+			return nil, err
+		}
+
+		teams = append(teams, &team)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return teams, nil
+}
+
+func (m *TeamModel) getTeam(query string, args ...any) (*Team, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var team Team
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&team.Id, &team.Name, &team.Manufacturer)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &team, nil
+}
+
+func (m *TeamModel) Get(id int) (*Team, error) {
+	query := "SELECT * FROM teams WHERE id = $1"
+	return m.getTeam(query, id)
+}
+
+func (m *TeamModel) GetByName(name string) (*Team, error) {
+	query := "SELECT * FROM teams WHERE name = $1 COLLATE NOCASE"
+	return m.getTeam(query, name)
+}
+
+func (m *TeamModel) Update(team *Team) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "UPDATE teams SET name = $1, manufacturer = $2 WHERE id = $3"
+
+	_, err := m.DB.ExecContext(ctx, query, team.Name, team.Manufacturer, team.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type TrackModel struct {
+	DB DBTX
+}
+
+type Track struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name" binding:"required,min=3"`
+	Location string `json:"location" binding:"required,min=3"`
+	Surface  string `json:"surface"`
+}
+
+func (m *TrackModel) Insert(track *Track) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "INSERT INTO tracks (name, location, surface) VALUES ($1, $2, $3) RETURNING id"
+
+	return m.DB.QueryRowContext(ctx, query, track.Name, track.Location, track.Surface).Scan(&track.Id)
+}
+
+func (m *TrackModel) GetAll() ([]*Track, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "SELECT * FROM tracks"
+
+	rows, err := m.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	tracks := []*Track{}
+
+	for rows.Next() {
+		var track Track
+
+		err := rows.Scan(&track.Id, &track.Name, &track.Location, &track.Surface)
+		if err != nil {
+			return nil, err
+		}
+
+		tracks = append(tracks, &track)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tracks, nil
+}
+
+func (m *TrackModel) getTrack(query string, args ...any) (*Track, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var track Track
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&track.Id, &track.Name, &track.Location, &track.Surface)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &track, nil
+}
+
+func (m *TrackModel) Get(id int) (*Track, error) {
+	query := "SELECT * FROM tracks WHERE id = $1"
+	return m.getTrack(query, id)
+}
+
+func (m *TrackModel) GetByName(name string) (*Track, error) {
+	query := "SELECT * FROM tracks WHERE name = $1 COLLATE NOCASE"
+	return m.getTrack(query, name)
+}
+
+func (m *TrackModel) Update(track *Track) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	query := "UPDATE tracks SET name = $1, location = $2, surface = $3 WHERE id = $4"
+
+	_, err := m.DB.ExecContext(ctx, query, track.Name, track.Location, track.Surface, track.Id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
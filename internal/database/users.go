@@ -7,7 +7,7 @@ import (
 )
 
 type UserModel struct {
-	DB *sql.DB
+	DB DBTX
 }
 
 type User struct {
@@ -15,6 +15,7 @@ type User struct {
 	Email    string `json:"email"`
 	Name     string `json:"name"`
 	Password string `json:"-"`
+	IsAdmin  bool   `json:"isAdmin"`
 }
 
 func (m *UserModel) Insert(user *User) error {
@@ -35,7 +36,7 @@ func (m *UserModel) getUser(query string, args ...any) (*User, error) {
 	defer cancel()
 
 	var user User
-	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.Id, &user.Email, &user.Name, &user.Password)
+	err := m.DB.QueryRowContext(ctx, query, args...).Scan(&user.Id, &user.Email, &user.Name, &user.Password, &user.IsAdmin)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -54,3 +55,21 @@ func (m *UserModel) GetByEmail(email string) (*User, error) {
 	query := `SELECT * FROM users WHERE email = $1`
 	return m.getUser(query, email)
 }
+
+// SetAdmin grants or takes away administrator rights, reporting whether a
+// user with the email exists.
+func (m *UserModel) SetAdmin(email string, admin bool) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	result, err := m.DB.ExecContext(ctx, "UPDATE users SET is_admin = $1 WHERE email = $2", admin, email)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
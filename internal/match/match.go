@@ -0,0 +1,166 @@
+// Package match compares rider names that were typed by different people at
+// different times, so "J. Lawrence", "Jett  Lawrence" and "LAWRENCE, Jett"
+// can be recognised as the same rider.
+package match
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// folds maps the accented letters common in rider names to plain ones.
+var folds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a",
+	'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u",
+	'ý': "y", 'ÿ': "y",
+	'ß': "ss", 'æ': "ae", 'œ': "oe",
+}
+
+// suffixes are generational suffixes, left off so "Jr." on one sheet and not
+// another doesn't count against a match.
+var suffixes = map[string]bool{"jr": true, "sr": true, "ii": true, "iii": true, "iv": true}
+
+// Normalize reduces a name to lowercase words without accents, punctuation
+// or suffixes. "Last, First" is turned around to "first last".
+func Normalize(name string) string {
+	if last, first, ok := strings.Cut(name, ","); ok {
+		name = first + " " + last
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case folds[r] != "":
+			b.WriteString(folds[r])
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case r == '\'' || r == '’' || r == '.':
+			// O'Neal and J.R. read the same without the marks.
+		default:
+			b.WriteRune(' ')
+		}
+	}
+
+	words := []string{}
+	for _, word := range strings.Fields(b.String()) {
+		if !suffixes[word] {
+			words = append(words, word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// Split breaks a name into a first and last name, the last word being the
+// last name.
+func Split(name string) (first, last string) {
+	words := strings.Fields(name)
+	switch len(words) {
+	case 0:
+		return "", ""
+	case 1:
+		return "", words[0]
+	}
+	return strings.Join(words[:len(words)-1], " "), words[len(words)-1]
+}
+
+// Similarity scores how alike two names are, from 0 for nothing in common
+// to 1 for the same name once normalized. Words in a different order and a
+// first name cut to its initial still score high.
+func Similarity(a, b string) float64 {
+	a, b = Normalize(a), Normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	score := jaroWinkler(a, b)
+	score = max(score, jaroWinkler(sortWords(a), sortWords(b)))
+	return max(score, initials(a, b))
+}
+
+// initials scores names whose last names match and whose first names agree
+// on their initial, such as "j lawrence" and "jett lawrence". It stays
+// below an exact match so a full name is always preferred.
+func initials(a, b string) float64 {
+	firstA, lastA := Split(a)
+	firstB, lastB := Split(b)
+	if lastA != lastB || firstA == "" || firstB == "" || firstA[0] != firstB[0] {
+		return 0
+	}
+	if len(firstA) == 1 || len(firstB) == 1 {
+		return 0.9
+	}
+	return 0
+}
+
+func sortWords(name string) string {
+	words := strings.Fields(name)
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+// jaroWinkler is the Jaro similarity of two strings, boosted for a shared
+// prefix of up to four characters.
+func jaroWinkler(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	jaro := jaroSimilarity(ra, rb)
+
+	prefix := 0
+	for prefix < min(4, len(ra), len(rb)) && ra[prefix] == rb[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+func jaroSimilarity(a, b []rune) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+
+	window := max(len(a), len(b))/2 - 1
+	window = max(window, 0)
+
+	matchedA := make([]bool, len(a))
+	matchedB := make([]bool, len(b))
+	matches := 0
+	for i := range a {
+		for j := max(0, i-window); j < min(len(b), i+window+1); j++ {
+			if matchedB[j] || a[i] != b[j] {
+				continue
+			}
+			matchedA[i], matchedB[j] = true, true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	j := 0
+	for i := range a {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	return (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions/2))/m) / 3
+}
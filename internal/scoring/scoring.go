@@ -0,0 +1,284 @@
+// Package scoring works out championship standings from race results under
+// a series' points rules, so series that score differently don't each need
+// their own code.
+package scoring
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// What a finishing position is scored on: every moto, or the rider's
+// overall finish at the round.
+const (
+	BasisMoto    = "moto"
+	BasisOverall = "overall"
+)
+
+// Bonus kinds. Holeshot and lapLed pay per holeshot and per lap led; pole
+// pays the top qualifier of the class at each round.
+const (
+	BonusHoleshot = "holeshot"
+	BonusLapLed   = "lapLed"
+	BonusPole     = "pole"
+)
+
+// Rules describe how a series scores a season. Points is what each finish
+// pays, indexed by position minus one. A round's finish points are scaled by
+// its multiplier and rounded, penalty deductions are taken off, then its
+// bonuses are added. DropWorst rounds, the lowest-scoring first, count for
+// nothing.
+type Rules struct {
+	Basis       string       `json:"basis"`
+	Points      []int        `json:"points"`
+	Multipliers []Multiplier `json:"multipliers"`
+	DropWorst   int          `json:"dropWorst"`
+	Bonuses     []Bonus      `json:"bonuses"`
+}
+
+// Multiplier scales the finish points of one round, such as a playoff
+// final paying double.
+type Multiplier struct {
+	Round  int     `json:"round"`
+	Factor float64 `json:"factor"`
+}
+
+// Bonus pays extra points for something other than where a rider finished.
+type Bonus struct {
+	Kind   string `json:"kind"`
+	Points int    `json:"points"`
+}
+
+// Validate reports the first thing wrong with a set of rules.
+func (r *Rules) Validate() error {
+	if r.Basis != BasisMoto && r.Basis != BasisOverall {
+		return fmt.Errorf("basis must be %q or %q", BasisMoto, BasisOverall)
+	}
+	if len(r.Points) == 0 {
+		return errors.New("points must pay at least one position")
+	}
+	for i, points := range r.Points {
+		if points < 0 {
+			return fmt.Errorf("position %d pays negative points", i+1)
+		}
+	}
+
+	rounds := map[int]bool{}
+	for _, m := range r.Multipliers {
+		if m.Round < 1 {
+			return fmt.Errorf("multiplier round %d must be 1 or more", m.Round)
+		}
+		if m.Factor <= 0 {
+			return fmt.Errorf("round %d multiplier must be more than 0", m.Round)
+		}
+		if rounds[m.Round] {
+			return fmt.Errorf("round %d has more than one multiplier", m.Round)
+		}
+		rounds[m.Round] = true
+	}
+
+	if r.DropWorst < 0 {
+		return errors.New("dropWorst can't be negative")
+	}
+
+	kinds := map[string]bool{}
+	for _, b := range r.Bonuses {
+		switch b.Kind {
+		case BonusHoleshot, BonusLapLed, BonusPole:
+		default:
+			return fmt.Errorf("unknown bonus %q, use %s, %s or %s", b.Kind, BonusHoleshot, BonusLapLed, BonusPole)
+		}
+		if kinds[b.Kind] {
+			return fmt.Errorf("bonus %s is listed more than once", b.Kind)
+		}
+		kinds[b.Kind] = true
+	}
+
+	return nil
+}
+
+// PointsFor is what a finish in the given position pays before any
+// multiplier. Positions outside the table score nothing.
+func (r *Rules) PointsFor(position int) int {
+	if position < 1 || position > len(r.Points) {
+		return 0
+	}
+	return r.Points[position-1]
+}
+
+// BonusFor is what one of a kind of bonus pays, or 0 when the rules pay
+// nothing for it.
+func (r *Rules) BonusFor(kind string) int {
+	for _, b := range r.Bonuses {
+		if b.Kind == kind {
+			return b.Points
+		}
+	}
+	return 0
+}
+
+// Factor is the multiplier of a round, 1 unless the rules say otherwise.
+func (r *Rules) Factor(round int) float64 {
+	for _, m := range r.Multipliers {
+		if m.Round == round {
+			return m.Factor
+		}
+	}
+	return 1
+}
+
+// Finish is where a rider placed in a moto, or overall when the rules score
+// overall finishes. Riders who did not finish score nothing. Deduction is
+// the points penalties took off the finish; it isn't scaled by the round's
+// multiplier and can take a round below zero.
+type Finish struct {
+	Round     int
+	RiderId   int
+	Position  int
+	Finished  bool
+	Deduction int
+}
+
+// Award is a number of bonuses of one kind a rider earned at a round.
+type Award struct {
+	Round   int
+	RiderId int
+	Kind    string
+	Count   int
+}
+
+// RoundScore is what a rider scored at one round. Points are net of
+// Deducted, the points penalties took off.
+type RoundScore struct {
+	Round    int  `json:"round"`
+	Points   int  `json:"points"`
+	Deducted int  `json:"deducted"`
+	Bonus    int  `json:"bonus"`
+	Total    int  `json:"total"`
+	Dropped  bool `json:"dropped"`
+}
+
+// Standing is a rider's place in the championship. Points leaves out
+// dropped rounds; Dropped is what they were worth. Wins counts finishes in
+// first, which break ties on points.
+type Standing struct {
+	Position int          `json:"position"`
+	RiderId  int          `json:"riderId"`
+	Points   int          `json:"points"`
+	Dropped  int          `json:"dropped"`
+	Wins     int          `json:"wins"`
+	Rounds   []RoundScore `json:"rounds"`
+}
+
+// Evaluate scores a season under the rules and ranks the riders. Rounds
+// lists every round of the season in running order, including those a
+// rider missed, which score 0 and are the first dropped. Ties on points go
+// to more wins, then the better score at the latest round where the riders
+// differ.
+func Evaluate(rules Rules, rounds []int, finishes []Finish, awards []Award) []Standing {
+	index := map[int]int{}
+	for i, round := range rounds {
+		index[round] = i
+	}
+
+	byRider := map[int]*Standing{}
+	var riders []int
+	standing := func(riderId int) *Standing {
+		s, ok := byRider[riderId]
+		if !ok {
+			s = &Standing{RiderId: riderId, Rounds: make([]RoundScore, len(rounds))}
+			for i, round := range rounds {
+				s.Rounds[i].Round = round
+			}
+			byRider[riderId] = s
+			riders = append(riders, riderId)
+		}
+		return s
+	}
+
+	// Finish points are added up per round first so the multiplier rounds
+	// once, on the round total.
+	raw := map[int][]int{}
+	for _, finish := range finishes {
+		i, ok := index[finish.Round]
+		if !ok {
+			continue
+		}
+		s := standing(finish.RiderId)
+		if raw[finish.RiderId] == nil {
+			raw[finish.RiderId] = make([]int, len(rounds))
+		}
+		s.Rounds[i].Deducted += finish.Deduction
+		if !finish.Finished {
+			continue
+		}
+		raw[finish.RiderId][i] += rules.PointsFor(finish.Position)
+		if finish.Position == 1 {
+			s.Wins++
+		}
+	}
+
+	for _, award := range awards {
+		i, ok := index[award.Round]
+		if !ok {
+			continue
+		}
+		standing(award.RiderId).Rounds[i].Bonus += award.Count * rules.BonusFor(award.Kind)
+	}
+
+	standings := make([]Standing, 0, len(riders))
+	for _, riderId := range riders {
+		s := byRider[riderId]
+		for i := range s.Rounds {
+			score := &s.Rounds[i]
+			if points := raw[riderId]; points != nil {
+				score.Points = int(math.Round(float64(points[i]) * rules.Factor(score.Round)))
+			}
+			score.Points -= score.Deducted
+			score.Total = score.Points + score.Bonus
+			s.Points += score.Total
+		}
+		s.drop(rules.DropWorst)
+		standings = append(standings, *s)
+	}
+
+	sort.SliceStable(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Points != b.Points {
+			return a.Points > b.Points
+		}
+		if a.Wins != b.Wins {
+			return a.Wins > b.Wins
+		}
+		for k := len(rounds) - 1; k >= 0; k-- {
+			if a.Rounds[k].Total != b.Rounds[k].Total {
+				return a.Rounds[k].Total > b.Rounds[k].Total
+			}
+		}
+		return a.RiderId < b.RiderId
+	})
+
+	for i := range standings {
+		standings[i].Position = i + 1
+	}
+
+	return standings
+}
+
+// drop takes the n lowest-scoring rounds out of the rider's points, the
+// earlier round going first when two score the same.
+func (s *Standing) drop(n int) {
+	order := make([]int, len(s.Rounds))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return s.Rounds[order[i]].Total < s.Rounds[order[j]].Total })
+
+	for _, i := range order[:min(n, len(order))] {
+		s.Rounds[i].Dropped = true
+		s.Dropped += s.Rounds[i].Total
+		s.Points -= s.Rounds[i].Total
+	}
+}
@@ -0,0 +1,219 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+)
+
+// proMotocross is the Pro Motocross moto points table.
+var proMotocross = []int{25, 22, 20, 18, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+
+// motos turns finishing orders of rider Ids into finishes, one order per
+// moto, in the round given.
+func motos(round int, orders ...[]int) []Finish {
+	var finishes []Finish
+	for _, order := range orders {
+		for i, riderId := range order {
+			finishes = append(finishes, Finish{Round: round, RiderId: riderId, Position: i + 1, Finished: true})
+		}
+	}
+	return finishes
+}
+
+func dnf(round, riderId, position int) Finish {
+	return Finish{Round: round, RiderId: riderId, Position: position}
+}
+
+func join(lists ...[]Finish) []Finish {
+	var all []Finish
+	for _, list := range lists {
+		all = append(all, list...)
+	}
+	return all
+}
+
+// line is what a test expects of a standing: the rider, their points and
+// moto wins, in finishing order.
+type line struct {
+	riderId int
+	points  int
+	wins    int
+}
+
+func TestEvaluate(t *testing.T) {
+	// Rider Ids are race numbers. The 2025 450 opening rounds at Fox
+	// Raceway and Hangtown, as in the seed data.
+	opening := join(
+		motos(1, []int{4, 7, 32, 96, 3, 21, 94, 2, 14}, []int{4, 32, 7, 3, 96, 94, 21, 14}),
+		[]Finish{dnf(1, 2, 9)},
+		motos(2, []int{96, 4, 3, 32, 7, 94, 21, 2, 14}, []int{4, 96, 3, 7, 32, 2, 94, 21, 14}),
+	)
+
+	// Jett Lawrence's perfect 2023 450 season, 22 motos from 22 over 11
+	// rounds, against a rider second every time.
+	var perfect []Finish
+	var season2023 []int
+	for round := 1; round <= 11; round++ {
+		season2023 = append(season2023, round)
+		perfect = append(perfect, motos(round, []int{18, 2}, []int{18, 2})...)
+	}
+
+	tests := []struct {
+		name     string
+		rules    Rules
+		rounds   []int
+		finishes []Finish
+		awards   []Award
+		want     []line
+	}{
+		{
+			name:     "pro motocross opening rounds",
+			rules:    Rules{Basis: BasisMoto, Points: proMotocross},
+			rounds:   []int{1, 2},
+			finishes: opening,
+			want: []line{
+				{4, 97, 3}, {96, 81, 1}, {7, 76, 0}, {32, 76, 0}, {3, 74, 0},
+				{94, 58, 0}, {21, 56, 0}, {14, 49, 0}, {2, 41, 0},
+			},
+		},
+		{
+			name:     "perfect season",
+			rules:    Rules{Basis: BasisMoto, Points: proMotocross},
+			rounds:   season2023,
+			finishes: perfect,
+			want:     []line{{18, 550, 22}, {2, 484, 0}},
+		},
+		{
+			// SMX playoffs pay double, and the final triple.
+			name:   "round multipliers",
+			rules:  Rules{Basis: BasisOverall, Points: []int{25, 22, 20}, Multipliers: []Multiplier{{Round: 2, Factor: 2}, {Round: 3, Factor: 3}}},
+			rounds: []int{1, 2, 3},
+			finishes: join(
+				motos(1, []int{1, 2, 3}),
+				motos(2, []int{2, 1, 3}),
+				motos(3, []int{3, 2, 1}),
+			),
+			want: []line{{2, 138, 1}, {3, 135, 1}, {1, 129, 1}},
+		},
+		{
+			name:   "multiplier rounds the round total once",
+			rules:  Rules{Basis: BasisMoto, Points: []int{3, 1}, Multipliers: []Multiplier{{Round: 1, Factor: 1.5}}},
+			rounds: []int{1},
+			finishes: join(
+				motos(1, []int{1, 2}, []int{2, 1}),
+			),
+			want: []line{{1, 6, 1}, {2, 6, 1}},
+		},
+		{
+			name:   "drop worst",
+			rules:  Rules{Basis: BasisOverall, Points: proMotocross, DropWorst: 1},
+			rounds: []int{1, 2, 3},
+			finishes: join(
+				motos(1, []int{1, 2}),
+				motos(2, []int{2}),
+				motos(3, []int{1, 2}),
+			),
+			// Rider 1 missed round 2, which is the round dropped; rider 2
+			// drops one of their 22s.
+			want: []line{{1, 50, 2}, {2, 47, 1}},
+		},
+		{
+			name:   "bonuses",
+			rules:  Rules{Basis: BasisMoto, Points: []int{10, 5}, Bonuses: []Bonus{{Kind: BonusHoleshot, Points: 1}, {Kind: BonusLapLed, Points: 1}, {Kind: BonusPole, Points: 3}}},
+			rounds: []int{1},
+			finishes: join(
+				motos(1, []int{1, 2}, []int{1, 2}),
+			),
+			awards: []Award{
+				{Round: 1, RiderId: 2, Kind: BonusHoleshot, Count: 2},
+				{Round: 1, RiderId: 2, Kind: BonusLapLed, Count: 6},
+				{Round: 1, RiderId: 2, Kind: BonusPole, Count: 1},
+				{Round: 1, RiderId: 1, Kind: BonusLapLed, Count: 24},
+			},
+			want: []line{{1, 44, 2}, {2, 21, 0}},
+		},
+		{
+			name:   "bonuses the rules don't pay",
+			rules:  Rules{Basis: BasisMoto, Points: []int{10, 5}},
+			rounds: []int{1},
+			finishes: join(
+				motos(1, []int{1, 2}),
+			),
+			awards: []Award{{Round: 1, RiderId: 2, Kind: BonusHoleshot, Count: 1}},
+			want:   []line{{1, 10, 1}, {2, 5, 0}},
+		},
+		{
+			name:   "deductions are not multiplied",
+			rules:  Rules{Basis: BasisMoto, Points: []int{10, 5}, Multipliers: []Multiplier{{Round: 1, Factor: 2}}},
+			rounds: []int{1},
+			finishes: []Finish{
+				{Round: 1, RiderId: 1, Position: 1, Finished: true, Deduction: 12},
+				{Round: 1, RiderId: 2, Position: 2, Finished: true},
+			},
+			want: []line{{2, 10, 0}, {1, 8, 1}},
+		},
+		{
+			name:   "deductions can take a round below zero",
+			rules:  Rules{Basis: BasisMoto, Points: []int{10, 5}},
+			rounds: []int{1},
+			finishes: []Finish{
+				{Round: 1, RiderId: 1, Position: 1, Finished: true},
+				{Round: 1, RiderId: 2, Position: 2, Deduction: 3},
+			},
+			want: []line{{1, 10, 1}, {2, -3, 0}},
+		},
+		{
+			name:   "ties go to wins, then the latest round",
+			rules:  Rules{Basis: BasisOverall, Points: []int{10, 8, 6}},
+			rounds: []int{1, 2},
+			finishes: join(
+				motos(1, []int{1, 2, 3}),
+				motos(2, []int{3, 2, 1}),
+			),
+			// Everyone has 16 points. Rider 2 has no win, and rider 3
+			// beat rider 1 at round 2.
+			want: []line{{3, 16, 1}, {1, 16, 1}, {2, 16, 0}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []line
+			for i, s := range Evaluate(tt.rules, tt.rounds, tt.finishes, tt.awards) {
+				if s.Position != i+1 {
+					t.Errorf("standing %d has position %d", i+1, s.Position)
+				}
+				got = append(got, line{s.RiderId, s.Points, s.Wins})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateRounds(t *testing.T) {
+	rules := Rules{Basis: BasisOverall, Points: []int{10, 8}, Multipliers: []Multiplier{{Round: 2, Factor: 2}}, DropWorst: 1, Bonuses: []Bonus{{Kind: BonusPole, Points: 1}}}
+	finishes := []Finish{
+		{Round: 1, RiderId: 1, Position: 2, Finished: true},
+		{Round: 2, RiderId: 1, Position: 1, Finished: true, Deduction: 5},
+	}
+	awards := []Award{{Round: 3, RiderId: 1, Kind: BonusPole, Count: 1}}
+
+	standings := Evaluate(rules, []int{1, 2, 3}, finishes, awards)
+	if len(standings) != 1 {
+		t.Fatalf("got %d standings, want 1", len(standings))
+	}
+
+	want := []RoundScore{
+		{Round: 1, Points: 8, Total: 8},
+		{Round: 2, Points: 15, Deducted: 5, Total: 15},
+		{Round: 3, Bonus: 1, Total: 1, Dropped: true},
+	}
+	if got := standings[0].Rounds; !reflect.DeepEqual(got, want) {
+		t.Errorf("rounds: got %+v, want %+v", got, want)
+	}
+	if got := standings[0]; got.Points != 23 || got.Dropped != 1 {
+		t.Errorf("got %d points with %d dropped, want 23 with 1 dropped", got.Points, got.Dropped)
+	}
+}
@@ -0,0 +1,205 @@
+package timing
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Message is one update published to a topic such as "event:12" or
+// "event:12:moto:2". Ids increase across all topics, so a client resuming
+// from the last id it saw gets exactly what it missed.
+type Message struct {
+	Id    int64     `json:"id"`
+	Topic string    `json:"topic"`
+	Event string    `json:"event"`
+	Data  any       `json:"data"`
+	Time  time.Time `json:"time"`
+}
+
+// Subscription receives messages for its topics until it is closed, either
+// by the subscriber or by the hub when the subscriber falls too far behind.
+type Subscription struct {
+	C <-chan Message
+
+	hub    *Hub
+	c      chan Message
+	topics map[string]bool
+	once   sync.Once
+}
+
+// Hub fans messages out to subscribers and keeps a bounded history so
+// clients can resume after a reconnect.
+type Hub struct {
+	mu          sync.Mutex
+	nextId      int64
+	history     []Message
+	historySize int
+	bufferSize  int
+	subs        map[*Subscription]bool
+}
+
+// NewHub returns a hub that remembers the last historySize messages and
+// buffers up to bufferSize messages per subscriber.
+func NewHub(historySize, bufferSize int) *Hub {
+	return &Hub{
+		historySize: historySize,
+		bufferSize:  bufferSize,
+		subs:        map[*Subscription]bool{},
+	}
+}
+
+// Publish sends data to everyone subscribed to topic. Subscribers whose
+// buffer is full are dropped rather than slowing everyone else down; they
+// can reconnect and resume from their last id.
+func (h *Hub) Publish(topic, event string, data any) Message {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextId++
+	msg := Message{Id: h.nextId, Topic: topic, Event: event, Data: data, Time: time.Now().UTC()}
+
+	h.history = append(h.history, msg)
+	if len(h.history) > h.historySize {
+		h.history = h.history[len(h.history)-h.historySize:]
+	}
+
+	for sub := range h.subs {
+		if !sub.topics[topic] {
+			continue
+		}
+		select {
+		case sub.c <- msg:
+		default:
+			h.drop(sub)
+		}
+	}
+
+	return msg
+}
+
+// Subscribe registers for the given topics. With a lastId the missed
+// messages still in history are queued first; with a lastId of zero the
+// latest message of each topic is queued so the client starts with a
+// snapshot.
+func (h *Hub) Subscribe(topics []string, lastId int64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sub := &Subscription{hub: h, topics: map[string]bool{}}
+	for _, topic := range topics {
+		sub.topics[topic] = true
+	}
+
+	backlog := h.backlog(sub.topics, lastId)
+
+	size := h.bufferSize
+	if len(backlog) > size {
+		size = len(backlog)
+	}
+
+	sub.c = make(chan Message, size)
+	sub.C = sub.c
+	for _, msg := range backlog {
+		sub.c <- msg
+	}
+
+	h.subs[sub] = true
+	return sub
+}
+
+// Add subscribes to more topics and queues the same backlog Subscribe would
+// for them. Topics already subscribed to are left alone so nothing is
+// delivered twice. If the backlog doesn't fit in the subscriber's buffer the
+// subscription is dropped.
+func (s *Subscription) Add(lastId int64, topics ...string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	if !s.hub.subs[s] {
+		return
+	}
+
+	added := map[string]bool{}
+	for _, topic := range topics {
+		if !s.topics[topic] {
+			added[topic] = true
+			s.topics[topic] = true
+		}
+	}
+
+	for _, msg := range s.hub.backlog(added, lastId) {
+		select {
+		case s.c <- msg:
+		default:
+			s.hub.drop(s)
+			return
+		}
+	}
+}
+
+// Remove unsubscribes from topics.
+func (s *Subscription) Remove(topics ...string) {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	for _, topic := range topics {
+		delete(s.topics, topic)
+	}
+}
+
+// Topics returns the topics currently subscribed to.
+func (s *Subscription) Topics() []string {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	topics := make([]string, 0, len(s.topics))
+	for topic := range s.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.drop(s)
+}
+
+// backlog returns the history a new subscriber to topics should start with:
+// everything after lastId, or the latest message of each topic when lastId
+// is zero. It must be called with the hub locked.
+func (h *Hub) backlog(topics map[string]bool, lastId int64) []Message {
+	var backlog []Message
+	if lastId > 0 {
+		for _, msg := range h.history {
+			if msg.Id > lastId && topics[msg.Topic] {
+				backlog = append(backlog, msg)
+			}
+		}
+		return backlog
+	}
+
+	latest := map[string]int{}
+	for i, msg := range h.history {
+		if topics[msg.Topic] {
+			latest[msg.Topic] = i
+		}
+	}
+	for i, msg := range h.history {
+		if idx, ok := latest[msg.Topic]; ok && idx == i {
+			backlog = append(backlog, msg)
+		}
+	}
+	return backlog
+}
+
+// drop must be called with the hub locked.
+func (h *Hub) drop(sub *Subscription) {
+	sub.once.Do(func() {
+		delete(h.subs, sub)
+		close(sub.c)
+	})
+}
@@ -0,0 +1,131 @@
+package timing
+
+import (
+	"reflect"
+	"testing"
+)
+
+// publishReplay publishes the board of the recorded moto after every lap,
+// with an event-wide update alongside each, and returns the ids of the
+// moto's messages.
+func publishReplay(t *testing.T, hub *Hub) []int64 {
+	t.Helper()
+
+	gateDrop, crossings := readRecording(t)
+	tracker := NewTracker()
+
+	var ids []int64
+	for _, clock := range []string{"13:07:21.100", "13:09:33.900", "13:11:46.100", "13:13:58.500"} {
+		board := tracker.Ingest(1, "450", 1, &gateDrop, until(t, crossings, clock))
+		ids = append(ids, hub.Publish("event:1:moto:1", "leaderboard", board).Id)
+		hub.Publish("event:1", "leaderboard", board)
+	}
+	return ids
+}
+
+// drain returns what has been queued for a subscription so far.
+func drain(sub *Subscription) []Message {
+	var messages []Message
+	for {
+		select {
+		case msg, ok := <-sub.C:
+			if !ok {
+				return messages
+			}
+			messages = append(messages, msg)
+		default:
+			return messages
+		}
+	}
+}
+
+func messageIds(messages []Message) []int64 {
+	var ids []int64
+	for _, msg := range messages {
+		ids = append(ids, msg.Id)
+	}
+	return ids
+}
+
+func TestSubscribeResumesFromLastEventId(t *testing.T) {
+	hub := NewHub(100, 10)
+	ids := publishReplay(t, hub)
+
+	// A client that saw lap two reconnects with its id.
+	sub := hub.Subscribe([]string{"event:1:moto:1"}, ids[1])
+	defer sub.Close()
+
+	missed := drain(sub)
+	if got := messageIds(missed); !reflect.DeepEqual(got, ids[2:]) {
+		t.Fatalf("resumed with %v, want %v", got, ids[2:])
+	}
+	if board := missed[len(missed)-1].Data.(Board); board.Standings[0].Laps != 4 {
+		t.Errorf("last message has %d laps, want 4", board.Standings[0].Laps)
+	}
+
+	// Live messages follow on from the backlog.
+	live := hub.Publish("event:1:moto:1", "leaderboard", nil)
+	if got := drain(sub); len(got) != 1 || got[0].Id != live.Id {
+		t.Errorf("got %v after the backlog, want %d", messageIds(got), live.Id)
+	}
+}
+
+func TestSubscribeStartsWithSnapshot(t *testing.T) {
+	hub := NewHub(100, 10)
+	ids := publishReplay(t, hub)
+
+	sub := hub.Subscribe([]string{"event:1:moto:1"}, 0)
+	defer sub.Close()
+
+	if got := messageIds(drain(sub)); !reflect.DeepEqual(got, ids[3:]) {
+		t.Errorf("started with %v, want the latest board %v", got, ids[3:])
+	}
+}
+
+func TestSubscribeResumeOutOfHistory(t *testing.T) {
+	// Only the last three messages, across both topics, are kept.
+	hub := NewHub(3, 10)
+	ids := publishReplay(t, hub)
+
+	sub := hub.Subscribe([]string{"event:1:moto:1"}, ids[0])
+	defer sub.Close()
+
+	if got := messageIds(drain(sub)); !reflect.DeepEqual(got, ids[3:]) {
+		t.Errorf("resumed with %v, want what is left of the history %v", got, ids[3:])
+	}
+}
+
+func TestAddQueuesBacklogOnce(t *testing.T) {
+	hub := NewHub(100, 10)
+	ids := publishReplay(t, hub)
+
+	sub := hub.Subscribe([]string{"event:1:moto:1"}, ids[2])
+	defer sub.Close()
+	drain(sub)
+
+	// Topics already subscribed to aren't queued again.
+	sub.Add(ids[2], "event:1:moto:1", "event:1")
+	got := drain(sub)
+	if want := []int64{ids[2] + 1, ids[3] + 1}; !reflect.DeepEqual(messageIds(got), want) {
+		t.Fatalf("got %v, want the event's messages %v", messageIds(got), want)
+	}
+	for _, msg := range got {
+		if msg.Topic != "event:1" {
+			t.Errorf("message %d is on %s, want event:1", msg.Id, msg.Topic)
+		}
+	}
+}
+
+func TestSlowSubscriberIsDropped(t *testing.T) {
+	hub := NewHub(100, 2)
+
+	sub := hub.Subscribe([]string{"event:1:moto:1"}, 0)
+	publishReplay(t, hub)
+
+	if got := len(drain(sub)); got != 2 {
+		t.Errorf("got %d messages before being dropped, want 2", got)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Error("subscription still open after falling behind")
+	}
+}
@@ -0,0 +1,246 @@
+// Package timing turns transponder crossings into lap times and running
+// order, and fans the resulting updates out to live subscribers.
+package timing
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FinishLoop is the loop id of the timing line that counts laps. Crossings
+// of any other loop only update a rider's last seen loop.
+const FinishLoop = "finish"
+
+// MinLapTime filters out double reads at the finish line. Motocross laps are
+// never this quick, so a second crossing inside it is ignored.
+const MinLapTime = 30 * time.Second
+
+type Crossing struct {
+	RiderNumber int       `json:"riderNumber" binding:"required"`
+	LoopId      string    `json:"loopId" binding:"required"`
+	Timestamp   time.Time `json:"timestamp" binding:"required"`
+}
+
+// Standing is one rider's place in the running order. Times are in
+// milliseconds so dashboards don't have to parse durations.
+type Standing struct {
+	Position     int       `json:"position"`
+	RiderNumber  int       `json:"riderNumber"`
+	Laps         int       `json:"laps"`
+	LastLapMs    int64     `json:"lastLapMs"`
+	BestLapMs    int64     `json:"bestLapMs"`
+	TotalMs      int64     `json:"totalMs"`
+	GapMs        int64     `json:"gapMs"`
+	LapsDown     int       `json:"lapsDown"`
+	LastLoop     string    `json:"lastLoop"`
+	LastCrossing time.Time `json:"lastCrossing"`
+}
+
+// Board is the running order of one moto.
+type Board struct {
+	EventId   int        `json:"eventId"`
+	Class     string     `json:"class"`
+	Moto      int        `json:"moto"`
+	GateDrop  *time.Time `json:"gateDrop,omitempty"`
+	Standings []Standing `json:"standings"`
+}
+
+type rider struct {
+	number     int
+	laps       int
+	lapStart   time.Time
+	lastFinish time.Time
+	lastLap    time.Duration
+	bestLap    time.Duration
+	total      time.Duration
+	lastLoop   string
+	lastSeen   time.Time
+}
+
+type session struct {
+	eventId  int
+	class    string
+	moto     int
+	gateDrop *time.Time
+	riders   map[int]*rider
+}
+
+// Tracker keeps the state of every moto that has received crossings since
+// the process started.
+type Tracker struct {
+	mu       sync.Mutex
+	sessions map[string]*session
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{sessions: map[string]*session{}}
+}
+
+func sessionKey(eventId int, class string, moto int) string {
+	return fmt.Sprintf("%d/%s/%d", eventId, class, moto)
+}
+
+// Ingest applies a batch of crossings to a moto and returns its running
+// order afterwards. Crossings are applied in timestamp order, and ones older
+// than what has already been seen for a rider are ignored so retried
+// batches are harmless. A gate drop time, when known, makes the first
+// finish crossing complete lap one; otherwise it only starts the clock.
+func (t *Tracker) Ingest(eventId int, class string, moto int, gateDrop *time.Time, crossings []Crossing) Board {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := sessionKey(eventId, class, moto)
+	s, ok := t.sessions[key]
+	if !ok {
+		s = &session{eventId: eventId, class: class, moto: moto, riders: map[int]*rider{}}
+		t.sessions[key] = s
+	}
+	if gateDrop != nil {
+		s.gateDrop = gateDrop
+	}
+
+	sorted := append([]Crossing(nil), crossings...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+
+	for _, crossing := range sorted {
+		s.apply(crossing)
+	}
+
+	return s.board()
+}
+
+// Board returns the current running order of a moto, or false when it has
+// received no crossings.
+func (t *Tracker) Board(eventId int, class string, moto int) (Board, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.sessions[sessionKey(eventId, class, moto)]
+	if !ok {
+		return Board{}, false
+	}
+	return s.board(), true
+}
+
+// Boards returns the running order of every moto of an event.
+func (t *Tracker) Boards(eventId int) []Board {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var boards []Board
+	for _, s := range t.sessions {
+		if s.eventId == eventId {
+			boards = append(boards, s.board())
+		}
+	}
+
+	sort.Slice(boards, func(i, j int) bool {
+		if boards[i].Class != boards[j].Class {
+			return boards[i].Class < boards[j].Class
+		}
+		return boards[i].Moto < boards[j].Moto
+	})
+
+	return boards
+}
+
+// Reset forgets a moto, e.g. after a restart.
+func (t *Tracker) Reset(eventId int, class string, moto int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.sessions, sessionKey(eventId, class, moto))
+}
+
+func (s *session) apply(crossing Crossing) {
+	r, ok := s.riders[crossing.RiderNumber]
+	if !ok {
+		r = &rider{number: crossing.RiderNumber}
+		if s.gateDrop != nil {
+			r.lapStart = *s.gateDrop
+		}
+		s.riders[crossing.RiderNumber] = r
+	}
+
+	if crossing.Timestamp.Before(r.lastSeen) {
+		return
+	}
+
+	r.lastSeen = crossing.Timestamp
+	r.lastLoop = crossing.LoopId
+
+	if crossing.LoopId != FinishLoop {
+		return
+	}
+
+	if !r.lastFinish.IsZero() && crossing.Timestamp.Sub(r.lastFinish) < MinLapTime {
+		return
+	}
+
+	if !r.lapStart.IsZero() {
+		lap := crossing.Timestamp.Sub(r.lapStart)
+		r.laps++
+		r.lastLap = lap
+		r.total += lap
+		if r.bestLap == 0 || lap < r.bestLap {
+			r.bestLap = lap
+		}
+	}
+
+	r.lapStart = crossing.Timestamp
+	r.lastFinish = crossing.Timestamp
+}
+
+// board ranks riders by laps completed, then by who completed their last
+// lap first.
+func (s *session) board() Board {
+	riders := make([]*rider, 0, len(s.riders))
+	for _, r := range s.riders {
+		riders = append(riders, r)
+	}
+
+	sort.Slice(riders, func(i, j int) bool {
+		a, b := riders[i], riders[j]
+		if a.laps != b.laps {
+			return a.laps > b.laps
+		}
+		if !a.lastFinish.Equal(b.lastFinish) {
+			if a.lastFinish.IsZero() || b.lastFinish.IsZero() {
+				return !a.lastFinish.IsZero()
+			}
+			return a.lastFinish.Before(b.lastFinish)
+		}
+		return a.number < b.number
+	})
+
+	board := Board{EventId: s.eventId, Class: s.class, Moto: s.moto, GateDrop: s.gateDrop, Standings: []Standing{}}
+
+	for i, r := range riders {
+		standing := Standing{
+			Position:     i + 1,
+			RiderNumber:  r.number,
+			Laps:         r.laps,
+			LastLapMs:    r.lastLap.Milliseconds(),
+			BestLapMs:    r.bestLap.Milliseconds(),
+			TotalMs:      r.total.Milliseconds(),
+			LastLoop:     r.lastLoop,
+			LastCrossing: r.lastSeen,
+		}
+
+		if i > 0 {
+			leader := riders[0]
+			standing.LapsDown = leader.laps - r.laps
+			if standing.LapsDown == 0 && !r.lastFinish.IsZero() {
+				standing.GapMs = r.lastFinish.Sub(leader.lastFinish).Milliseconds()
+			}
+		}
+
+		board.Standings = append(board.Standings, standing)
+	}
+
+	return board
+}
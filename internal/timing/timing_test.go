@@ -0,0 +1,197 @@
+package timing
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// recording is the sample moto the replay command ships with: the gate drop
+// and four laps of the top five 450s.
+var recording = filepath.Join("..", "..", "cmd", "replay", "testdata", "fox-raceway-450-moto1.csv")
+
+// readRecording returns the recording's gate drop and crossings in file
+// order.
+func readRecording(t *testing.T) (time.Time, []Crossing) {
+	t.Helper()
+
+	f, err := os.Open(recording)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.Comment = '#'
+	if _, err := reader.Read(); err != nil {
+		t.Fatal(err)
+	}
+
+	var gateDrop time.Time
+	var crossings []Crossing
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ts, err := time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if row[2] == "gate" {
+			gateDrop = ts
+			continue
+		}
+
+		number, err := strconv.Atoi(row[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		crossings = append(crossings, Crossing{RiderNumber: number, LoopId: row[2], Timestamp: ts})
+	}
+
+	return gateDrop, crossings
+}
+
+// until returns the crossings up to and including the given time of day.
+func until(t *testing.T, crossings []Crossing, clock string) []Crossing {
+	t.Helper()
+
+	end, err := time.Parse(time.RFC3339Nano, "2025-05-24T"+clock+"Z")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var before []Crossing
+	for _, crossing := range crossings {
+		if !crossing.Timestamp.After(end) {
+			before = append(before, crossing)
+		}
+	}
+	return before
+}
+
+func order(board Board) []int {
+	var numbers []int
+	for _, standing := range board.Standings {
+		numbers = append(numbers, standing.RiderNumber)
+	}
+	return numbers
+}
+
+func TestReplayStandings(t *testing.T) {
+	gateDrop, crossings := readRecording(t)
+
+	tracker := NewTracker()
+
+	// Sent in batches the way the replay command does, out of order
+	// within each batch.
+	var board Board
+	for start := 0; start < len(crossings); start += 7 {
+		batch := append([]Crossing(nil), crossings[start:min(start+7, len(crossings))]...)
+		for i, j := 0, len(batch)-1; i < j; i, j = i+1, j-1 {
+			batch[i], batch[j] = batch[j], batch[i]
+		}
+		board = tracker.Ingest(1, "450", 1, &gateDrop, batch)
+	}
+
+	want := []Standing{
+		{Position: 1, RiderNumber: 4, Laps: 4, LastLapMs: 131900, BestLapMs: 131500, TotalMs: 533700},
+		{Position: 2, RiderNumber: 7, Laps: 4, LastLapMs: 132200, BestLapMs: 132200, TotalMs: 536600, GapMs: 2900},
+		{Position: 3, RiderNumber: 3, Laps: 4, LastLapMs: 131700, BestLapMs: 131700, TotalMs: 537800, GapMs: 4100},
+		{Position: 4, RiderNumber: 96, Laps: 4, LastLapMs: 132600, BestLapMs: 131900, TotalMs: 538300, GapMs: 4600},
+		{Position: 5, RiderNumber: 32, Laps: 4, LastLapMs: 134100, BestLapMs: 132000, TotalMs: 538500, GapMs: 4800},
+	}
+
+	if len(board.Standings) != len(want) {
+		t.Fatalf("got %d standings, want %d", len(board.Standings), len(want))
+	}
+	for i, got := range board.Standings {
+		if got.LastLoop != FinishLoop {
+			t.Errorf("#%d last seen at %q, want %q", got.RiderNumber, got.LastLoop, FinishLoop)
+		}
+		got.LastLoop, got.LastCrossing = "", time.Time{}
+		if got != want[i] {
+			t.Errorf("P%d: got %+v, want %+v", i+1, got, want[i])
+		}
+	}
+
+	// A retried batch changes nothing.
+	again := tracker.Ingest(1, "450", 1, nil, crossings)
+	if !reflect.DeepEqual(again, board) {
+		t.Errorf("replaying the moto again changed the board:\n got %+v\nwant %+v", again.Standings, board.Standings)
+	}
+}
+
+func TestReplayRunningOrder(t *testing.T) {
+	gateDrop, crossings := readRecording(t)
+
+	tests := []struct {
+		name  string
+		clock string
+		laps  int
+		order []int
+		loop  string
+	}{
+		{"first split", "13:06:03.495", 0, []int{3, 4, 7, 32, 96}, "split1"},
+		{"lap one", "13:07:21.100", 1, []int{4, 7, 32, 96, 3}, FinishLoop},
+		// #7 and #32 cross the line together; the lower number goes first.
+		{"lap three", "13:11:46.100", 3, []int{4, 7, 32, 96, 3}, FinishLoop},
+		// Splits don't change the order until the next lap is done.
+		{"last split", "13:12:45.370", 3, []int{4, 7, 32, 96, 3}, "split1"},
+		{"flag", "13:13:58.500", 4, []int{4, 7, 3, 96, 32}, FinishLoop},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := NewTracker().Ingest(1, "450", 1, &gateDrop, until(t, crossings, tt.clock))
+
+			if got := order(board); !reflect.DeepEqual(got, tt.order) {
+				t.Errorf("order: got %v, want %v", got, tt.order)
+			}
+			for _, standing := range board.Standings {
+				if standing.Laps != tt.laps || standing.LastLoop != tt.loop {
+					t.Errorf("#%d: got %d laps last seen at %q, want %d at %q", standing.RiderNumber, standing.Laps, standing.LastLoop, tt.laps, tt.loop)
+				}
+			}
+		})
+	}
+}
+
+func TestReplayWithoutGateDrop(t *testing.T) {
+	_, crossings := readRecording(t)
+
+	// The first time across the line only starts the clock.
+	board := NewTracker().Ingest(1, "450", 1, nil, crossings)
+
+	leader := board.Standings[0]
+	if leader.RiderNumber != 4 || leader.Laps != 3 || leader.TotalMs != 395500 {
+		t.Errorf("got #%d with %d laps in %dms, want #4 with 3 laps in 395500ms", leader.RiderNumber, leader.Laps, leader.TotalMs)
+	}
+}
+
+func TestIngestIgnoresDoubleReads(t *testing.T) {
+	gateDrop, crossings := readRecording(t)
+	lapOne := until(t, crossings, "13:07:21.100")
+
+	tracker := NewTracker()
+	tracker.Ingest(1, "450", 1, &gateDrop, lapOne)
+
+	// #4 read again two seconds after crossing the line.
+	double := Crossing{RiderNumber: 4, LoopId: FinishLoop, Timestamp: lapOne[5].Timestamp.Add(2 * time.Second)}
+	board := tracker.Ingest(1, "450", 1, nil, []Crossing{double})
+
+	if got := board.Standings[0]; got.RiderNumber != 4 || got.Laps != 1 || got.LastLapMs != 138200 {
+		t.Errorf("got #%d on %d laps, last lap %dms; want #4 on 1 lap, last lap 138200ms", got.RiderNumber, got.Laps, got.LastLapMs)
+	}
+}